@@ -0,0 +1,78 @@
+package v1alpha1
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types reported in the status of package resources.
+const (
+	// ReadyCondition reports whether the resource reached its desired state.
+	ReadyCondition = "Ready"
+
+	// DependenciesReadyCondition reports whether the dependencies of a
+	// package are installed.
+	DependenciesReadyCondition = "DependenciesReady"
+
+	// ConfigValidCondition reports whether the configuration of a package
+	// could be parsed.
+	ConfigValidCondition = "ConfigValid"
+
+	// ImagesVerifiedCondition reports whether the images a package would run
+	// were verified against those listed in its bundle.
+	ImagesVerifiedCondition = "ImagesVerified"
+
+	// UpgradeAvailableCondition reports whether a newer bundle is available.
+	UpgradeAvailableCondition = "UpgradeAvailable"
+
+	// RegistryReachableCondition reports whether the bundle registry could be
+	// reached.
+	RegistryReachableCondition = "RegistryReachable"
+)
+
+// ConditionReason converts a state such as "installing dependencies" into a
+// condition reason such as "InstallingDependencies".
+func ConditionReason(state string) string {
+	words := strings.Fields(state)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	reason := strings.Join(words, "")
+	if reason == "" {
+		return "Unknown"
+	}
+	return reason
+}
+
+func newCondition(conditionType string, status metav1.ConditionStatus, reason, message string, generation int64) metav1.Condition {
+	return metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	}
+}
+
+// SetCondition sets a condition on the package status, returning true if
+// it changed.
+func (config *Package) SetCondition(conditionType string, status metav1.ConditionStatus, reason, message string) bool {
+	return meta.SetStatusCondition(&config.Status.Conditions,
+		newCondition(conditionType, status, reason, message, config.Generation))
+}
+
+// SetCondition sets a condition on the package bundle status, returning true
+// if it changed.
+func (config *PackageBundle) SetCondition(conditionType string, status metav1.ConditionStatus, reason, message string) bool {
+	return meta.SetStatusCondition(&config.Status.Conditions,
+		newCondition(conditionType, status, reason, message, config.Generation))
+}
+
+// SetCondition sets a condition on the package bundle controller status,
+// returning true if it changed.
+func (config *PackageBundleController) SetCondition(conditionType string, status metav1.ConditionStatus, reason, message string) bool {
+	return meta.SetStatusCondition(&config.Status.Conditions,
+		newCondition(conditionType, status, reason, message, config.Generation))
+}
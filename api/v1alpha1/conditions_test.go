@@ -0,0 +1,35 @@
+package v1alpha1_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+func TestConditionReason(t *testing.T) {
+	assert.Equal(t, "Installed", api.ConditionReason(string(api.StateInstalled)))
+	assert.Equal(t, "InstallingDependencies", api.ConditionReason(string(api.StateInstallingDependencies)))
+	assert.Equal(t, "UpgradeAvailable", api.ConditionReason(string(api.BundleControllerStateUpgradeAvailable)))
+	assert.Equal(t, "Unknown", api.ConditionReason(""))
+}
+
+func TestPackage_SetCondition(t *testing.T) {
+	sut := api.NewPackage("hello-eks-anywhere", "my-hello", "eksa-packages-maggie", "")
+	sut.Generation = 3
+
+	assert.True(t, sut.SetCondition(api.ReadyCondition, metav1.ConditionFalse, "Installing", ""))
+	assert.False(t, sut.SetCondition(api.ReadyCondition, metav1.ConditionFalse, "Installing", ""))
+	assert.True(t, sut.SetCondition(api.ReadyCondition, metav1.ConditionTrue, "Installed", ""))
+
+	condition := meta.FindStatusCondition(sut.Status.Conditions, api.ReadyCondition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, int64(3), condition.ObservedGeneration)
+
+	sut.Generation = 4
+	assert.True(t, sut.SetCondition(api.ReadyCondition, metav1.ConditionTrue, "Installed", ""))
+	assert.Len(t, sut.Status.Conditions, 1)
+}
@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	"os"
 	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
@@ -13,6 +14,39 @@ const (
 	PackageNamespace  = "eksa-packages"
 	namespacePrefix   = PackageNamespace + "-"
 	clusterNameEnvVar = "CLUSTER_NAME"
+
+	// RetryAnnotation requests an immediate retry of a failed or backing off
+	// installation. The controller removes it once the retry has started.
+	RetryAnnotation = "anywhere.eks.aws.com/retry"
+
+	// DependencyAnnotation marks a package that the controller created to
+	// satisfy the dependencies of other packages. Such packages are owned by
+	// their dependents and garbage collected along with the last of them.
+	DependencyAnnotation = "anywhere.eks.aws.com/dependency"
+
+	// PinnedAnnotation keeps a dependency package installed after the last
+	// package depending on it is removed.
+	PinnedAnnotation = "anywhere.eks.aws.com/pinned"
+
+	// CascadeDeleteAnnotation allows a package to be deleted while other
+	// packages depend on it, uninstalling those packages first.
+	CascadeDeleteAnnotation = "anywhere.eks.aws.com/cascade-delete"
+
+	// ApproveAnnotation approves the plan of a package requiring approval
+	// when set to the plan's hash.
+	ApproveAnnotation = "anywhere.eks.aws.com/approve"
+
+	// PackageFinalizer holds a deleted package until its release has been
+	// uninstalled.
+	PackageFinalizer = "packages.eks.amazonaws.com/finalizer"
+
+	// DefaultValuesKey is the key of a Secret or ConfigMap holding package
+	// configuration when a reference doesn't say.
+	DefaultValuesKey = "values.yaml"
+
+	// DefaultReadyTimeout is how long a package's workloads may take to
+	// become ready when the package doesn't say.
+	DefaultReadyTimeout = 5 * time.Minute
 )
 
 func (config *Package) MetaKind() string {
@@ -46,6 +80,17 @@ func (config *Package) GetValues() (values map[string]interface{}, err error) {
 	return mapInterfaces, err
 }
 
+// ReferencesValues returns true if the package's configuration comes in part
+// from the named Secret or ConfigMap.
+func (config *Package) ReferencesValues(kind, name string) bool {
+	for _, ref := range config.Spec.ValuesFrom {
+		if ref.Kind == kind && ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (config *Package) GetClusterName() string {
 	if strings.HasPrefix(config.Namespace, namespacePrefix) {
 		clusterName := strings.TrimPrefix(config.Namespace, namespacePrefix)
@@ -75,3 +120,134 @@ func (config *Package) IsInstalledOnWorkload() bool {
 
 	return managementClusterName != clusterName
 }
+
+// GetReadyTimeout returns how long the package's workloads may take to become
+// ready after installation.
+func (config *Package) GetReadyTimeout() time.Duration {
+	if config.Spec.ReadyTimeout == nil {
+		return DefaultReadyTimeout
+	}
+	return config.Spec.ReadyTimeout.Duration
+}
+
+// GetInstallOptions returns the package's install options, taking those it
+// doesn't set from the defaults.
+func (config *Package) GetInstallOptions(defaults *InstallOptions) InstallOptions {
+	options := InstallOptions{}
+	if defaults != nil {
+		defaults.DeepCopyInto(&options)
+	}
+	own := config.Spec.InstallOptions.DeepCopy()
+	if own == nil {
+		return options
+	}
+	if own.Wait != nil {
+		options.Wait = own.Wait
+	}
+	if own.Atomic != nil {
+		options.Atomic = own.Atomic
+	}
+	if own.Timeout != nil {
+		options.Timeout = own.Timeout
+	}
+	if own.SkipCRDs != nil {
+		options.SkipCRDs = own.SkipCRDs
+	}
+	if own.DisableHooks != nil {
+		options.DisableHooks = own.DisableHooks
+	}
+	if own.MaxHistory != nil {
+		options.MaxHistory = own.MaxHistory
+	}
+	return options
+}
+
+// IsRetryRequested returns true if the retry annotation is set on the package.
+func (config *Package) IsRetryRequested() bool {
+	_, ok := config.Annotations[RetryAnnotation]
+	return ok
+}
+
+// IsApproved returns true if the approve annotation on the package is set to
+// the hash of a plan.
+func (config *Package) IsApproved(hash string) bool {
+	return hash != "" && config.Annotations[ApproveAnnotation] == hash
+}
+
+// IsDependency returns true if the controller created the package to satisfy
+// the dependencies of other packages, rather than a user creating it.
+func (config *Package) IsDependency() bool {
+	return config.Annotations[DependencyAnnotation] == "true"
+}
+
+// IsPinned returns true if the package should be kept when no other packages
+// depend on it.
+func (config *Package) IsPinned() bool {
+	return config.Annotations[PinnedAnnotation] == "true"
+}
+
+// IsCascadeDelete returns true if deleting the package should also delete the
+// packages depending on it.
+func (config *Package) IsCascadeDelete() bool {
+	return config.Annotations[CascadeDeleteAnnotation] == "true"
+}
+
+// IsInstalled returns true if a version of the package is installed, whatever
+// the package is doing since, unless it is being uninstalled.
+func (config *Package) IsInstalled() bool {
+	if config.Status.State == StateUninstalling {
+		return false
+	}
+	return config.Status.State == StateInstalled || config.Status.CurrentVersion != ""
+}
+
+// GetDependents returns the names of the packages owning this one as a
+// dependency.
+func (config *Package) GetDependents() []string {
+	var dependents []string
+	for _, ref := range config.OwnerReferences {
+		if ref.Kind == PackageKind && ref.APIVersion == GroupVersion.String() {
+			dependents = append(dependents, ref.Name)
+		}
+	}
+	return dependents
+}
+
+// AddDependent records that the dependent package requires this one, by
+// making it an owner. Returns true if it wasn't already recorded.
+func (config *Package) AddDependent(dependent *Package) bool {
+	for _, ref := range config.OwnerReferences {
+		if ref.UID == dependent.UID {
+			return false
+		}
+	}
+	config.OwnerReferences = append(config.OwnerReferences, metav1.OwnerReference{
+		APIVersion: GroupVersion.String(),
+		Kind:       PackageKind,
+		Name:       dependent.Name,
+		UID:        dependent.UID,
+	})
+	return true
+}
+
+// RemoveDependents removes the packages recorded as owners of this one, so it
+// is no longer garbage collected with them. Returns true if any were removed.
+func (config *Package) RemoveDependents() bool {
+	refs := config.OwnerReferences[:0]
+	for _, ref := range config.OwnerReferences {
+		if ref.Kind != PackageKind || ref.APIVersion != GroupVersion.String() {
+			refs = append(refs, ref)
+		}
+	}
+	removed := len(refs) != len(config.OwnerReferences)
+	config.OwnerReferences = refs
+	return removed
+}
+
+// GetKey returns the key of the object's data holding the configuration.
+func (ref ValuesReference) GetKey() string {
+	if ref.Key == "" {
+		return DefaultValuesKey
+	}
+	return ref.Key
+}
@@ -2,8 +2,10 @@ package v1alpha1_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
 )
@@ -84,3 +86,91 @@ func TestPackage_IsInstalledOnWorkload(t *testing.T) {
 	sut.Namespace = "eksa-packages-pharrell"
 	assert.True(t, sut.IsInstalledOnWorkload())
 }
+
+func TestPackage_IsRetryRequested(t *testing.T) {
+	sut := api.NewPackage("hello-eks-anywhere", "my-hello", "eksa-packages-maggie", "")
+	assert.False(t, sut.IsRetryRequested())
+	sut.Annotations = map[string]string{api.RetryAnnotation: ""}
+	assert.True(t, sut.IsRetryRequested())
+}
+
+func TestPackage_IsApproved(t *testing.T) {
+	sut := api.NewPackage("hello-eks-anywhere", "my-hello", "eksa-packages-maggie", "")
+	assert.False(t, sut.IsApproved("abc123"))
+	sut.Annotations = map[string]string{api.ApproveAnnotation: "abc123"}
+	assert.True(t, sut.IsApproved("abc123"))
+	assert.False(t, sut.IsApproved("def456"))
+	sut.Annotations = map[string]string{api.ApproveAnnotation: ""}
+	assert.False(t, sut.IsApproved(""))
+}
+
+func TestPackage_IsDependency(t *testing.T) {
+	sut := api.NewPackage("cert-manager", "cert-manager", "eksa-packages-maggie", "")
+	assert.False(t, sut.IsDependency())
+	assert.False(t, sut.IsPinned())
+	sut.Annotations = map[string]string{api.DependencyAnnotation: "true", api.PinnedAnnotation: "true"}
+	assert.True(t, sut.IsDependency())
+	assert.True(t, sut.IsPinned())
+}
+
+func TestPackage_IsInstalled(t *testing.T) {
+	sut := api.NewPackage("cert-manager", "cert-manager", "eksa-packages-maggie", "")
+	assert.False(t, sut.IsInstalled())
+	sut.Status.State = api.StateInstalled
+	assert.True(t, sut.IsInstalled())
+	sut.Status.State = api.StateSuspended
+	assert.False(t, sut.IsInstalled())
+	sut.Status.CurrentVersion = "1.0.0"
+	assert.True(t, sut.IsInstalled())
+	sut.Status.State = api.StateUninstalling
+	assert.False(t, sut.IsInstalled())
+}
+
+func TestPackage_Dependents(t *testing.T) {
+	sut := api.NewPackage("cert-manager", "cert-manager", "eksa-packages-maggie", "")
+	sut.OwnerReferences = []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "other", UID: "cm"}}
+	harbor := api.NewPackage("harbor", "my-harbor", "eksa-packages-maggie", "")
+	harbor.UID = "harbor"
+	emissary := api.NewPackage("emissary", "my-emissary", "eksa-packages-maggie", "")
+	emissary.UID = "emissary"
+
+	assert.True(t, sut.AddDependent(&harbor))
+	assert.True(t, sut.AddDependent(&emissary))
+	assert.False(t, sut.AddDependent(&harbor))
+	assert.Equal(t, []string{"my-harbor", "my-emissary"}, sut.GetDependents())
+
+	assert.True(t, sut.RemoveDependents())
+	assert.False(t, sut.RemoveDependents())
+	assert.Empty(t, sut.GetDependents())
+	assert.Equal(t, []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "other", UID: "cm"}}, sut.OwnerReferences)
+}
+
+func TestPackage_GetReadyTimeout(t *testing.T) {
+	sut := api.NewPackage("hello-eks-anywhere", "my-hello", "eksa-packages-maggie", "")
+	assert.Equal(t, api.DefaultReadyTimeout, sut.GetReadyTimeout())
+	sut.Spec.ReadyTimeout = &metav1.Duration{Duration: time.Minute}
+	assert.Equal(t, time.Minute, sut.GetReadyTimeout())
+}
+
+func TestPackage_GetInstallOptions(t *testing.T) {
+	sut := api.NewPackage("hello-eks-anywhere", "my-hello", "eksa-packages-maggie", "")
+	yes, no, history := true, false, 10
+	assert.Equal(t, api.InstallOptions{}, sut.GetInstallOptions(nil))
+
+	defaults := &api.InstallOptions{Wait: &yes, Atomic: &yes, MaxHistory: &history}
+	assert.Equal(t, *defaults, sut.GetInstallOptions(defaults))
+
+	sut.Spec.InstallOptions = &api.InstallOptions{Atomic: &no, SkipCRDs: &yes}
+	assert.Equal(t, api.InstallOptions{Wait: &yes, Atomic: &no, SkipCRDs: &yes, MaxHistory: &history}, sut.GetInstallOptions(defaults))
+	assert.Equal(t, api.InstallOptions{Atomic: &no, SkipCRDs: &yes}, sut.GetInstallOptions(nil))
+}
+
+func TestPackage_ReferencesValues(t *testing.T) {
+	sut := api.NewPackage("hello-eks-anywhere", "my-hello", "eksa-packages-maggie", "")
+	sut.Spec.ValuesFrom = []api.ValuesReference{{Kind: "Secret", Name: "credentials", Key: "creds"}, {Kind: "ConfigMap", Name: "defaults"}}
+	assert.True(t, sut.ReferencesValues("Secret", "credentials"))
+	assert.True(t, sut.ReferencesValues("ConfigMap", "defaults"))
+	assert.False(t, sut.ReferencesValues("ConfigMap", "credentials"))
+	assert.Equal(t, "creds", sut.Spec.ValuesFrom[0].GetKey())
+	assert.Equal(t, api.DefaultValuesKey, sut.Spec.ValuesFrom[1].GetKey())
+}
@@ -20,12 +20,14 @@ import (
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
-// +kubebuilder:webhook:path=/validate-packages-eks-amazonaws-com-v1alpha1-package,mutating=false,failurePolicy=fail,sideEffects=None,groups=packages.eks.amazonaws.com,resources=packages,verbs=create;update,versions=v1alpha1,name=vpackage.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-packages-eks-amazonaws-com-v1alpha1-package,mutating=false,failurePolicy=fail,sideEffects=None,groups=packages.eks.amazonaws.com,resources=packages,verbs=create;update;delete,versions=v1alpha1,name=vpackage.kb.io,admissionReviewVersions=v1
 // +kubebuilder:printcolumn:name="Package",type=string,JSONPath=`.spec.packageName`
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="CurrentVersion",type=string,JSONPath=`.status.currentVersion`
 // +kubebuilder:printcolumn:name="TargetVersion",type=string,JSONPath=`.status.targetVersion`
+// +kubebuilder:printcolumn:name="UpgradesAvailable",type=string,JSONPath=`.status.upgradesAvailable[*].version`
 // +kubebuilder:printcolumn:name="Detail",type=string,JSONPath=`.status.detail`
 // Package is the Schema for the package API.
 type Package struct {
@@ -48,23 +50,209 @@ type PackageSpec struct {
 	// Config for the package.
 	Config string `json:"config,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	// ValuesFrom references Secrets and ConfigMaps in the package's namespace
+	// holding configuration for the package. They are merged in order, with
+	// later references and then Config taking precedence.
+	ValuesFrom []ValuesReference `json:"valuesFrom,omitempty"`
+
 	// TargetNamespace defines where package resources will be deployed.
 	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// DependencyConfig is the config for dependency packages created for this
+	// package, keyed by package name.
+	DependencyConfig map[string]string `json:"dependencyConfig,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ReadyTimeout is how long the package's workloads may take to become
+	// ready after installation before it is marked degraded. Defaults to 5m.
+	ReadyTimeout *metav1.Duration `json:"readyTimeout,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// HealDrift re-applies the package's release when its resources are
+	// found to have drifted from it.
+	HealDrift bool `json:"healDrift,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// UpgradePolicy selects how far the package is upgraded automatically
+	// when a newer version is in the active bundle: manual never upgrades,
+	// patch and minor stay within the installed or requested minor and major
+	// version, and latest always installs the highest version. When unset,
+	// PackageVersion is installed, or the first version in the bundle if it
+	// is empty.
+	UpgradePolicy UpgradePolicyEnum `json:"upgradePolicy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// MaintenanceWindows restrict upgrades of the package to the times they
+	// are open, in place of those of the package bundle controller.
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Suspend stops the package from being installed, upgraded or
+	// reconfigured until it is unset. Its status is still reported, and it
+	// is still uninstalled when deleted.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Approval selects whether changes to the package are applied
+	// automatically, or planned and held until the plan is approved by
+	// setting the approve annotation to its hash.
+	Approval ApprovalEnum `json:"approval,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// RollbackTo rolls the package's release back to a revision from its
+	// history, and holds it there until it is unset.
+	RollbackTo int64 `json:"rollbackTo,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// InstallOptions configure how the package's release is installed and
+	// upgraded, in place of those of the package bundle controller.
+	InstallOptions *InstallOptions `json:"installOptions,omitempty"`
 }
 
-// +kubebuilder:validation:Enum=initializing;installing;installing dependencies;installed;updating;uninstalling;unknown
+// ValuesReference identifies configuration held in a Secret or ConfigMap.
+type ValuesReference struct {
+	// +kubebuilder:validation:Enum=Secret;ConfigMap
+	// Kind of the object, either Secret or ConfigMap.
+	Kind string `json:"kind"`
+
+	// +kubebuilder:validation:Required
+	// Name of the object.
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Optional
+	// Key of the object's data holding the configuration as YAML. Defaults
+	// to values.yaml.
+	Key string `json:"key,omitempty"`
+}
+
+// InstallOptions configure how Helm installs and upgrades a package's
+// release. Options which are unset are taken from the package bundle
+// controller, and then from Helm's defaults.
+type InstallOptions struct {
+	// +kubebuilder:validation:Optional
+	// Wait for the release's resources to be ready before the install or
+	// upgrade is considered successful.
+	Wait *bool `json:"wait,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Atomic removes the resources of a failed install, and rolls back a
+	// failed upgrade. It implies Wait.
+	Atomic *bool `json:"atomic,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Timeout of each Kubernetes operation, such as running a hook or
+	// waiting for resources. Defaults to 5m when waiting, otherwise hooks
+	// aren't timed out.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// SkipCRDs skips installing the chart's CRDs.
+	SkipCRDs *bool `json:"skipCRDs,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// DisableHooks prevents the chart's hooks from running.
+	DisableHooks *bool `json:"disableHooks,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// MaxHistory limits the revisions of the release which are kept, or
+	// keeps them all when 0. Defaults to 2.
+	MaxHistory *int `json:"maxHistory,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=manual;patch;minor;latest
+type UpgradePolicyEnum string
+
+const (
+	UpgradePolicyManual UpgradePolicyEnum = "manual"
+	UpgradePolicyPatch  UpgradePolicyEnum = "patch"
+	UpgradePolicyMinor  UpgradePolicyEnum = "minor"
+	UpgradePolicyLatest UpgradePolicyEnum = "latest"
+)
+
+// +kubebuilder:validation:Enum=automatic;manual
+type ApprovalEnum string
+
+const (
+	ApprovalAutomatic ApprovalEnum = "automatic"
+	ApprovalManual    ApprovalEnum = "manual"
+)
+
+// +kubebuilder:validation:Enum=initializing;installing;installing dependencies;verifying;installed;degraded;upgrade pending;pending approval;updating;uninstalling;suspended;failed;unknown
 type StateEnum string
 
 const (
 	StateInitializing           StateEnum = "initializing"
 	StateInstalling             StateEnum = "installing"
 	StateInstallingDependencies StateEnum = "installing dependencies"
+	StateVerifying              StateEnum = "verifying"
 	StateInstalled              StateEnum = "installed"
+	StateDegraded               StateEnum = "degraded"
+	StateUpgradePending         StateEnum = "upgrade pending"
+	StatePendingApproval        StateEnum = "pending approval"
 	StateUpdating               StateEnum = "updating"
 	StateUninstalling           StateEnum = "uninstalling"
+	StateSuspended              StateEnum = "suspended"
+	StateFailed                 StateEnum = "failed"
 	StateUnknown                StateEnum = "unknown"
 )
 
+// FailureReasonEnum classifies the failure a status' detail describes, for
+// automation to act on.
+// +kubebuilder:validation:Enum=PackageNotFound;BundleNotFound;InvalidBundle;InvalidConfig;InvalidNamespace;InvalidArtifact;ArtifactNotFound;RegistryAuthFailed;RegistryUnreachable;ClusterUnreachable;HelmConflict;InstallFailed;UninstallFailed;RollbackFailed;UnexpectedImages;WorkloadsNotReady;Unknown
+type FailureReasonEnum string
+
+const (
+	// FailureReasonPackageNotFound is a package or version missing from the
+	// active bundle.
+	FailureReasonPackageNotFound FailureReasonEnum = "PackageNotFound"
+	// FailureReasonBundleNotFound is an active bundle or package bundle
+	// controller which can't be found.
+	FailureReasonBundleNotFound FailureReasonEnum = "BundleNotFound"
+	// FailureReasonInvalidBundle is a bundle which can't be read or whose
+	// packages are inconsistent.
+	FailureReasonInvalidBundle FailureReasonEnum = "InvalidBundle"
+	// FailureReasonInvalidConfig is a package configuration which can't be
+	// parsed or doesn't match the package's schema.
+	FailureReasonInvalidConfig FailureReasonEnum = "InvalidConfig"
+	// FailureReasonInvalidNamespace is a package outside the package
+	// namespaces.
+	FailureReasonInvalidNamespace FailureReasonEnum = "InvalidNamespace"
+	// FailureReasonInvalidArtifact is an artifact pulled from a registry
+	// which can't be read.
+	FailureReasonInvalidArtifact FailureReasonEnum = "InvalidArtifact"
+	// FailureReasonArtifactNotFound is an artifact missing from its registry.
+	FailureReasonArtifactNotFound FailureReasonEnum = "ArtifactNotFound"
+	// FailureReasonRegistryAuthFailed is a registry refusing the
+	// controller's credentials.
+	FailureReasonRegistryAuthFailed FailureReasonEnum = "RegistryAuthFailed"
+	// FailureReasonRegistryUnreachable is a registry which can't be reached.
+	FailureReasonRegistryUnreachable FailureReasonEnum = "RegistryUnreachable"
+	// FailureReasonClusterUnreachable is a target cluster whose kubeconfig
+	// can't be found or whose API server can't be reached.
+	FailureReasonClusterUnreachable FailureReasonEnum = "ClusterUnreachable"
+	// FailureReasonHelmConflict is a release locked by another operation, or
+	// resources owned by something else.
+	FailureReasonHelmConflict FailureReasonEnum = "HelmConflict"
+	// FailureReasonInstallFailed is any other failure to install or upgrade.
+	FailureReasonInstallFailed FailureReasonEnum = "InstallFailed"
+	// FailureReasonUninstallFailed is any other failure to uninstall.
+	FailureReasonUninstallFailed FailureReasonEnum = "UninstallFailed"
+	// FailureReasonRollbackFailed is any other failure to roll back.
+	FailureReasonRollbackFailed FailureReasonEnum = "RollbackFailed"
+	// FailureReasonUnexpectedImages is a package running images missing from
+	// its bundle.
+	FailureReasonUnexpectedImages FailureReasonEnum = "UnexpectedImages"
+	// FailureReasonWorkloadsNotReady is an installed package whose workloads
+	// aren't ready.
+	FailureReasonWorkloadsNotReady FailureReasonEnum = "WorkloadsNotReady"
+	// FailureReasonUnknown is a failure which isn't classified.
+	FailureReasonUnknown FailureReasonEnum = "Unknown"
+)
+
 // PackageStatus defines the observed state of Package.
 type PackageStatus struct {
 	// +kubebuilder:validation:Required
@@ -78,17 +266,60 @@ type PackageStatus struct {
 	// Version to be installed.
 	TargetVersion string `json:"targetVersion,omitempty"`
 
+	// InstalledDriver is the driver which installed the resources of the
+	// current version. Packages installed before it was recorded were all
+	// installed by Helm.
+	InstalledDriver DriverEnum `json:"installedDriver,omitempty"`
+
 	// State of the installation.
 	State StateEnum `json:"state,omitempty"`
 
 	// Detail of the state.
 	Detail string `json:"detail,omitempty"`
 
-	// UpgradesAvailable indicates upgraded versions in the bundle.
+	// Reason classifies the failure described by the detail, if any.
+	Reason FailureReasonEnum `json:"reason,omitempty"`
+
+	// RetryCount is the number of consecutive failed installation attempts.
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// LastAttemptTime is when the last installation attempt was made.
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+
+	// RolledBack details the last automatic rollback of a failed upgrade.
+	RolledBack *PackageRollback `json:"rolledBack,omitempty"`
+
+	// Drift lists the package's resources whose live state no longer matches
+	// the release.
+	Drift []string `json:"drift,omitempty"`
+
+	// UpgradesAvailable lists the versions newer than the current version in
+	// the active bundle and in newer available bundles, newest first.
 	UpgradesAvailable []PackageAvailableUpgrade `json:"upgradesAvailable,omitempty"`
 
+	// NextMaintenanceWindow is when the maintenance window a pending upgrade
+	// is waiting for opens.
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+
+	// History lists the package's most recent revisions, oldest first.
+	History []PackageRevision `json:"history,omitempty"`
+
+	// Plan describes the changes awaiting approval.
+	Plan *PackagePlan `json:"plan,omitempty"`
+
+	// ImagesVerifiedHash identifies the source and values whose images the
+	// ImagesVerified condition reports on, so they're only rendered again
+	// when either changes.
+	ImagesVerifiedHash string `json:"imagesVerifiedHash,omitempty"`
+
 	// Spec previous settings
 	Spec PackageSpec `json:"spec,omitempty"`
+
+	// Conditions of the package.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 type PackageOCISource struct {
@@ -104,6 +335,93 @@ type PackageOCISource struct {
 	// +kubebuilder:validation:Required
 	// Digest is a checksum value identifying the version of the package and its contents.
 	Digest string `json:"digest"`
+	// +kubebuilder:validation:Optional
+	// Driver installing the package.
+	Driver DriverEnum `json:"driver,omitempty"`
+}
+
+// PackagePlan describes the changes a package requiring approval would make.
+type PackagePlan struct {
+	// +kubebuilder:validation:Required
+	// Hash identifies the plan. Setting the approve annotation to it applies
+	// the plan.
+	Hash string `json:"hash"`
+
+	// FromVersion is the version currently installed.
+	FromVersion string `json:"fromVersion,omitempty"`
+
+	// +kubebuilder:validation:Required
+	// ToVersion is the version to be installed.
+	ToVersion string `json:"toVersion"`
+
+	// Changes summarizes each resource which would be added, changed or
+	// removed.
+	Changes []string `json:"changes,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=succeeded;failed;rolled back
+type RevisionResultEnum string
+
+const (
+	RevisionResultSucceeded  RevisionResultEnum = "succeeded"
+	RevisionResultFailed     RevisionResultEnum = "failed"
+	RevisionResultRolledBack RevisionResultEnum = "rolled back"
+)
+
+// PackageRevision records an installation, upgrade or rollback of a package.
+type PackageRevision struct {
+	// +kubebuilder:validation:Required
+	// Revision numbers the package's revisions in the order they were made.
+	Revision int64 `json:"revision"`
+
+	// +kubebuilder:validation:Required
+	// Version installed.
+	Version string `json:"version"`
+
+	// Digest of the package installed.
+	Digest string `json:"digest,omitempty"`
+
+	// Bundle the version was taken from.
+	Bundle string `json:"bundle,omitempty"`
+
+	// ConfigHash identifies the configuration installed.
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// HelmRevision is the revision of the Helm release, if one was made and
+	// is still kept to roll back to.
+	HelmRevision int `json:"helmRevision,omitempty"`
+
+	// RollbackOf is the revision a rollback restored.
+	RollbackOf int64 `json:"rollbackOf,omitempty"`
+
+	// +kubebuilder:validation:Required
+	// Time of the revision.
+	Time metav1.Time `json:"time"`
+
+	// +kubebuilder:validation:Required
+	// Result of the revision.
+	Result RevisionResultEnum `json:"result"`
+
+	// Detail of the result.
+	Detail string `json:"detail,omitempty"`
+}
+
+// PackageRollback details an automatic rollback of a failed upgrade.
+type PackageRollback struct {
+	// +kubebuilder:validation:Required
+	// Version the package was rolled back to.
+	Version string `json:"version"`
+
+	// +kubebuilder:validation:Required
+	// FailedVersion is the version whose upgrade failed.
+	FailedVersion string `json:"failedVersion"`
+
+	// Reason the upgrade failed.
+	Reason string `json:"reason,omitempty"`
+
+	// +kubebuilder:validation:Required
+	// Time of the rollback.
+	Time metav1.Time `json:"time"`
 }
 
 // PackageAvailableUpgrade details the package's available upgrade versions.
@@ -35,17 +35,6 @@ func (config *PackageBundle) FindPackage(pkgName string) (retPkg BundlePackage,
 	return retPkg, fmt.Errorf("package not found in bundle (%s): %s", config.Name, pkgName)
 }
 
-func (config *PackageBundle) GetDependencies(version SourceVersion) (dependencies []BundlePackage, err error) {
-	for _, dep := range version.Dependencies {
-		pkg, err := config.FindPackage(dep)
-		if err != nil {
-			return nil, err
-		}
-		dependencies = append(dependencies, pkg)
-	}
-	return dependencies, nil
-}
-
 func (config *PackageBundle) FindVersion(pkg BundlePackage, pkgVersion string) (ret SourceVersion, err error) {
 	source := pkg.Source
 	for _, packageVersion := range source.Versions {
@@ -76,7 +65,7 @@ func (config *PackageBundle) FindOCISource(pkg BundlePackage, pkgVersion string)
 
 func (config *PackageBundle) GetOCISource(pkg BundlePackage, packageVersion SourceVersion) (retSource PackageOCISource) {
 	source := pkg.Source
-	return PackageOCISource{Registry: source.Registry, Repository: source.Repository, Digest: packageVersion.Digest, Version: packageVersion.Name}
+	return PackageOCISource{Registry: source.Registry, Repository: source.Repository, Digest: packageVersion.Digest, Version: packageVersion.Name, Driver: source.Driver}
 }
 
 // LessThan evaluates if the left calling bundle is less than the supplied parameter
@@ -87,7 +76,24 @@ func (config *PackageBundle) GetOCISource(pkg BundlePackage, packageVersion Sour
 func (config PackageBundle) LessThan(rhsBundle *PackageBundle) bool {
 	lhsMajor, lhsMinor, lhsBuild, _ := config.getMajorMinorBuild()
 	rhsMajor, rhsMinor, rhsBuild, _ := rhsBundle.getMajorMinorBuild()
-	return lhsMajor < rhsMajor || lhsMinor < rhsMinor || lhsBuild < rhsBuild
+	if lhsMajor != rhsMajor {
+		return lhsMajor < rhsMajor
+	}
+	if lhsMinor != rhsMinor {
+		return lhsMinor < rhsMinor
+	}
+	return lhsBuild < rhsBuild
+}
+
+// SameKubeVersion returns true if both bundles are for the same Kubernetes
+// major and minor version.
+func (config *PackageBundle) SameKubeVersion(other *PackageBundle) bool {
+	major, minor, _, err := config.getMajorMinorBuild()
+	if err != nil {
+		return false
+	}
+	otherMajor, otherMinor, _, err := other.getMajorMinorBuild()
+	return err == nil && major == otherMajor && minor == otherMinor
 }
 
 // BundlesByVersion implements sort.Interface for PackageBundles.
@@ -161,10 +167,36 @@ func (config *PackageBundle) IsValidVersion() bool {
 	return err == nil
 }
 
+// GetArtifactUri returns the reference of the package's artifact, by digest
+// when it has one.
+func (s PackageOCISource) GetArtifactUri() string {
+	uri := path.Join(s.Registry, s.Repository)
+	if s.Digest != "" {
+		return uri + "@" + s.Digest
+	}
+	return uri + ":" + s.Version
+}
+
 func (s PackageOCISource) GetChartUri() string {
 	return "oci://" + path.Join(s.Registry, s.Repository)
 }
 
+// GetDriver returns the driver installing the package.
+func (s PackageOCISource) GetDriver() DriverEnum {
+	if s.Driver == "" {
+		return DriverHelm
+	}
+	return s.Driver
+}
+
+// GetDriver returns the driver installing the package.
+func (s BundlePackageSource) GetDriver() DriverEnum {
+	if s.Driver == "" {
+		return DriverHelm
+	}
+	return s.Driver
+}
+
 // PackageMatches returns true if the given source locations match one another.
 func (s BundlePackageSource) PackageMatches(other BundlePackageSource) bool {
 	if s.Registry != other.Registry {
@@ -173,6 +205,9 @@ func (s BundlePackageSource) PackageMatches(other BundlePackageSource) bool {
 	if s.Repository != other.Repository {
 		return false
 	}
+	if s.GetDriver() != other.GetDriver() {
+		return false
+	}
 
 	myVersions := make(map[string]struct{})
 	for _, packageVersion := range s.Versions {
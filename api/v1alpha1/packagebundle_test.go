@@ -277,6 +277,14 @@ func TestPackageMatches(t *testing.T) {
 		assert.False(t, result)
 	})
 
+	t.Run("package drivers must match", func(t *testing.T) {
+		other := orig
+		other.Driver = DriverManifest
+		assert.False(t, orig.PackageMatches(other))
+		other.Driver = DriverHelm
+		assert.True(t, orig.PackageMatches(other))
+	})
+
 	t.Run("package added versions cause mismatch", func(t *testing.T) {
 		other := BundlePackageSource{
 			Registry:   "registry",
@@ -317,6 +325,13 @@ func TestPackageMatches(t *testing.T) {
 	})
 }
 
+func TestPackageOCISource_GetArtifactUri(t *testing.T) {
+	source := PackageOCISource{Registry: "public.ecr.aws/eks-anywhere", Repository: "addon", Version: "v1"}
+	assert.Equal(t, "public.ecr.aws/eks-anywhere/addon:v1", source.GetArtifactUri())
+	source.Digest = "sha256:deadbeef"
+	assert.Equal(t, "public.ecr.aws/eks-anywhere/addon@sha256:deadbeef", source.GetArtifactUri())
+}
+
 func TestSourceVersionKey(t *testing.T) {
 	t.Parallel()
 
@@ -391,6 +406,26 @@ func TestIsNewer(t *testing.T) {
 		candidate := givenBundle("v1-22-10002")
 		assert.True(t, current.LessThan(&candidate))
 	})
+
+	t.Run("older kube minor version with a newer build", func(t *testing.T) {
+		t.Parallel()
+
+		current := givenBundle("v1-22-10002")
+		candidate := givenBundle("v1-21-10003")
+		assert.False(t, current.LessThan(&candidate))
+		assert.True(t, candidate.LessThan(&current))
+	})
+}
+
+func TestPackageBundle_SameKubeVersion(t *testing.T) {
+	givenBundle := func(name string) *PackageBundle {
+		return &PackageBundle{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	assert.True(t, givenBundle("v1-21-10002").SameKubeVersion(givenBundle("v1-21-10003")))
+	assert.False(t, givenBundle("v1-21-10002").SameKubeVersion(givenBundle("v1-22-10002")))
+	assert.False(t, givenBundle("v1-21-10002").SameKubeVersion(givenBundle("v2-21-10002")))
+	assert.False(t, givenBundle("v1-21-10002").SameKubeVersion(givenBundle("invalid")))
 }
 
 func TestGetPackageFromBundle(t *testing.T) {
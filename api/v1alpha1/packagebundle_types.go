@@ -22,6 +22,7 @@ import (
 // +kubebuilder:subresource:status
 // +kubebuilder:webhook:path=/validate-packages-eks-amazonaws-com-v1alpha1-packagebundle,mutating=false,failurePolicy=fail,sideEffects=None,groups=packages.eks.amazonaws.com,resources=packagebundles,verbs=create;update,versions=v1alpha1,name=vpackagebundle.kb.io,admissionReviewVersions=v1
 // +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // PackageBundle is the Schema for the packagebundle API.
 type PackageBundle struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -70,8 +71,22 @@ type BundlePackageSource struct {
 	// +kubebuilder:validation:MinItems=1
 	// Versions of the package supported by this bundle.
 	Versions []SourceVersion `json:"versions"`
+
+	// +kubebuilder:validation:Optional
+	// Driver installing the package: helm for a Helm chart, the default, or
+	// manifest for an artifact of plain Kubernetes manifests or a Kustomize
+	// overlay.
+	Driver DriverEnum `json:"driver,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=helm;manifest
+type DriverEnum string
+
+const (
+	DriverHelm     DriverEnum = "helm"
+	DriverManifest DriverEnum = "manifest"
+)
+
 // SourceVersion describes a version of a package within a repository.
 type SourceVersion struct {
 	// +kubebuilder:validation:Required
@@ -89,7 +104,8 @@ type SourceVersion struct {
 	Schema string `json:"schema,omitempty"`
 
 	// +kubebuilder:validation:Optional
-	// Dependencies to be installed before the package
+	// Dependencies to be installed before the package, optionally with a
+	// semver constraint on their version, e.g. "cert-manager>=1.12".
 	Dependencies []string `json:"dependencies,omitempty"`
 }
 
@@ -108,6 +124,12 @@ type VersionImages struct {
 type PackageBundleStatus struct {
 	Spec  PackageBundleSpec      `json:"spec,omitempty"`
 	State PackageBundleStateEnum `json:"state"`
+
+	// Conditions of the package bundle.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:validation:Enum={"available","ignored","invalid","controller upgrade required"}
@@ -32,6 +32,8 @@ const (
 // +kubebuilder:resource:shortName=pbc,path=packagebundlecontrollers
 // +kubebuilder:printcolumn:name="ActiveBundle",type=string,JSONPath=`.spec.activeBundle`
 // +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="UpgradeAvailable",type=string,JSONPath=`.status.conditions[?(@.type=="UpgradeAvailable")].status`
 // +kubebuilder:printcolumn:name="Detail",type=string,JSONPath=`.status.detail`
 // PackageBundleController is the Schema for the packagebundlecontroller API.
 type PackageBundleController struct {
@@ -90,6 +92,35 @@ type PackageBundleControllerSpec struct {
 	// Allow target namespace creation by the controller
 	// +optional
 	CreateNamespace bool `json:"createNamespace"`
+
+	// MaintenanceWindows restrict package upgrades to the times they are
+	// open. Packages are upgraded at any time when there are none.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// Suspend stops every package on the cluster from being installed,
+	// upgraded or reconfigured until it is unset.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// InstallOptions configure how the releases of packages are installed
+	// and upgraded, unless a package sets its own.
+	// +optional
+	InstallOptions *InstallOptions `json:"installOptions,omitempty"`
+}
+
+// MaintenanceWindow is a recurring period during which packages may be
+// upgraded.
+type MaintenanceWindow struct {
+	// +kubebuilder:validation:Required
+	// Schedule is a cron expression for when the window opens, e.g.
+	// "0 2 * * 6" for 2am every Saturday. It is in UTC unless prefixed with
+	// a time zone, as in "CRON_TZ=Europe/Paris 0 2 * * 6".
+	Schedule string `json:"schedule"`
+
+	// +kubebuilder:validation:Required
+	// Duration is how long the window stays open.
+	Duration metav1.Duration `json:"duration"`
 }
 
 // +kubebuilder:validation:Enum=ignored;active;disconnected;upgrade available
@@ -111,8 +142,17 @@ type PackageBundleControllerStatus struct {
 	// Detail of the state.
 	Detail string `json:"detail,omitempty"`
 
+	// Reason classifies the failure described by the detail, if any.
+	Reason FailureReasonEnum `json:"reason,omitempty"`
+
 	// Spec previous settings
 	Spec PackageBundleControllerSpec `json:"spec,omitempty"`
+
+	// Conditions of the package bundle controller.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
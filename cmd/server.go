@@ -34,6 +34,7 @@ import (
 	"github.com/aws/eks-anywhere-packages/controllers"
 	pkgConfig "github.com/aws/eks-anywhere-packages/pkg/config"
 	"github.com/aws/eks-anywhere-packages/pkg/registry"
+	"github.com/aws/eks-anywhere-packages/pkg/tracing"
 	"github.com/aws/eks-anywhere-packages/pkg/webhook"
 )
 
@@ -43,6 +44,9 @@ type serverContext struct {
 	metricsAddr          string
 	enableLeaderElection bool
 	probeAddr            string
+
+	packageMaxConcurrentReconciles int
+	tracing                        tracing.Config
 }
 
 var serverCommandContext = &serverContext{}
@@ -61,6 +65,14 @@ func init() {
 	serverCommand.Flags().BoolVar(&serverCommandContext.enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	serverCommand.Flags().IntVar(&serverCommandContext.packageMaxConcurrentReconciles, "package-max-concurrent-reconciles", 1,
+		"The maximum number of packages reconciled at once. Packages on the same cluster are reconciled one at a time.")
+	serverCommand.Flags().StringVar(&serverCommandContext.tracing.Endpoint, "tracing-endpoint", "",
+		"The host:port of the OTLP gRPC collector traces are exported to. Tracing is disabled if empty.")
+	serverCommand.Flags().BoolVar(&serverCommandContext.tracing.Insecure, "tracing-insecure", false,
+		"Export traces without TLS.")
+	serverCommand.Flags().Float64Var(&serverCommandContext.tracing.SampleRatio, "tracing-sample-ratio", 1,
+		"The fraction of traces sampled.")
 }
 
 func server() error {
@@ -78,6 +90,17 @@ func server() error {
 		config.QPS = -1
 		config.Burst = -1
 	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), serverCommandContext.tracing)
+	if err != nil {
+		return fmt.Errorf("unable to set up tracing: %v", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			packageLog.Error(err, "shutting down tracing")
+		}
+	}()
+
 	mgr, err := ctrl.NewManager(config, ctrl.Options{
 		Scheme: scheme,
 		Metrics: ctrlmetricsserver.Options{
@@ -106,7 +129,7 @@ func server() error {
 	if err = controllers.RegisterPackageBundleControllerReconciler(mgr); err != nil {
 		return fmt.Errorf("unable to register package bundle controller controller: %v", err)
 	}
-	if err = controllers.RegisterPackageReconciler(mgr); err != nil {
+	if err = controllers.RegisterPackageReconciler(mgr, serverCommandContext.packageMaxConcurrentReconciles); err != nil {
 		return fmt.Errorf("unable to register package controller: %v", err)
 	}
 
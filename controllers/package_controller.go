@@ -17,15 +17,22 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
@@ -34,12 +41,21 @@ import (
 	"github.com/aws/eks-anywhere-packages/pkg/bundle"
 	"github.com/aws/eks-anywhere-packages/pkg/config"
 	"github.com/aws/eks-anywhere-packages/pkg/driver"
+	"github.com/aws/eks-anywhere-packages/pkg/metrics"
 	"github.com/aws/eks-anywhere-packages/pkg/packages"
+	"github.com/aws/eks-anywhere-packages/pkg/tracing"
 )
 
 const (
 	packageName = "Package"
 	retryLong   = time.Second * time.Duration(60)
+
+	// eventSource is the component name events are recorded under.
+	eventSource = "eks-anywhere-packages"
+
+	// valuesFromField indexes packages by the Secrets and ConfigMaps their
+	// configuration comes from.
+	valuesFromField = "spec.valuesFrom"
 )
 
 // PackageReconciler reconciles a Package object
@@ -47,62 +63,115 @@ type PackageReconciler struct {
 	client.Client
 	Log           logr.Logger
 	Scheme        *runtime.Scheme
-	PackageDriver driver.PackageDriver
+	Drivers       *driver.Pool
 	Manager       packages.Manager
 	bundleManager bundle.Manager
 	managerClient bundle.Client
+	recorder      record.EventRecorder
 }
 
 func NewPackageReconciler(client client.Client, scheme *runtime.Scheme,
-	driver driver.PackageDriver, manager packages.Manager,
+	drivers *driver.Pool, manager packages.Manager,
 	bundleManager bundle.Manager, managerClient bundle.Client,
-	log logr.Logger,
+	recorder record.EventRecorder, log logr.Logger,
 ) *PackageReconciler {
 	return &PackageReconciler{
 		Client:        client,
 		Scheme:        scheme,
-		PackageDriver: driver,
+		Drivers:       drivers,
 		Manager:       manager,
 		bundleManager: bundleManager,
 		managerClient: managerClient,
+		recorder:      recorder,
 		Log:           log,
 	}
 }
 
-func RegisterPackageReconciler(mgr ctrl.Manager) (err error) {
+// RegisterPackageReconciler registers the package controller, reconciling up
+// to maxConcurrentReconciles packages at once. Packages on the same cluster
+// are still reconciled one at a time.
+func RegisterPackageReconciler(mgr ctrl.Manager, maxConcurrentReconciles int) (err error) {
 	log := ctrl.Log.WithName(packageName)
 	manager := packages.NewManager()
 	cfg := mgr.GetConfig()
 
-	secretAuth, err := auth.NewECRSecret(cfg)
-	if err != nil {
-		return err
-	}
+	puller := artifacts.NewRegistryPuller(log)
+	// Drivers keep the state of the cluster they are used for, so each
+	// cluster has its own.
+	drivers := driver.NewPool(func(clusterName string, kind api.DriverEnum) (driver.PackageDriver, error) {
+		tcc := auth.NewTargetClusterClient(log, cfg, mgr.GetClient())
+		if kind == api.DriverManifest {
+			return driver.NewManifest(log, puller, tcc), nil
+		}
+		secretAuth, err := auth.NewECRSecret(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return driver.NewHelm(log, secretAuth, tcc), nil
+	})
 
 	tcc := auth.NewTargetClusterClient(log, cfg, mgr.GetClient())
-	helmDriver := driver.NewHelm(log, secretAuth, tcc)
-
-	puller := artifacts.NewRegistryPuller(log)
 	registryClient := bundle.NewRegistryClient(puller)
-	managerClient := bundle.NewManagerClient(mgr.GetClient())
-	bundleManager := bundle.NewBundleManager(log, registryClient, managerClient, tcc, config.GetGlobalConfig())
+	// Package configuration is read from the API server, as the Secrets and
+	// ConfigMaps it comes from are only watched by their metadata.
+	managerClient := bundle.NewManagerClientWithValuesReader(mgr.GetClient(), mgr.GetAPIReader())
+	recorder := mgr.GetEventRecorderFor(eventSource)
+	bundleManager := bundle.NewBundleManager(log, registryClient, managerClient, tcc, config.GetGlobalConfig(), recorder)
 	reconciler := NewPackageReconciler(
 		mgr.GetClient(),
 		mgr.GetScheme(),
-		helmDriver,
+		drivers,
 		manager,
 		bundleManager,
 		managerClient,
+		recorder,
 		log,
 	)
 
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &api.Package{}, valuesFromField, indexValuesFrom)
+	if err != nil {
+		return fmt.Errorf("indexing packages by their values: %w", err)
+	}
+
+	// Only the metadata of Secrets and ConfigMaps is watched, and only
+	// changes in package namespaces are mapped, so Helm's release secrets
+	// and the rest of the cluster's objects are neither cached in full nor
+	// looked at.
+	valuesPredicate := builder.WithPredicates(predicate.NewPredicateFuncs(isPackageValues))
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&api.Package{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}).
 		Watches(&api.PackageBundle{},
 			handler.EnqueueRequestsFromMapFunc(reconciler.mapBundleChangesToPackageUpdate)).
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(reconciler.mapValuesChangesToPackageUpdate("Secret")),
+			builder.OnlyMetadata, valuesPredicate).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(reconciler.mapValuesChangesToPackageUpdate("ConfigMap")),
+			builder.OnlyMetadata, valuesPredicate).
 		Complete(reconciler)
 }
 
+// indexValuesFrom returns the keys of the Secrets and ConfigMaps the
+// configuration of a package comes from.
+func indexValuesFrom(obj client.Object) []string {
+	var keys []string
+	for _, ref := range obj.(*api.Package).Spec.ValuesFrom {
+		keys = append(keys, valuesKey(ref.Kind, ref.Name))
+	}
+	return keys
+}
+
+func valuesKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// isPackageValues returns true if a Secret or ConfigMap may hold the
+// configuration of packages, which only come from their own namespace.
+func isPackageValues(obj client.Object) bool {
+	return strings.HasPrefix(obj.GetNamespace(), api.PackageNamespace) && obj.GetLabels()["owner"] != "helm"
+}
+
 func (r *PackageReconciler) mapBundleChangesToPackageUpdate(_ context.Context, _ client.Object) (req []reconcile.Request) {
 	ctx := context.Background()
 	objs := &api.PackageList{}
@@ -123,6 +192,33 @@ func (r *PackageReconciler) mapBundleChangesToPackageUpdate(_ context.Context, _
 	return req
 }
 
+// mapValuesChangesToPackageUpdate requests reconciliation of the packages
+// whose configuration comes from a changed Secret or ConfigMap.
+func (r *PackageReconciler) mapValuesChangesToPackageUpdate(kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) (req []reconcile.Request) {
+		objs := &api.PackageList{}
+		err := r.List(ctx, objs, client.InNamespace(obj.GetNamespace()),
+			client.MatchingFields{valuesFromField: valuesKey(kind, obj.GetName())})
+		if err != nil {
+			return req
+		}
+
+		for _, o := range objs.Items {
+			if !o.ReferencesValues(kind, obj.GetName()) {
+				continue
+			}
+			req = append(req, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: o.GetNamespace(),
+					Name:      o.GetName(),
+				},
+			})
+		}
+
+		return req
+	}
+}
+
 //+kubebuilder:rbac:groups=packages.eks.amazonaws.com,resources=packages,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=packages.eks.amazonaws.com,resources=packages/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=packages.eks.amazonaws.com,resources=packages/finalizers,verbs=update
@@ -130,24 +226,70 @@ func (r *PackageReconciler) mapBundleChangesToPackageUpdate(_ context.Context, _
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, err error) {
 	r.Log.V(6).Info("Reconcile:", "NamespacedName", req.NamespacedName)
-	managerContext := packages.NewManagerContext(ctx, r.Log, r.PackageDriver)
+	ctx, span := tracing.Start(ctx, "Reconcile package", tracing.PackageKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
+	managerContext := packages.NewManagerContext(ctx, r.Log, nil, r.recorder)
 	managerContext.ManagerClient = r.managerClient
 
 	// Get the CRD object from the k8s API.
-	var err error
 	if err = r.Get(ctx, req.NamespacedName, &managerContext.Package); err != nil {
 		if !apierrors.IsNotFound(err) {
 			return ctrl.Result{}, err
 		}
+		r.Log.V(6).Info("Package deleted (ignoring)", "NamespacedName", req.NamespacedName)
+		metrics.ForgetPackage(req.NamespacedName.String())
+		return ctrl.Result{}, nil
+	}
 
-		managerContext.SetUninstalling(req.Namespace, req.Name)
+	span.SetAttributes(tracing.ClusterKey.String(managerContext.Package.GetClusterName()))
+	drivers, ok := r.Drivers.TryAcquire(managerContext.Package.GetClusterName())
+	if !ok {
+		// The package is requeued through the rate limiter, so that it backs
+		// off for as long as the cluster stays busy.
+		r.Log.V(6).Info("Cluster busy, requeueing", "NamespacedName", req.NamespacedName)
+		return ctrl.Result{Requeue: true}, nil
+	}
+	defer drivers.Release()
+	// Packages are uninstalled by the driver which installed them.
+	managerContext.PackageDriver, err = drivers.Get(managerContext.Package.Status.InstalledDriver)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !managerContext.Package.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&managerContext.Package, api.PackageFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		managerContext.SetUninstalling()
+		if managerContext.Package.IsCascadeDelete() {
+			// The bundle is needed to find the packages depending on this one.
+			managerContext.Bundle, err = r.managerClient.GetActiveBundle(ctx, managerContext.Package.GetClusterName())
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("getting active bundle: %w", err)
+			}
+		}
 	} else {
+		if managerContext.Package.IsValidNamespace() && controllerutil.AddFinalizer(&managerContext.Package, api.PackageFinalizer) {
+			if err = r.managerClient.Save(ctx, &managerContext.Package); err != nil {
+				return ctrl.Result{}, fmt.Errorf("adding finalizer: %w", err)
+			}
+		}
+
+		// A pinned dependency must not be garbage collected along with the
+		// packages depending on it.
+		if managerContext.Package.IsPinned() && managerContext.Package.RemoveDependents() {
+			if err = r.managerClient.Save(ctx, &managerContext.Package); err != nil {
+				return ctrl.Result{}, fmt.Errorf("removing dependents from pinned package: %w", err)
+			}
+		}
+
 		pbc, err := r.managerClient.GetPackageBundleController(ctx, managerContext.Package.GetClusterName())
 		if err != nil {
 			r.Log.Error(err, "Getting package bundle controller")
 			managerContext.Package.Status.Detail = err.Error()
+			managerContext.Package.Status.Reason = api.FailureReasonBundleNotFound
 			if err = r.Status().Update(ctx, &managerContext.Package); err != nil {
 				return ctrl.Result{RequeueAfter: retryLong}, err
 			}
@@ -159,12 +301,21 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		if err != nil {
 			r.Log.Error(err, "Getting active bundle")
 			managerContext.Package.Status.Detail = err.Error()
+			managerContext.Package.Status.Reason = api.FailureReasonBundleNotFound
 			if err = r.Status().Update(ctx, &managerContext.Package); err != nil {
 				return ctrl.Result{RequeueAfter: retryLong}, err
 			}
 			return ctrl.Result{RequeueAfter: retryLong}, nil
 		}
 		managerContext.Bundle = bundle
+		span.SetAttributes(tracing.BundleKey.String(bundle.Name))
+
+		// Newer bundles are only used to report available upgrades, so
+		// failing to list them shouldn't hold up the package.
+		managerContext.Bundles, err = r.managerClient.GetBundleList(ctx)
+		if err != nil {
+			r.Log.Error(err, "Listing bundles")
+		}
 
 		targetVersion := managerContext.Package.Spec.PackageVersion
 		if targetVersion == "" {
@@ -174,15 +325,17 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		pkg, err := bundle.FindPackage(pkgName)
 		if err != nil {
 			managerContext.Package.Status.Detail = fmt.Sprintf("Package %s is not in the active bundle (%s).", pkgName, bundle.Name)
+			managerContext.Package.Status.Reason = api.FailureReasonPackageNotFound
 			r.Log.Info(managerContext.Package.Status.Detail)
 			if err = r.Status().Update(ctx, &managerContext.Package); err != nil {
 				return ctrl.Result{RequeueAfter: managerContext.RequeueAfter}, err
 			}
 			return ctrl.Result{RequeueAfter: retryLong}, err
 		}
-		managerContext.Version, err = bundle.FindVersion(pkg, targetVersion)
+		managerContext.Version, err = packages.FindVersion(bundle, pkg, &managerContext.Package)
 		if err != nil {
 			managerContext.Package.Status.Detail = fmt.Sprintf("Package %s@%s is not in the active bundle (%s).", pkgName, targetVersion, bundle.Name)
+			managerContext.Package.Status.Reason = api.FailureReasonPackageNotFound
 			r.Log.Info(managerContext.Package.Status.Detail)
 			if err = r.Status().Update(ctx, &managerContext.Package); err != nil {
 				return ctrl.Result{RequeueAfter: managerContext.RequeueAfter}, err
@@ -190,10 +343,21 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			return ctrl.Result{RequeueAfter: retryLong}, err
 		}
 		managerContext.Source = bundle.GetOCISource(pkg, managerContext.Version)
-		managerContext.Package.Status.TargetVersion = printableTargetVersion(managerContext.Source, targetVersion)
+		installed := managerContext.PackageDriver
+		managerContext.PackageDriver, err = drivers.Get(managerContext.Source.Driver)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		// A package whose driver changed is uninstalled by the old driver
+		// before the new one installs it.
+		if managerContext.PackageDriver != installed && managerContext.Package.Status.CurrentVersion != "" {
+			managerContext.InstalledDriver = installed
+		}
+		managerContext.Package.Status.TargetVersion = printableTargetVersion(managerContext.Source, targetVersion, managerContext.Package.Spec.UpgradePolicy)
 	}
 
 	updateNeeded := r.Manager.Process(managerContext)
+	metrics.RecordPackage(req.NamespacedName.String(), string(managerContext.Package.Status.State), managerContext.Package.GetClusterName())
 	if updateNeeded {
 		r.Log.V(6).Info("Updating status", "namespace", managerContext.Package.Namespace, "name", managerContext.Package.Name, "state", managerContext.Package.Status.State)
 		if err = r.Status().Update(ctx, &managerContext.Package); err != nil {
@@ -204,12 +368,16 @@ func (r *PackageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	return ctrl.Result{RequeueAfter: managerContext.RequeueAfter}, nil
 }
 
-func printableTargetVersion(source api.PackageOCISource, targetVersion string) string {
-	ret := targetVersion
-	if targetVersion == api.Latest {
-		ret = fmt.Sprintf("%s (%s)", source.Version, targetVersion)
+func printableTargetVersion(source api.PackageOCISource, targetVersion string, policy api.UpgradePolicyEnum) string {
+	switch {
+	case policy == api.UpgradePolicyManual:
+		return source.Version
+	case policy != "":
+		return fmt.Sprintf("%s (%s)", source.Version, policy)
+	case targetVersion == api.Latest:
+		return fmt.Sprintf("%s (%s)", source.Version, targetVersion)
 	}
-	return ret
+	return targetVersion
 }
 
 // SetupWithManager sets up the controller with the Manager.
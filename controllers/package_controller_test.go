@@ -10,14 +10,21 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	ctrlmocks "github.com/aws/eks-anywhere-packages/controllers/mocks"
 	bundleMocks "github.com/aws/eks-anywhere-packages/pkg/bundle/mocks"
+	"github.com/aws/eks-anywhere-packages/pkg/driver"
 	drivermocks "github.com/aws/eks-anywhere-packages/pkg/driver/mocks"
 	"github.com/aws/eks-anywhere-packages/pkg/packages"
 	packageMocks "github.com/aws/eks-anywhere-packages/pkg/packages/mocks"
@@ -31,6 +38,7 @@ func TestReconcile(t *testing.T) {
 
 		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
 		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(tf.mockBundle(), nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, nil)
 
 		fn, pkg := tf.mockGetFnPkg()
 		tf.ctrlClient.EXPECT().
@@ -67,6 +75,7 @@ func TestReconcile(t *testing.T) {
 
 		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
 		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(tf.mockBundle(), nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, nil)
 
 		fn, pkg := tf.mockGetFnPkg()
 		tf.ctrlClient.EXPECT().
@@ -108,6 +117,185 @@ func TestReconcile(t *testing.T) {
 		assert.Equal(t, expected, got.RequeueAfter)
 	})
 
+	t.Run("ignores deleted packages", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		pkg := tf.mockPackage()
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			Return(apierrors.NewNotFound(schema.GroupResource{}, name))
+
+		sut := tf.newReconciler()
+		got, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, got)
+	})
+
+	t.Run("adds the finalizer", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
+		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(tf.mockBundle(), nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, nil)
+
+		fn, pkg := tf.mockGetFnPkg()
+		pkg.Finalizers = nil
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+		tf.bundleClient.EXPECT().
+			Save(ctx, gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(func(_ context.Context, object client.Object) error {
+				assert.Equal(t, []string{api.PackageFinalizer}, object.GetFinalizers())
+				return nil
+			})
+
+		tf.packageManager.EXPECT().
+			Process(gomock.Any()).
+			Return(false)
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("handles errors adding the finalizer", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		fn, pkg := tf.mockGetFnPkg()
+		pkg.Finalizers = nil
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+		tf.bundleClient.EXPECT().
+			Save(ctx, gomock.AssignableToTypeOf(pkg)).
+			Return(errors.New("oops"))
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.EqualError(t, err, "adding finalizer: oops")
+	})
+
+	t.Run("removes dependents from pinned packages", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
+		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(tf.mockBundle(), nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, nil)
+
+		fn, pkg := tf.mockGetFnPkg()
+		pkg.Annotations = map[string]string{api.DependencyAnnotation: "true", api.PinnedAnnotation: "true"}
+		pkg.OwnerReferences = []metav1.OwnerReference{{APIVersion: api.GroupVersion.String(), Kind: api.PackageKind, Name: "dependent", UID: "uid"}}
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+		tf.bundleClient.EXPECT().
+			Save(ctx, gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(func(_ context.Context, object client.Object) error {
+				assert.Empty(t, object.GetOwnerReferences())
+				return nil
+			})
+
+		tf.packageManager.EXPECT().
+			Process(gomock.Any()).
+			Return(false)
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("uninstalls deleting packages", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		fn, pkg := tf.mockGetFnPkg()
+		now := metav1.Now()
+		pkg.DeletionTimestamp = &now
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+
+		tf.packageManager.EXPECT().
+			Process(gomock.Any()).
+			DoAndReturn(func(mc *packages.ManagerContext) bool {
+				assert.Equal(t, api.StateUninstalling, mc.Package.Status.State)
+				assert.Equal(t, "billy", mc.Package.GetClusterName())
+				return false
+			})
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("uninstalls deleting packages with cascade", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		fn, pkg := tf.mockGetFnPkg()
+		now := metav1.Now()
+		pkg.DeletionTimestamp = &now
+		pkg.Annotations = map[string]string{api.CascadeDeleteAnnotation: "true"}
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+		bundle := tf.mockBundle()
+		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(bundle, nil)
+
+		tf.packageManager.EXPECT().
+			Process(gomock.Any()).
+			DoAndReturn(func(mc *packages.ManagerContext) bool {
+				assert.Equal(t, api.StateUninstalling, mc.Package.Status.State)
+				assert.Equal(t, bundle, mc.Bundle)
+				return false
+			})
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("handles errors getting the bundle for cascading uninstalls", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		fn, pkg := tf.mockGetFnPkg()
+		now := metav1.Now()
+		pkg.DeletionTimestamp = &now
+		pkg.Annotations = map[string]string{api.CascadeDeleteAnnotation: "true"}
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(nil, errors.New("oops"))
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.EqualError(t, err, "getting active bundle: oops")
+	})
+
+	t.Run("ignores deleting packages without the finalizer", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		fn, pkg := tf.mockGetFnPkg()
+		now := metav1.Now()
+		pkg.DeletionTimestamp = &now
+		pkg.Finalizers = []string{"other"}
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+
+		sut := tf.newReconciler()
+		got, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+		assert.Equal(t, ctrl.Result{}, got)
+	})
+
 	t.Run("handles errors getting the active bundle", func(t *testing.T) {
 		tf, ctx := newTestFixtures(t)
 
@@ -166,6 +354,7 @@ func TestReconcile(t *testing.T) {
 			DoAndReturn(fn)
 		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
 		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(tf.mockBundle(), nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, nil)
 
 		tf.packageManager.EXPECT().
 			Process(gomock.Any()).
@@ -205,6 +394,7 @@ func TestReconcile(t *testing.T) {
 		newBundle := tf.mockBundle()
 		newBundle.ObjectMeta.Name = "fake bundle"
 		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(newBundle, nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, nil)
 
 		testErr := errors.New("status update test error")
 		status := tf.mockStatusWriter()
@@ -212,6 +402,7 @@ func TestReconcile(t *testing.T) {
 		pkg.Spec.PackageVersion = "2.0.0"
 		pkg.Status.TargetVersion = "2.0.0"
 		pkg.Status.Detail = fmt.Sprintf("Package %s@%s is not in the active bundle (%s).", pkg.Spec.PackageName, pkg.Spec.PackageVersion, "fake bundle")
+		pkg.Status.Reason = api.FailureReasonPackageNotFound
 		status.EXPECT().
 			Update(ctx, pkg).
 			Return(testErr)
@@ -229,6 +420,202 @@ func TestReconcile(t *testing.T) {
 		assert.Equal(t, expected, got.RequeueAfter)
 	})
 
+	t.Run("passes bundles to the manager to find upgrades", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
+		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(tf.mockBundle(), nil)
+		bundles := []api.PackageBundle{*tf.mockBundle()}
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(bundles, nil)
+
+		fn, pkg := tf.mockGetFnPkg()
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+
+		tf.packageManager.EXPECT().
+			Process(gomock.Any()).
+			DoAndReturn(func(mc *packages.ManagerContext) bool {
+				assert.Equal(t, bundles, mc.Bundles)
+				assert.Same(t, tf.packageDriver, mc.PackageDriver)
+				return false
+			})
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("installs with the driver chosen by the bundle", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		bundle := tf.mockBundle()
+		bundle.Spec.Packages[0].Source.Driver = api.DriverManifest
+		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
+		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(bundle, nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, nil)
+
+		fn, pkg := tf.mockGetFnPkg()
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+
+		tf.packageManager.EXPECT().
+			Process(gomock.Any()).
+			DoAndReturn(func(mc *packages.ManagerContext) bool {
+				assert.Equal(t, api.DriverManifest, mc.Source.Driver)
+				assert.Same(t, tf.manifestDriver, mc.PackageDriver)
+				assert.Nil(t, mc.InstalledDriver)
+				return false
+			})
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("keeps the driver which installed a package moved to another", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		bundle := tf.mockBundle()
+		bundle.Spec.Packages[0].Source.Driver = api.DriverManifest
+		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
+		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(bundle, nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, nil)
+
+		fn, pkg := tf.mockGetFnPkg()
+		pkg.Status.CurrentVersion = "0.1.0"
+		pkg.Status.InstalledDriver = api.DriverHelm
+		// The package's source already names the new driver once its
+		// upgrade has started.
+		pkg.Status.Source.Driver = api.DriverManifest
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+
+		tf.packageManager.EXPECT().
+			Process(gomock.Any()).
+			DoAndReturn(func(mc *packages.ManagerContext) bool {
+				assert.Same(t, tf.manifestDriver, mc.PackageDriver)
+				assert.Same(t, tf.packageDriver, mc.InstalledDriver)
+				return false
+			})
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("backs off while the drivers of the cluster are busy", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		fn, pkg := tf.mockGetFnPkg()
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+
+		sut := tf.newReconciler()
+		drivers, ok := sut.Drivers.TryAcquire("billy")
+		require.True(t, ok)
+		defer drivers.Release()
+		result, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+		assert.True(t, result.Requeue)
+		assert.Zero(t, result.RequeueAfter)
+
+		// Requeued packages are delayed by the workqueue's rate limiter,
+		// which backs off as the cluster stays busy.
+		limiter := workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]()
+		first := limiter.When(tf.mockRequest())
+		assert.Greater(t, limiter.When(tf.mockRequest()), first)
+	})
+
+	t.Run("uninstalls with the driver which installed the package", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		fn, pkg := tf.mockGetFnPkg()
+		now := metav1.Now()
+		pkg.DeletionTimestamp = &now
+		pkg.Status.InstalledDriver = api.DriverManifest
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+
+		tf.packageManager.EXPECT().
+			Process(gomock.Any()).
+			DoAndReturn(func(mc *packages.ManagerContext) bool {
+				assert.Same(t, tf.manifestDriver, mc.PackageDriver)
+				return false
+			})
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("ignores errors listing bundles", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
+		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(tf.mockBundle(), nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, errors.New("oops"))
+
+		fn, pkg := tf.mockGetFnPkg()
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+
+		tf.packageManager.EXPECT().
+			Process(gomock.Any()).
+			DoAndReturn(func(mc *packages.ManagerContext) bool {
+				assert.Empty(t, mc.Bundles)
+				return false
+			})
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("follows the upgrade policy", func(t *testing.T) {
+		tf, ctx := newTestFixtures(t)
+
+		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
+		bundle := tf.mockBundle()
+		bundle.Spec.Packages[0].Source.Versions = []api.SourceVersion{
+			{Name: "0.2.0", Digest: "sha256:deadbeef020"},
+			{Name: "0.1.2", Digest: "sha256:deadbeef012"},
+			{Name: "0.1.1", Digest: "sha256:deadbeef"},
+		}
+		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(bundle, nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, nil)
+
+		fn, pkg := tf.mockGetFnPkg()
+		pkg.Spec.UpgradePolicy = api.UpgradePolicyPatch
+		tf.ctrlClient.EXPECT().
+			Get(ctx, gomock.Any(), gomock.AssignableToTypeOf(pkg)).
+			DoAndReturn(fn)
+
+		tf.packageManager.EXPECT().
+			Process(gomock.Any()).
+			DoAndReturn(func(mc *packages.ManagerContext) bool {
+				assert.Equal(t, "0.1.2", mc.Source.Version)
+				assert.Equal(t, "0.1.2 (patch)", mc.Package.Status.TargetVersion)
+				return false
+			})
+
+		sut := tf.newReconciler()
+		_, err := sut.Reconcile(ctx, tf.mockRequest())
+
+		assert.NoError(t, err)
+	})
+
 	t.Run("Packages without version hold upgrade to latest", func(t *testing.T) {
 		tf, ctx := newTestFixtures(t)
 
@@ -239,6 +626,7 @@ func TestReconcile(t *testing.T) {
 		pkg.Spec.PackageVersion = ""
 		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
 		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(tf.mockBundle(), nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, nil)
 		newBundle := tf.mockBundle()
 		newBundle.Spec.Packages[0].Source.Versions = []api.SourceVersion{{
 			Name:   "0.2.0",
@@ -246,6 +634,7 @@ func TestReconcile(t *testing.T) {
 		}}
 		tf.bundleClient.EXPECT().GetPackageBundleController(gomock.Any(), "billy").Return(&pbc, nil)
 		tf.bundleClient.EXPECT().GetActiveBundle(gomock.Any(), "billy").Return(newBundle, nil)
+		tf.bundleClient.EXPECT().GetBundleList(gomock.Any()).Return(nil, nil)
 		tf.packageManager.EXPECT().
 			Process(gomock.Any()).
 			Return(false).Do(func(mctx *packages.ManagerContext) {
@@ -276,6 +665,46 @@ func TestReconcile(t *testing.T) {
 	})
 }
 
+func TestMapValuesChangesToPackageUpdate(t *testing.T) {
+	tf, ctx := newTestFixtures(t)
+	referencing := tf.mockPackage()
+	referencing.Spec.ValuesFrom = []api.ValuesReference{{Kind: "Secret", Name: "credentials"}}
+	other := tf.mockPackage()
+	other.Name = "other"
+	other.Spec.ValuesFrom = []api.ValuesReference{{Kind: "ConfigMap", Name: "credentials"}}
+	tf.ctrlClient.EXPECT().
+		List(ctx, gomock.AssignableToTypeOf(&api.PackageList{}), client.InNamespace("eksa-packages-billy"),
+			client.MatchingFields{valuesFromField: "Secret/credentials"}).
+		SetArg(1, api.PackageList{Items: []api.Package{*referencing, *other}})
+	secret := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "eksa-packages-billy"}}
+
+	sut := tf.newReconciler()
+	got := sut.mapValuesChangesToPackageUpdate("Secret")(ctx, secret)
+
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "eksa-packages-billy", Name: "my-package"}}}, got)
+}
+
+func TestIndexValuesFrom(t *testing.T) {
+	tf, _ := newTestFixtures(t)
+	pkg := tf.mockPackage()
+	pkg.Spec.ValuesFrom = []api.ValuesReference{{Kind: "Secret", Name: "credentials"}, {Kind: "ConfigMap", Name: "settings"}}
+
+	assert.Equal(t, []string{"Secret/credentials", "ConfigMap/settings"}, indexValuesFrom(pkg))
+}
+
+func TestIsPackageValues(t *testing.T) {
+	values := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "eksa-packages-billy"}}
+	assert.True(t, isPackageValues(values))
+
+	release := values.DeepCopy()
+	release.Labels = map[string]string{"owner": "helm"}
+	assert.False(t, isPackageValues(release))
+
+	other := values.DeepCopy()
+	other.Namespace = "kube-system"
+	assert.False(t, isPackageValues(other))
+}
+
 //
 // Test helpers
 //
@@ -291,6 +720,7 @@ type testFixtures struct {
 
 	ctrlClient     *ctrlmocks.MockClient
 	packageDriver  *drivermocks.MockPackageDriver
+	manifestDriver *drivermocks.MockPackageDriver
 	packageManager *packageMocks.MockManager
 	bundleManager  *bundleMocks.MockManager
 	bundleClient   *bundleMocks.MockClient
@@ -305,6 +735,7 @@ func newTestFixtures(t *testing.T) (*testFixtures, context.Context) {
 		logger:           logr.Discard(),
 		ctrlClient:       ctrlmocks.NewMockClient(gomockController),
 		packageDriver:    drivermocks.NewMockPackageDriver(gomockController),
+		manifestDriver:   drivermocks.NewMockPackageDriver(gomockController),
 		packageManager:   packageMocks.NewMockManager(gomockController),
 		bundleManager:    bundleMocks.NewMockManager(gomockController),
 		bundleClient:     bundleMocks.NewMockClient(gomockController),
@@ -317,8 +748,9 @@ func (tf *testFixtures) mockPackage() *api.Package {
 			Kind: "Package",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "my-package",
-			Namespace: "eksa-packages-billy",
+			Name:       "my-package",
+			Namespace:  "eksa-packages-billy",
+			Finalizers: []string{api.PackageFinalizer},
 		},
 		Spec: api.PackageSpec{
 			PackageName:    "hello-eks-anywhere",
@@ -371,13 +803,19 @@ func (tf *testFixtures) newReconciler() *PackageReconciler {
 	mockBundleClient := tf.bundleClient
 
 	return &PackageReconciler{
-		Client:        mockCtrlClient,
-		Scheme:        nil,
-		Log:           tf.logger,
-		PackageDriver: mockPackageDriver,
+		Client: mockCtrlClient,
+		Scheme: nil,
+		Log:    tf.logger,
+		Drivers: driver.NewPool(func(_ string, kind api.DriverEnum) (driver.PackageDriver, error) {
+			if kind == api.DriverManifest {
+				return tf.manifestDriver, nil
+			}
+			return mockPackageDriver, nil
+		}),
 		Manager:       mockPackageManager,
 		bundleManager: mockBundleManager,
 		managerClient: mockBundleClient,
+		recorder:      record.NewFakeRecorder(10),
 	}
 }
 
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -76,7 +77,7 @@ func RegisterPackageBundleControllerReconciler(mgr ctrl.Manager) error {
 	tcc := authenticator.NewTargetClusterClient(log, mgr.GetConfig(), mgr.GetClient())
 	puller := artifacts.NewRegistryPuller(log)
 	registryClient := bundle.NewRegistryClient(puller)
-	bm := bundle.NewBundleManager(log, registryClient, bundleClient, tcc, config.GetGlobalConfig())
+	bm := bundle.NewBundleManager(log, registryClient, bundleClient, tcc, config.GetGlobalConfig(), mgr.GetEventRecorderFor(eventSource))
 	ci := registry.NewCertInjector(mgr.GetClient(), log)
 	reconciler := NewPackageBundleControllerReconciler(mgr.GetClient(),
 		mgr.GetScheme(), bm, ci, log)
@@ -121,6 +122,7 @@ func (r *PackageBundleControllerReconciler) Reconcile(ctx context.Context, req c
 	if pbc.IsIgnored() {
 		if pbc.Status.State != api.BundleControllerStateIgnored {
 			pbc.Status.State = api.BundleControllerStateIgnored
+			pbc.SetCondition(api.ReadyCondition, metav1.ConditionFalse, api.ConditionReason(string(pbc.Status.State)), "")
 			r.Log.V(6).Info("update", "PackageBundleController", pbc.Name, "state", pbc.Status.State)
 			err = r.Client.Status().Update(ctx, pbc, &client.SubResourceUpdateOptions{})
 			if err != nil {
@@ -15,6 +15,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -51,7 +52,7 @@ func TestPackageBundleControllerReconcilerReconcile(t *testing.T) {
 	tcc := mocks2.NewMockTargetClusterClient(gomock.NewController(t))
 	rc := bundleMocks.NewMockRegistryClient(gomock.NewController(t))
 	bc := bundleMocks.NewMockClient(gomock.NewController(t))
-	bm := bundle.NewBundleManager(logr.Discard(), rc, bc, tcc, cfg)
+	bm := bundle.NewBundleManager(logr.Discard(), rc, bc, tcc, cfg, record.NewFakeRecorder(10))
 
 	controllerNN := types.NamespacedName{
 		Namespace: api.PackageNamespace,
@@ -6,6 +6,7 @@ import (
 	"github.com/go-logr/logr"
 	"oras.land/oras-go/v2/registry/remote"
 
+	"github.com/aws/eks-anywhere-packages/pkg/metrics"
 	"github.com/aws/eks-anywhere-packages/pkg/registry"
 )
 
@@ -49,5 +50,10 @@ func (p *RegistryPuller) Pull(ctx context.Context, ref, clusterName string) ([]b
 	}
 	client := registry.NewOCIRegistry(sc, remoteRegistry)
 
-	return registry.PullBytes(ctx, client, *art)
+	data, err := registry.PullBytes(ctx, client, *art)
+	if err != nil {
+		return nil, err
+	}
+	metrics.RegistryPulled(art.Registry)
+	return data, nil
 }
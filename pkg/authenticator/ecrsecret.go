@@ -7,6 +7,7 @@ import (
 	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
@@ -111,8 +112,14 @@ func (s *ecrSecret) DelFromConfigMap(ctx context.Context, name, namespace string
 	cm, err := s.clientset.CoreV1().ConfigMaps(s.targetCluster).
 		Get(ctx, ConfigMapName, metav1.GetOptions{})
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
 		return err
 	}
+	if _, ok := cm.Data[namespace]; !ok {
+		return nil
+	}
 
 	css := csset.NewCSSet(cm.Data[namespace])
 	css.Del(name)
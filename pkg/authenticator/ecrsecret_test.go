@@ -191,6 +191,31 @@ func TestDelFromConfigMap(t *testing.T) {
 		_, exists := updatedCM.Data["eksa-packages"]
 		assert.False(t, exists)
 	})
+
+	t.Run("namespace not in the configmap", func(t *testing.T) {
+		mockClientset := fake.NewSimpleClientset(&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ConfigMapName,
+				Namespace: targetClusterNamespace,
+			},
+		})
+		ecrAuth := ecrSecret{clientset: mockClientset}
+		err := ecrAuth.Initialize(clusterName)
+		require.NoError(t, err)
+
+		err = ecrAuth.DelFromConfigMap(ctx, name, namespace)
+		assert.NoError(t, err)
+	})
+
+	t.Run("configmap not found", func(t *testing.T) {
+		mockClientset := fake.NewSimpleClientset()
+		ecrAuth := ecrSecret{clientset: mockClientset}
+		err := ecrAuth.Initialize(clusterName)
+		require.NoError(t, err)
+
+		err = ecrAuth.DelFromConfigMap(ctx, name, namespace)
+		assert.NoError(t, err)
+	})
 }
 
 func TestGetSecretValues(t *testing.T) {
@@ -15,6 +15,7 @@ import (
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	clientcmd "k8s.io/client-go/tools/clientcmd"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // MockTargetClusterClient is a mock of TargetClusterClient interface.
@@ -40,6 +41,20 @@ func (m *MockTargetClusterClient) EXPECT() *MockTargetClusterClientMockRecorder
 	return m.recorder
 }
 
+// ApplyObject mocks base method.
+func (m *MockTargetClusterClient) ApplyObject(ctx context.Context, object client.Object, fieldOwner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyObject", ctx, object, fieldOwner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyObject indicates an expected call of ApplyObject.
+func (mr *MockTargetClusterClientMockRecorder) ApplyObject(ctx, object, fieldOwner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyObject", reflect.TypeOf((*MockTargetClusterClient)(nil).ApplyObject), ctx, object, fieldOwner)
+}
+
 // ApplySecret mocks base method.
 func (m *MockTargetClusterClient) ApplySecret(ctx context.Context, secret *v1.Secret) error {
 	m.ctrl.T.Helper()
@@ -82,6 +97,34 @@ func (mr *MockTargetClusterClientMockRecorder) CreateClusterNamespace(ctx, clust
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClusterNamespace", reflect.TypeOf((*MockTargetClusterClient)(nil).CreateClusterNamespace), ctx, clusterName)
 }
 
+// DeleteObject mocks base method.
+func (m *MockTargetClusterClient) DeleteObject(ctx context.Context, object client.Object) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteObject", ctx, object)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteObject indicates an expected call of DeleteObject.
+func (mr *MockTargetClusterClientMockRecorder) DeleteObject(ctx, object interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObject", reflect.TypeOf((*MockTargetClusterClient)(nil).DeleteObject), ctx, object)
+}
+
+// GetObject mocks base method.
+func (m *MockTargetClusterClient) GetObject(ctx context.Context, key client.ObjectKey, object client.Object) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetObject", ctx, key, object)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetObject indicates an expected call of GetObject.
+func (mr *MockTargetClusterClientMockRecorder) GetObject(ctx, key, object interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObject", reflect.TypeOf((*MockTargetClusterClient)(nil).GetObject), ctx, key, object)
+}
+
 // GetServerVersion mocks base method.
 func (m *MockTargetClusterClient) GetServerVersion(ctx context.Context, clusterName string) (*version.Info, error) {
 	m.ctrl.T.Helper()
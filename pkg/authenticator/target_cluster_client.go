@@ -1,6 +1,7 @@
 package authenticator
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -21,6 +22,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+	"github.com/aws/eks-anywhere-packages/pkg/failure"
+	"github.com/aws/eks-anywhere-packages/pkg/tracing"
 )
 
 const (
@@ -46,6 +49,15 @@ type TargetClusterClient interface {
 	// ApplySecret for the workload cluster
 	ApplySecret(ctx context.Context, secret *corev1.Secret) (err error)
 
+	// GetObject from the target cluster.
+	GetObject(ctx context.Context, key client.ObjectKey, object client.Object) (err error)
+
+	// ApplyObject to the target cluster with server-side apply.
+	ApplyObject(ctx context.Context, object client.Object, fieldOwner string) (err error)
+
+	// DeleteObject from the target cluster.
+	DeleteObject(ctx context.Context, object client.Object) (err error)
+
 	// Implement RESTClientGetter
 	ToRESTConfig() (*rest.Config, error)
 	ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error)
@@ -59,6 +71,12 @@ type targetClusterClient struct {
 	targetSelf   bool
 	clientConfig clientcmd.ClientConfig
 	logger       logr.Logger
+
+	// kubeconfig is the kubeconfig the client was last initialized with.
+	kubeconfig []byte
+	// objectClient reads objects from the target cluster, created on first
+	// use and kept until the kubeconfig changes.
+	objectClient client.Client
 }
 
 var _ TargetClusterClient = (*targetClusterClient)(nil)
@@ -67,13 +85,25 @@ func NewTargetClusterClient(logger logr.Logger, config *rest.Config, client clie
 	return &targetClusterClient{logger: logger, Config: config, Client: client}
 }
 
-func (tcc *targetClusterClient) Initialize(ctx context.Context, clusterName string) error {
+func (tcc *targetClusterClient) Initialize(ctx context.Context, clusterName string) (err error) {
+	ctx, span := tracing.Start(ctx, "Initialize target cluster client", tracing.ClusterKey.String(clusterName))
+	defer func() {
+		err = failure.Wrap(api.FailureReasonClusterUnreachable, err)
+		tracing.End(span, err)
+	}()
 	kubeconfig, err := tcc.getKubeconfig(ctx, clusterName)
 	if err != nil {
 		return err
 	}
 
+	// Creating the object client means discovering the cluster's API, so
+	// it is kept while the kubeconfig is the same.
+	if tcc.clientConfig != nil && tcc.targetSelf == (kubeconfig == nil) && bytes.Equal(tcc.kubeconfig, kubeconfig) {
+		return nil
+	}
 	tcc.targetSelf = false
+	tcc.kubeconfig = kubeconfig
+	tcc.objectClient = nil
 	if kubeconfig == nil {
 		tcc.targetSelf = true
 		tcc.clientConfig = clientcmd.NewDefaultClientConfig(clientcmdapi.Config{}, &clientcmd.ConfigOverrides{})
@@ -148,17 +178,17 @@ func (tcc *targetClusterClient) getKubeconfig(ctx context.Context, clusterName s
 func (tcc *targetClusterClient) GetServerVersion(ctx context.Context, clusterName string) (info *version.Info, err error) {
 	err = tcc.Initialize(ctx, clusterName)
 	if err != nil {
-		return nil, fmt.Errorf("initializing target client: %s", err)
+		return nil, failure.Errorf(api.FailureReasonClusterUnreachable, "initializing target client: %s", err)
 	}
 
 	discoveryClient, err := tcc.ToDiscoveryClient()
 	if err != nil {
-		return nil, fmt.Errorf("creating discoveryClient client: %s", err)
+		return nil, failure.Errorf(api.FailureReasonClusterUnreachable, "creating discoveryClient client: %s", err)
 	}
 
 	info, err = discoveryClient.ServerVersion()
 	if err != nil {
-		return nil, fmt.Errorf("getting server version: %w", err)
+		return nil, failure.Errorf(api.FailureReasonClusterUnreachable, "getting server version: %w", err)
 	}
 	return info, nil
 }
@@ -264,3 +294,58 @@ func (tcc *targetClusterClient) ApplySecret(ctx context.Context, secret *corev1.
 
 	return nil
 }
+
+// GetObject from the target cluster.
+//
+// It must only be called with an initialized target cluster client.
+func (tcc *targetClusterClient) GetObject(ctx context.Context, key client.ObjectKey, object client.Object) error {
+	k8sClient, err := tcc.getObjectClient()
+	if err != nil {
+		return err
+	}
+	return k8sClient.Get(ctx, key, object)
+}
+
+// ApplyObject to the target cluster with server-side apply, taking ownership
+// of any fields managed by others.
+//
+// It must only be called with an initialized target cluster client.
+func (tcc *targetClusterClient) ApplyObject(ctx context.Context, object client.Object, fieldOwner string) error {
+	k8sClient, err := tcc.getObjectClient()
+	if err != nil {
+		return err
+	}
+	return k8sClient.Patch(ctx, object, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership)
+}
+
+// DeleteObject from the target cluster.
+//
+// It must only be called with an initialized target cluster client.
+func (tcc *targetClusterClient) DeleteObject(ctx context.Context, object client.Object) error {
+	k8sClient, err := tcc.getObjectClient()
+	if err != nil {
+		return err
+	}
+	return k8sClient.Delete(ctx, object, client.PropagationPolicy(metav1.DeletePropagationBackground))
+}
+
+// getObjectClient returns the client for the objects of the target cluster,
+// creating it on first use with the current kubeconfig.
+func (tcc *targetClusterClient) getObjectClient() (client.Client, error) {
+	if tcc.clientConfig == nil {
+		return nil, fmt.Errorf("client is not initialized")
+	}
+
+	if tcc.objectClient == nil {
+		restConfig, err := tcc.ToRESTConfig()
+		if err != nil {
+			return nil, failure.Errorf(api.FailureReasonClusterUnreachable, "creating rest config: %w", err)
+		}
+		tcc.objectClient, err = client.New(restConfig, client.Options{})
+		if err != nil {
+			return nil, failure.Errorf(api.FailureReasonClusterUnreachable, "creating k8s client: %w", err)
+		}
+	}
+
+	return tcc.objectClient, nil
+}
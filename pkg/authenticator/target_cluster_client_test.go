@@ -88,6 +88,25 @@ func TestTargetClusterClient_Init(t *testing.T) {
 		assert.EqualError(t, err, "getting kubeconfig for cluster \"billy\": boom")
 	})
 
+	t.Run("keeps the object client while the kubeconfig is the same", func(t *testing.T) {
+		mockClient := mocks.NewMockClient(gomock.NewController(t))
+		sut := NewTargetClusterClient(testr.New(t), nil, mockClient)
+		var kubeconfigSecret corev1.Secret
+		kubeconfigSecret.Data = map[string][]byte{"value": []byte(actualData)}
+		mockClient.EXPECT().Get(ctx, gomock.Any(), gomock.Any()).DoAndReturn(setKubeConfigSecret(&kubeconfigSecret)).Times(3)
+		t.Setenv("CLUSTER_NAME", "franky")
+
+		assert.NoError(t, sut.Initialize(ctx, "billy"))
+		objectClient := mocks.NewMockClient(gomock.NewController(t))
+		sut.objectClient = objectClient
+		assert.NoError(t, sut.Initialize(ctx, "billy"))
+		assert.Equal(t, objectClient, sut.objectClient)
+
+		kubeconfigSecret.Data["value"] = []byte(actualData + "current-context: billy-admin@billy\n")
+		assert.NoError(t, sut.Initialize(ctx, "billy"))
+		assert.Nil(t, sut.objectClient)
+	})
+
 	t.Run("get kubeconfig no cluster", func(t *testing.T) {
 		logger := testr.New(t)
 		mockClient := mocks.NewMockClient(gomock.NewController(t))
@@ -98,3 +117,33 @@ func TestTargetClusterClient_Init(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestTargetClusterClient_GetObject(t *testing.T) {
+	t.Run("fails when not initialized", func(t *testing.T) {
+		sut := NewTargetClusterClient(testr.New(t), nil, nil)
+
+		err := sut.GetObject(context.Background(), client.ObjectKey{Name: "web"}, &corev1.ConfigMap{})
+
+		assert.EqualError(t, err, "client is not initialized")
+	})
+}
+
+func TestTargetClusterClient_ApplyObject(t *testing.T) {
+	t.Run("fails when not initialized", func(t *testing.T) {
+		sut := NewTargetClusterClient(testr.New(t), nil, nil)
+
+		err := sut.ApplyObject(context.Background(), &corev1.ConfigMap{}, "owner")
+
+		assert.EqualError(t, err, "client is not initialized")
+	})
+}
+
+func TestTargetClusterClient_DeleteObject(t *testing.T) {
+	t.Run("fails when not initialized", func(t *testing.T) {
+		sut := NewTargetClusterClient(testr.New(t), nil, nil)
+
+		err := sut.DeleteObject(context.Background(), &corev1.ConfigMap{})
+
+		assert.EqualError(t, err, "client is not initialized")
+	})
+}
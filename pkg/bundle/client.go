@@ -33,6 +33,9 @@ type Client interface {
 	// GetSecret retries the named secret
 	GetSecret(ctx context.Context, name string) (secret *v1.Secret, err error)
 
+	// GetValues retrieves package configuration from a Secret or ConfigMap
+	GetValues(ctx context.Context, namespace string, ref api.ValuesReference) (values string, err error)
+
 	// CreateBundle add a new bundle custom resource
 	CreateBundle(ctx context.Context, bundle *api.PackageBundle) error
 
@@ -42,6 +45,9 @@ type Client interface {
 	// CreatePackage creates a package
 	CreatePackage(ctx context.Context, pkg *api.Package) (err error)
 
+	// DeletePackage deletes a package
+	DeletePackage(ctx context.Context, pkg *api.Package) (err error)
+
 	// GetPackageList retrieves the list of packages resources.
 	GetPackageList(ctx context.Context, namespace string) (packages api.PackageList, err error)
 
@@ -54,11 +60,27 @@ type Client interface {
 
 type managerClient struct {
 	client.Client
+
+	// valuesReader reads the Secrets and ConfigMaps packages take their
+	// configuration from.
+	valuesReader client.Reader
 }
 
 func NewManagerClient(client client.Client) *managerClient {
 	return &(managerClient{
-		Client: client,
+		Client:       client,
+		valuesReader: client,
+	})
+}
+
+// NewManagerClientWithValuesReader returns a client reading package
+// configuration through the given reader, such as the manager's API reader,
+// so that Secrets and ConfigMaps whose metadata alone is watched aren't
+// cached in full.
+func NewManagerClientWithValuesReader(client client.Client, valuesReader client.Reader) *managerClient {
+	return &(managerClient{
+		Client:       client,
+		valuesReader: valuesReader,
 	})
 }
 
@@ -141,6 +163,38 @@ func (bc *managerClient) GetSecret(ctx context.Context, name string) (secret *v1
 	return secret, nil
 }
 
+func (bc *managerClient) GetValues(ctx context.Context, namespace string, ref api.ValuesReference) (string, error) {
+	nn := types.NamespacedName{
+		Namespace: namespace,
+		Name:      ref.Name,
+	}
+	key := ref.GetKey()
+	switch ref.Kind {
+	case "Secret":
+		secret := &v1.Secret{}
+		if err := bc.valuesReader.Get(ctx, nn, secret); err != nil {
+			return "", fmt.Errorf("getting secret %s: %w", nn, err)
+		}
+		if values, ok := secret.Data[key]; ok {
+			return string(values), nil
+		}
+	case "ConfigMap":
+		cm := &v1.ConfigMap{}
+		if err := bc.valuesReader.Get(ctx, nn, cm); err != nil {
+			return "", fmt.Errorf("getting config map %s: %w", nn, err)
+		}
+		if values, ok := cm.Data[key]; ok {
+			return values, nil
+		}
+		if values, ok := cm.BinaryData[key]; ok {
+			return string(values), nil
+		}
+	default:
+		return "", fmt.Errorf("unsupported values kind %q", ref.Kind)
+	}
+	return "", fmt.Errorf("%s %s has no key %s", ref.Kind, nn, key)
+}
+
 func (bc *managerClient) GetBundleList(ctx context.Context) (bundles []api.PackageBundle, err error) {
 	allBundles := &api.PackageBundleList{}
 	err = bc.Client.List(ctx, allBundles, &client.ListOptions{Namespace: api.PackageNamespace})
@@ -215,6 +269,11 @@ func (p *managerClient) CreatePackage(ctx context.Context, pkg *api.Package) (er
 	return p.Client.Create(ctx, pkg)
 }
 
+// DeletePackage deletes the given package resource
+func (p *managerClient) DeletePackage(ctx context.Context, pkg *api.Package) (err error) {
+	return p.Client.Delete(ctx, pkg)
+}
+
 // GetPackageList retrieves all packages present in the given namespace
 func (p *managerClient) GetPackageList(ctx context.Context, namespace string) (packages api.PackageList, err error) {
 	list := api.PackageList{}
@@ -202,6 +202,87 @@ func TestBundleClient_GetBundle(t *testing.T) {
 	})
 }
 
+func TestBundleClient_GetValues(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "values", Namespace: "eksa-packages-billy"}
+
+	t.Run("from a secret", func(t *testing.T) {
+		mockClient := givenMockClient(t)
+		bundleClient := NewManagerClient(mockClient)
+		mockClient.EXPECT().Get(ctx, key, gomock.AssignableToTypeOf(&v1.Secret{})).
+			DoAndReturn(func(_ context.Context, _ types.NamespacedName, obj client.Object, _ ...client.GetOption) error {
+				obj.(*v1.Secret).Data = map[string][]byte{"values.yaml": []byte("password: secret")}
+				return nil
+			})
+
+		values, err := bundleClient.GetValues(ctx, key.Namespace, api.ValuesReference{Kind: "Secret", Name: "values"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "password: secret", values)
+	})
+
+	t.Run("from a config map key", func(t *testing.T) {
+		mockClient := givenMockClient(t)
+		bundleClient := NewManagerClient(mockClient)
+		mockClient.EXPECT().Get(ctx, key, gomock.AssignableToTypeOf(&v1.ConfigMap{})).
+			DoAndReturn(func(_ context.Context, _ types.NamespacedName, obj client.Object, _ ...client.GetOption) error {
+				obj.(*v1.ConfigMap).Data = map[string]string{"config": "replicas: 2"}
+				return nil
+			})
+
+		values, err := bundleClient.GetValues(ctx, key.Namespace, api.ValuesReference{Kind: "ConfigMap", Name: "values", Key: "config"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "replicas: 2", values)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		mockClient := givenMockClient(t)
+		bundleClient := NewManagerClient(mockClient)
+		mockClient.EXPECT().Get(ctx, key, gomock.AssignableToTypeOf(&v1.ConfigMap{})).Return(nil)
+
+		_, err := bundleClient.GetValues(ctx, key.Namespace, api.ValuesReference{Kind: "ConfigMap", Name: "values"})
+
+		assert.EqualError(t, err, "ConfigMap eksa-packages-billy/values has no key values.yaml")
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		mockClient := givenMockClient(t)
+		bundleClient := NewManagerClient(mockClient)
+		mockClient.EXPECT().Get(ctx, key, gomock.AssignableToTypeOf(&v1.Secret{})).Return(fmt.Errorf("boom"))
+
+		_, err := bundleClient.GetValues(ctx, key.Namespace, api.ValuesReference{Kind: "Secret", Name: "values"})
+
+		assert.EqualError(t, err, "getting secret eksa-packages-billy/values: boom")
+	})
+
+	t.Run("through the values reader", func(t *testing.T) {
+		mockClient := givenMockClient(t)
+		mockReader := givenMockClient(t)
+		bundleClient := NewManagerClientWithValuesReader(mockClient, mockReader)
+		mockReader.EXPECT().Get(ctx, key, gomock.AssignableToTypeOf(&v1.Secret{})).
+			DoAndReturn(func(_ context.Context, _ types.NamespacedName, obj client.Object, _ ...client.GetOption) error {
+				obj.(*v1.Secret).Data = map[string][]byte{"values.yaml": []byte("password: secret")}
+				return nil
+			})
+
+		values, err := bundleClient.GetValues(ctx, key.Namespace, api.ValuesReference{Kind: "Secret", Name: "values"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "password: secret", values)
+	})
+
+	t.Run("unsupported kind", func(t *testing.T) {
+		bundleClient := NewManagerClient(givenMockClient(t))
+
+		_, err := bundleClient.GetValues(ctx, key.Namespace, api.ValuesReference{Kind: "Pod", Name: "values"})
+
+		assert.EqualError(t, err, `unsupported values kind "Pod"`)
+	})
+}
+
 func TestBundleClient_GetBundleList(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -0,0 +1,11 @@
+package bundle
+
+// Reasons of the events recorded against bundles and bundle controllers.
+const (
+	EventReasonNewBundle                 = "NewBundle"
+	EventReasonUpgradeAvailable          = "UpgradeAvailable"
+	EventReasonControllerUpgradeRequired = "ControllerUpgradeRequired"
+	EventReasonInvalidBundle             = "InvalidBundle"
+	EventReasonDisconnected              = "Disconnected"
+	EventReasonConnected                 = "Connected"
+)
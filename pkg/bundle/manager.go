@@ -8,10 +8,15 @@ import (
 
 	"github.com/go-logr/logr"
 	"golang.org/x/mod/semver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	"github.com/aws/eks-anywhere-packages/pkg/authenticator"
 	"github.com/aws/eks-anywhere-packages/pkg/config"
+	"github.com/aws/eks-anywhere-packages/pkg/failure"
+	"github.com/aws/eks-anywhere-packages/pkg/metrics"
 )
 
 //go:generate mockgen -source manager.go -destination=mocks/manager.go -package=mocks Manager
@@ -30,58 +35,89 @@ type bundleManager struct {
 	registryClient RegistryClient
 	targetClient   authenticator.TargetClusterClient
 	config         config.Config
+	recorder       record.EventRecorder
 }
 
-func NewBundleManager(log logr.Logger, registryClient RegistryClient, bundleClient Client, targetClient authenticator.TargetClusterClient, config config.Config) *bundleManager {
+func NewBundleManager(log logr.Logger, registryClient RegistryClient, bundleClient Client, targetClient authenticator.TargetClusterClient, config config.Config, recorder record.EventRecorder) *bundleManager {
 	return &bundleManager{
 		log:            log,
 		bundleClient:   bundleClient,
 		registryClient: registryClient,
 		targetClient:   targetClient,
 		config:         config,
+		recorder:       recorder,
 	}
 }
 
 var _ Manager = (*bundleManager)(nil)
 
 func (m bundleManager) ProcessBundle(_ context.Context, newBundle *api.PackageBundle) (bool, error) {
+	state := api.PackageBundleStateAvailable
 	if newBundle.Namespace != api.PackageNamespace {
-		if newBundle.Status.State != api.PackageBundleStateIgnored {
-			newBundle.Spec.DeepCopyInto(&newBundle.Status.Spec)
-			newBundle.Status.State = api.PackageBundleStateIgnored
-			m.log.V(6).Info("update", "bundle", newBundle.Name, "state", newBundle.Status.State)
-			return true, nil
-		}
-		return false, nil
+		state = api.PackageBundleStateIgnored
+	} else if !m.isCompatibleWith(newBundle) {
+		state = api.PackageBundleStateUpgradeRequired
+	} else if !newBundle.IsValidVersion() {
+		state = api.PackageBundleStateInvalid
 	}
 
-	if !m.isCompatibleWith(newBundle) {
-		if newBundle.Status.State != api.PackageBundleStateUpgradeRequired {
-			newBundle.Spec.DeepCopyInto(&newBundle.Status.Spec)
-			newBundle.Status.State = api.PackageBundleStateUpgradeRequired
-			m.log.V(6).Info("update", "bundle", newBundle.Name, "state", newBundle.Status.State)
-			return true, nil
+	changed := false
+	if newBundle.Status.State != state {
+		newBundle.Spec.DeepCopyInto(&newBundle.Status.Spec)
+		newBundle.Status.State = state
+		m.log.V(6).Info("update", "bundle", newBundle.Name, "state", newBundle.Status.State)
+		switch state {
+		case api.PackageBundleStateUpgradeRequired:
+			m.recorder.Eventf(newBundle, corev1.EventTypeWarning, EventReasonControllerUpgradeRequired,
+				"Bundle requires controller version %s, running %s", newBundle.Spec.MinVersion, m.config.BuildInfo.Version)
+		case api.PackageBundleStateInvalid:
+			m.recorder.Event(newBundle, corev1.EventTypeWarning, EventReasonInvalidBundle, "Bundle name is not a valid version")
 		}
-		return false, nil
+		changed = true
+	}
+	if setBundleConditions(newBundle) {
+		changed = true
 	}
+	return changed, nil
+}
 
-	if !newBundle.IsValidVersion() {
-		if newBundle.Status.State != api.PackageBundleStateInvalid {
-			newBundle.Spec.DeepCopyInto(&newBundle.Status.Spec)
-			newBundle.Status.State = api.PackageBundleStateInvalid
-			m.log.V(6).Info("update", "bundle", newBundle.Name, "state", newBundle.Status.State)
-			return true, nil
-		}
-		return false, nil
+// setBundleConditions derives the conditions of a bundle from its state,
+// returning true if any of them changed.
+func setBundleConditions(bundle *api.PackageBundle) bool {
+	reason := api.ConditionReason(string(bundle.Status.State))
+	switch bundle.Status.State {
+	case api.PackageBundleStateAvailable:
+		return bundle.SetCondition(api.ReadyCondition, metav1.ConditionTrue, reason, "")
+	case api.PackageBundleStateUpgradeRequired:
+		return bundle.SetCondition(api.ReadyCondition, metav1.ConditionFalse, reason,
+			"bundle requires controller version "+bundle.Spec.MinVersion)
+	case api.PackageBundleStateInvalid:
+		return bundle.SetCondition(api.ReadyCondition, metav1.ConditionFalse, reason,
+			"bundle name is not a valid version")
 	}
+	return bundle.SetCondition(api.ReadyCondition, metav1.ConditionFalse, reason, "")
+}
 
-	if newBundle.Status.State != api.PackageBundleStateAvailable {
-		newBundle.Spec.DeepCopyInto(&newBundle.Status.Spec)
-		newBundle.Status.State = api.PackageBundleStateAvailable
-		m.log.V(6).Info("update", "bundle", newBundle.Name, "state", newBundle.Status.State)
-		return true, nil
+// setControllerConditions derives the conditions of a bundle controller from
+// its state, returning true if any of them changed.
+func setControllerConditions(pbc *api.PackageBundleController) bool {
+	reason := api.ConditionReason(string(pbc.Status.State))
+	changed := false
+	switch pbc.Status.State {
+	case api.BundleControllerStateActive:
+		changed = pbc.SetCondition(api.ReadyCondition, metav1.ConditionTrue, reason, pbc.Status.Detail)
+		if pbc.SetCondition(api.UpgradeAvailableCondition, metav1.ConditionFalse, "UpToDate", "") {
+			changed = true
+		}
+	case api.BundleControllerStateUpgradeAvailable:
+		changed = pbc.SetCondition(api.ReadyCondition, metav1.ConditionTrue, reason, "")
+		if pbc.SetCondition(api.UpgradeAvailableCondition, metav1.ConditionTrue, reason, pbc.Status.Detail) {
+			changed = true
+		}
+	default:
+		changed = pbc.SetCondition(api.ReadyCondition, metav1.ConditionFalse, reason, pbc.Status.Detail)
 	}
-	return false, nil
+	return changed
 }
 
 func (m *bundleManager) isCompatibleWith(bundle *api.PackageBundle) bool {
@@ -98,14 +134,35 @@ func (m *bundleManager) hasBundleNamed(bundles []api.PackageBundle, bundleName s
 	return false
 }
 
+// bundleCreated returns when the bundle with the given name was created, and
+// whether it exists.
+func (m *bundleManager) bundleCreated(bundles []api.PackageBundle, bundleName string) (time.Time, bool) {
+	for _, b := range bundles {
+		if b.Name == bundleName {
+			return b.CreationTimestamp.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
 func (m *bundleManager) ProcessBundleController(ctx context.Context, pbc *api.PackageBundleController) error {
+	// conditionsChanged tracks condition changes not yet saved.
+	conditionsChanged := false
+	saveStatus := func() error {
+		setControllerConditions(pbc)
+		conditionsChanged = false
+		return m.bundleClient.SaveStatus(ctx, pbc)
+	}
+
 	info, err := m.targetClient.GetServerVersion(ctx, pbc.Name)
 	if err != nil {
 		m.log.Error(err, "Unable to get server version")
 		if pbc.Status.State == api.BundleControllerStateActive || pbc.Status.State == "" {
 			pbc.Status.Detail = err.Error()
+			pbc.Status.Reason = failure.Reason(err, api.FailureReasonClusterUnreachable)
 			pbc.Status.State = api.BundleControllerStateDisconnected
-			err = m.bundleClient.SaveStatus(ctx, pbc)
+			m.recorder.Eventf(pbc, corev1.EventTypeWarning, EventReasonDisconnected, "Unable to get server version: %s", err)
+			err = saveStatus()
 			if err != nil {
 				return fmt.Errorf("updating %s status to %s: %s", pbc.Name, pbc.Status.State, err)
 			}
@@ -126,27 +183,38 @@ func (m *bundleManager) ProcessBundleController(ctx context.Context, pbc *api.Pa
 	latestBundle, err := m.registryClient.LatestBundle(ctx, pbc.GetBundleURI(), info.Major, info.Minor, pbc.Name)
 	if err != nil {
 		m.log.Error(err, "Unable to get latest bundle")
+		metrics.BundleChecked(pbc.Name, metrics.BundleCheckFailed)
+		pbc.SetCondition(api.RegistryReachableCondition, metav1.ConditionFalse, "RegistryUnreachable", err.Error())
 		if pbc.Status.State == api.BundleControllerStateActive || pbc.Status.State == "" {
 			pbc.Status.State = api.BundleControllerStateDisconnected
 			pbc.Status.Detail = err.Error()
-			err = m.bundleClient.SaveStatus(ctx, pbc)
+			pbc.Status.Reason = failure.Reason(err, api.FailureReasonRegistryUnreachable)
+			m.recorder.Eventf(pbc, corev1.EventTypeWarning, EventReasonDisconnected, "Unable to get latest bundle from %s: %s", pbc.GetBundleURI(), err)
+			err = saveStatus()
 			if err != nil {
 				return fmt.Errorf("updating %s status to %s: %s", pbc.Name, pbc.Status.State, err)
 			}
 		}
 		return err
 	}
+	conditionsChanged = pbc.SetCondition(api.RegistryReachableCondition, metav1.ConditionTrue, "RegistryReachable", "")
 
 	allBundles, err := m.bundleClient.GetBundleList(ctx)
 	if err != nil {
 		return fmt.Errorf("getting bundle list: %s", err)
 	}
 
-	if !m.hasBundleNamed(allBundles, latestBundle.Name) {
+	if found, ok := m.bundleCreated(allBundles, latestBundle.Name); ok {
+		metrics.BundleChecked(pbc.Name, metrics.BundleCheckUnchanged)
+		metrics.LatestBundleFound(pbc.Name, found)
+	} else {
 		err = m.bundleClient.CreateBundle(ctx, latestBundle)
 		if err != nil {
 			return err
 		}
+		m.recorder.Eventf(pbc, corev1.EventTypeNormal, EventReasonNewBundle, "Found new bundle %s", latestBundle.Name)
+		metrics.BundleChecked(pbc.Name, metrics.BundleCheckNew)
+		metrics.LatestBundleFound(pbc.Name, time.Now())
 	}
 	latestBundleIsCurrentBundle := latestBundle.Name == pbc.Spec.ActiveBundle
 
@@ -205,8 +273,10 @@ func (m *bundleManager) ProcessBundleController(ctx context.Context, pbc *api.Pa
 		pbc.Status.State = api.BundleControllerStateUpgradeAvailable
 		m.log.V(6).Info("update", "PackageBundleController", pbc.Name, "state", pbc.Status.State)
 		pbc.Status.Detail = latestBundle.Name + " available"
+		pbc.Status.Reason = ""
+		m.recorder.Eventf(pbc, corev1.EventTypeNormal, EventReasonUpgradeAvailable, "Bundle %s available", latestBundle.Name)
 		pbc.Spec.DeepCopyInto(&pbc.Status.Spec)
-		err = m.bundleClient.SaveStatus(ctx, pbc)
+		err = saveStatus()
 		if err != nil {
 			return fmt.Errorf("updating %s status to %s: %s", pbc.Name, pbc.Status.State, err)
 		}
@@ -214,8 +284,9 @@ func (m *bundleManager) ProcessBundleController(ctx context.Context, pbc *api.Pa
 		if !latestBundleIsCurrentBundle {
 			if pbc.Status.Detail != latestBundle.Name+" available" {
 				pbc.Status.Detail = latestBundle.Name + " available"
+				pbc.Status.Reason = ""
 				pbc.Spec.DeepCopyInto(&pbc.Status.Spec)
-				err = m.bundleClient.SaveStatus(ctx, pbc)
+				err = saveStatus()
 				if err != nil {
 					return fmt.Errorf("updating %s detail to %s: %s", pbc.Name, pbc.Status.Detail, err)
 				}
@@ -225,17 +296,20 @@ func (m *bundleManager) ProcessBundleController(ctx context.Context, pbc *api.Pa
 		pbc.Status.State = api.BundleControllerStateActive
 		m.log.V(6).Info("update", "PackageBundleController", pbc.Name, "state", pbc.Status.State)
 		pbc.Status.Detail = ""
+		pbc.Status.Reason = ""
 		pbc.Spec.DeepCopyInto(&pbc.Status.Spec)
-		err = m.bundleClient.SaveStatus(ctx, pbc)
+		err = saveStatus()
 		if err != nil {
 			return fmt.Errorf("updating %s status to %s: %s", pbc.Name, pbc.Status.State, err)
 		}
 	case api.BundleControllerStateDisconnected:
 		pbc.Status.State = api.BundleControllerStateActive
+		m.recorder.Event(pbc, corev1.EventTypeNormal, EventReasonConnected, "Registry reachable again")
 		m.log.V(6).Info("update", "PackageBundleController", pbc.Name, "state", pbc.Status.State)
 		pbc.Status.Detail = ""
+		pbc.Status.Reason = ""
 		pbc.Spec.DeepCopyInto(&pbc.Status.Spec)
-		err = m.bundleClient.SaveStatus(ctx, pbc)
+		err = saveStatus()
 		if err != nil {
 			return fmt.Errorf("updating %s status to %s: %s", pbc.Name, pbc.Status.State, err)
 		}
@@ -244,8 +318,9 @@ func (m *bundleManager) ProcessBundleController(ctx context.Context, pbc *api.Pa
 			pbc.Status.State = api.BundleControllerStateActive
 			m.log.V(6).Info("update", "PackageBundleController", pbc.Name, "state", pbc.Status.State)
 			pbc.Status.Detail = ""
+			pbc.Status.Reason = ""
 			pbc.Spec.DeepCopyInto(&pbc.Status.Spec)
-			err = m.bundleClient.SaveStatus(ctx, pbc)
+			err = saveStatus()
 			if err != nil {
 				return fmt.Errorf("updating %s status to %s: %s", pbc.Name, pbc.Status.State, err)
 			}
@@ -253,10 +328,21 @@ func (m *bundleManager) ProcessBundleController(ctx context.Context, pbc *api.Pa
 			pbc.Spec.ActiveBundle = latestBundle.Name
 			m.log.V(6).Info("update", "PackageBundleController", pbc.Name, "activeBundle", pbc.Spec.ActiveBundle)
 			pbc.Status.Detail = ""
+			pbc.Status.Reason = ""
 			err = m.bundleClient.Save(ctx, pbc)
 			if err != nil {
 				return fmt.Errorf("updating %s activeBundle to %s: %s", pbc.Name, pbc.Spec.ActiveBundle, err)
 			}
+			// The update triggers another reconcile, which sets the conditions.
+			return nil
+		}
+	}
+
+	if setControllerConditions(pbc) || conditionsChanged {
+		m.log.V(6).Info("update", "PackageBundleController", pbc.Name, "conditions", pbc.Status.Conditions)
+		err = m.bundleClient.SaveStatus(ctx, pbc)
+		if err != nil {
+			return fmt.Errorf("updating %s conditions: %s", pbc.Name, err)
 		}
 	}
 
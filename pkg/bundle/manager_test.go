@@ -10,9 +10,11 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	"github.com/aws/eks-anywhere-packages/pkg/authenticator/mocks"
@@ -41,6 +43,24 @@ func GivenBundle(state api.PackageBundleStateEnum) *api.PackageBundle {
 	}
 }
 
+func givenConditions(pbc *api.PackageBundleController) {
+	setControllerConditions(pbc)
+	pbc.SetCondition(api.RegistryReachableCondition, metav1.ConditionTrue, "RegistryReachable", "")
+}
+
+func thenEvents(t *testing.T, bm *bundleManager, expected ...string) {
+	events := bm.recorder.(*record.FakeRecorder).Events
+	for _, e := range expected {
+		select {
+		case actual := <-events:
+			assert.Equal(t, e, actual)
+		default:
+			assert.Fail(t, "missing event", e)
+		}
+	}
+	assert.Empty(t, events)
+}
+
 func givenBundleManager(t *testing.T) (*mocks.MockTargetClusterClient, *bundleMocks.MockRegistryClient, *bundleMocks.MockClient, *bundleManager) {
 	logger := testr.New(t)
 	ctrl := gomock.NewController(t)
@@ -49,7 +69,7 @@ func givenBundleManager(t *testing.T) (*mocks.MockTargetClusterClient, *bundleMo
 	bc := bundleMocks.NewMockClient(ctrl)
 	cfg := config.GetConfig()
 	cfg.BuildInfo.Version = "v2.2.2"
-	bm := NewBundleManager(logger, rc, bc, tcc, cfg)
+	bm := NewBundleManager(logger, rc, bc, tcc, cfg, record.NewFakeRecorder(10))
 	return tcc, rc, bc, bm
 }
 
@@ -72,6 +92,7 @@ func TestProcessBundle(t *testing.T) {
 		_, _, _, bm := givenBundleManager(t)
 		bundle := GivenBundle(api.PackageBundleStateIgnored)
 		bundle.Namespace = "billy"
+		setBundleConditions(bundle)
 
 		update, err := bm.ProcessBundle(ctx, bundle)
 
@@ -107,6 +128,7 @@ func TestProcessBundle(t *testing.T) {
 		_, _, _, bm := givenBundleManager(t)
 		bundle := GivenBundle(api.PackageBundleStateAvailable)
 		bundle.Name = testPreviousBundleName
+		setBundleConditions(bundle)
 
 		update, err := bm.ProcessBundle(ctx, bundle)
 
@@ -115,6 +137,18 @@ func TestProcessBundle(t *testing.T) {
 		assert.Equal(t, api.PackageBundleStateAvailable, bundle.Status.State)
 	})
 
+	t.Run("sets missing conditions", func(t *testing.T) {
+		_, _, _, bm := givenBundleManager(t)
+		bundle := GivenBundle(api.PackageBundleStateAvailable)
+		bundle.Name = testPreviousBundleName
+
+		update, err := bm.ProcessBundle(ctx, bundle)
+
+		assert.True(t, update)
+		assert.Equal(t, nil, err)
+		assert.True(t, meta.IsStatusConditionTrue(bundle.Status.Conditions, api.ReadyCondition))
+	})
+
 	t.Run("newer controller version required", func(t *testing.T) {
 		_, _, _, bm := givenBundleManager(t)
 		bundle := GivenBundle("")
@@ -126,6 +160,8 @@ func TestProcessBundle(t *testing.T) {
 		assert.True(t, update)
 		assert.Equal(t, nil, err)
 		assert.Equal(t, api.PackageBundleStateUpgradeRequired, bundle.Status.State)
+		assert.True(t, meta.IsStatusConditionFalse(bundle.Status.Conditions, api.ReadyCondition))
+		thenEvents(t, bm, "Warning ControllerUpgradeRequired Bundle requires controller version v4.4.4, running v2.2.2")
 
 		// No change results in no update needed
 		update, err = bm.ProcessBundle(ctx, bundle)
@@ -167,6 +203,28 @@ func TestBundleManager_ProcessBundleController(t *testing.T) {
 	}
 
 	t.Run("active to active", func(t *testing.T) {
+		tcc, rc, bc, bm := givenBundleManager(t)
+		pbc := givenPackageBundleController()
+		givenConditions(pbc)
+		latestBundle := givenBundle()
+		tcc.EXPECT().GetServerVersion(ctx, pbc.Name).Return(&info, nil)
+		tcc.EXPECT().Initialize(ctx, gomock.Any()).Return(nil)
+		tcc.EXPECT().ToRESTConfig().Return(&rest.Config{}, nil)
+		tcc.EXPECT().CreateClusterNamespace(ctx, pbc.Name).Return(nil)
+		tcc.EXPECT().ApplySecret(ctx, gomock.Any()).Return(nil)
+
+		rc.EXPECT().LatestBundle(ctx, testBundleRegistry+"/eks-anywhere-packages-bundles", testKubeMajor, testKubeMinor, pbc.Name).Return(latestBundle, nil)
+		bc.EXPECT().GetBundleList(ctx).Return(allBundles, nil)
+		bc.EXPECT().CreateClusterConfigMap(ctx, pbc.Name).Return(nil)
+		bc.EXPECT().GetSecret(ctx, "aws-secret").Return(&corev1.Secret{}, nil)
+
+		err := bm.ProcessBundleController(ctx, pbc)
+
+		assert.NoError(t, err)
+		assert.Equal(t, api.BundleControllerStateActive, pbc.Status.State)
+	})
+
+	t.Run("active to active sets missing conditions", func(t *testing.T) {
 		tcc, rc, bc, bm := givenBundleManager(t)
 		pbc := givenPackageBundleController()
 		latestBundle := givenBundle()
@@ -180,11 +238,15 @@ func TestBundleManager_ProcessBundleController(t *testing.T) {
 		bc.EXPECT().GetBundleList(ctx).Return(allBundles, nil)
 		bc.EXPECT().CreateClusterConfigMap(ctx, pbc.Name).Return(nil)
 		bc.EXPECT().GetSecret(ctx, "aws-secret").Return(&corev1.Secret{}, nil)
+		bc.EXPECT().SaveStatus(ctx, pbc).Return(nil)
 
 		err := bm.ProcessBundleController(ctx, pbc)
 
 		assert.NoError(t, err)
 		assert.Equal(t, api.BundleControllerStateActive, pbc.Status.State)
+		assert.True(t, meta.IsStatusConditionTrue(pbc.Status.Conditions, api.ReadyCondition))
+		assert.True(t, meta.IsStatusConditionTrue(pbc.Status.Conditions, api.RegistryReachableCondition))
+		assert.True(t, meta.IsStatusConditionFalse(pbc.Status.Conditions, api.UpgradeAvailableCondition))
 	})
 
 	t.Run("active missing active bundle", func(t *testing.T) {
@@ -209,6 +271,7 @@ func TestBundleManager_ProcessBundleController(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, api.BundleControllerStateUpgradeAvailable, pbc.Status.State)
+		assert.True(t, meta.IsStatusConditionTrue(pbc.Status.Conditions, api.UpgradeAvailableCondition))
 	})
 
 	t.Run("active missing active bundle download error", func(t *testing.T) {
@@ -287,6 +350,10 @@ func TestBundleManager_ProcessBundleController(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, api.BundleControllerStateDisconnected, pbc.Status.State)
+		assert.Equal(t, api.FailureReasonRegistryUnreachable, pbc.Status.Reason)
+		assert.True(t, meta.IsStatusConditionFalse(pbc.Status.Conditions, api.ReadyCondition))
+		assert.True(t, meta.IsStatusConditionFalse(pbc.Status.Conditions, api.RegistryReachableCondition))
+		thenEvents(t, bm, "Warning Disconnected Unable to get latest bundle from public.ecr.aws/l0g8r8j6/eks-anywhere-packages-bundles: ooops")
 	})
 
 	t.Run("disconnected to disconnected", func(t *testing.T) {
@@ -343,6 +410,9 @@ func TestBundleManager_ProcessBundleController(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, api.BundleControllerStateUpgradeAvailable, pbc.Status.State)
+		thenEvents(t, bm,
+			"Normal NewBundle Found new bundle v1-21-1004",
+			"Normal UpgradeAvailable Bundle v1-21-1004 available")
 	})
 
 	t.Run("active to cm error", func(t *testing.T) {
@@ -407,6 +477,7 @@ func TestBundleManager_ProcessBundleController(t *testing.T) {
 		pbc := givenPackageBundleController()
 		pbc.Status.State = api.BundleControllerStateUpgradeAvailable
 		pbc.Status.Detail = "v1-21-1004 available"
+		givenConditions(pbc)
 		latestBundle := givenBundle()
 		latestBundle.Name = testNextBundleName
 		tcc.EXPECT().GetServerVersion(ctx, pbc.Name).Return(&info, nil)
@@ -505,6 +576,7 @@ func TestBundleManager_ProcessBundleController(t *testing.T) {
 		tcc, rc, bc, bm := givenBundleManager(t)
 		pbc := givenPackageBundleController()
 		pbc.Status.State = api.BundleControllerStateDisconnected
+		pbc.Status.Reason = api.FailureReasonRegistryUnreachable
 		latestBundle := givenBundle()
 		tcc.EXPECT().GetServerVersion(ctx, pbc.Name).Return(&info, nil)
 		tcc.EXPECT().Initialize(ctx, gomock.Any()).Return(nil)
@@ -517,6 +589,7 @@ func TestBundleManager_ProcessBundleController(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, api.BundleControllerStateActive, pbc.Status.State)
+		assert.Empty(t, pbc.Status.Reason)
 	})
 
 	t.Run("disconnected to active error", func(t *testing.T) {
@@ -621,6 +694,7 @@ func TestBundleManager_ProcessBundleController(t *testing.T) {
 		tcc, rc, bc, bm := givenBundleManager(t)
 		pbc := givenPackageBundleController()
 		_ = os.Setenv("CLUSTER_NAME", pbc.Name)
+		givenConditions(pbc)
 		latestBundle := givenBundle()
 		tcc.EXPECT().GetServerVersion(ctx, pbc.Name).Return(&info, nil)
 		tcc.EXPECT().Initialize(ctx, gomock.Any()).Return(nil)
@@ -642,6 +716,7 @@ func TestBundleManager_ProcessBundleController(t *testing.T) {
 		tcc, rc, bc, bm := givenBundleManager(t)
 		pbc := givenPackageBundleController()
 		_ = os.Setenv("CLUSTER_NAME", "other-cluster-name")
+		givenConditions(pbc)
 		latestBundle := givenBundle()
 		tcc.EXPECT().GetServerVersion(ctx, pbc.Name).Return(&info, nil)
 		tcc.EXPECT().Initialize(ctx, gomock.Any()).Return(nil)
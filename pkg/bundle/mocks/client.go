@@ -79,6 +79,20 @@ func (mr *MockClientMockRecorder) CreatePackage(ctx, pkg interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePackage", reflect.TypeOf((*MockClient)(nil).CreatePackage), ctx, pkg)
 }
 
+// DeletePackage mocks base method.
+func (m *MockClient) DeletePackage(ctx context.Context, pkg *v1alpha1.Package) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePackage", ctx, pkg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePackage indicates an expected call of DeletePackage.
+func (mr *MockClientMockRecorder) DeletePackage(ctx, pkg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePackage", reflect.TypeOf((*MockClient)(nil).DeletePackage), ctx, pkg)
+}
+
 // GetActiveBundle mocks base method.
 func (m *MockClient) GetActiveBundle(ctx context.Context, clusterName string) (*v1alpha1.PackageBundle, error) {
 	m.ctrl.T.Helper()
@@ -169,6 +183,21 @@ func (mr *MockClientMockRecorder) GetSecret(ctx, name interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockClient)(nil).GetSecret), ctx, name)
 }
 
+// GetValues mocks base method.
+func (m *MockClient) GetValues(ctx context.Context, namespace string, ref v1alpha1.ValuesReference) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValues", ctx, namespace, ref)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetValues indicates an expected call of GetValues.
+func (mr *MockClientMockRecorder) GetValues(ctx, namespace, ref interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValues", reflect.TypeOf((*MockClient)(nil).GetValues), ctx, namespace, ref)
+}
+
 // Save mocks base method.
 func (m *MockClient) Save(ctx context.Context, object client.Object) error {
 	m.ctrl.T.Helper()
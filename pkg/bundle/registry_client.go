@@ -9,6 +9,7 @@ import (
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	"github.com/aws/eks-anywhere-packages/pkg/artifacts"
+	"github.com/aws/eks-anywhere-packages/pkg/failure"
 )
 
 //go:generate mockgen -source registry_client.go -destination=mocks/registry_client.go -package=mocks RegistryClient
@@ -48,17 +49,17 @@ func (rc *registryClient) LatestBundle(ctx context.Context, baseRef, kubeMajor,
 func (rc *registryClient) DownloadBundle(ctx context.Context, ref, clusterName string) (*api.PackageBundle, error) {
 	data, err := rc.puller.Pull(ctx, ref, clusterName)
 	if err != nil {
-		return nil, fmt.Errorf("pulling package bundle: %s", err)
+		return nil, failure.Errorf(api.FailureReasonRegistryUnreachable, "pulling package bundle: %w", err)
 	}
 
 	if len(bytes.TrimSpace(data)) == 0 {
-		return nil, fmt.Errorf("package bundle artifact is empty")
+		return nil, failure.Errorf(api.FailureReasonInvalidBundle, "package bundle artifact is empty")
 	}
 
 	bundle := &api.PackageBundle{}
 	err = yaml.Unmarshal(data, bundle)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshalling package bundle: %s", err)
+		return nil, failure.Errorf(api.FailureReasonInvalidBundle, "unmarshalling package bundle: %w", err)
 	}
 
 	return bundle, nil
@@ -0,0 +1,113 @@
+package dependency
+
+import (
+	"sort"
+	"strings"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+// Dependent is an installed package that depends on another package.
+type Dependent struct {
+	Package api.Package
+
+	// requires holds the names of every package it depends on, directly or
+	// transitively.
+	requires map[string]bool
+}
+
+// Requires returns true if the dependent depends on the named package.
+func (d Dependent) Requires(pkgName string) bool {
+	return d.requires[strings.ToLower(pkgName)]
+}
+
+// Dependents returns the installed packages which depend, directly or
+// transitively, on the target package. They are ordered so that each comes
+// before the packages it depends on, which is the order to uninstall them in.
+//
+// There are no dependents while another package provides the same bundle
+// package as the target.
+func (r *Resolver) Dependents(target *api.Package, packages []api.Package) []Dependent {
+	var dependents []Dependent
+	for _, pkg := range packages {
+		if pkg.Name == target.Name {
+			continue
+		}
+		if strings.EqualFold(pkg.Spec.PackageName, target.Spec.PackageName) && pkg.DeletionTimestamp.IsZero() {
+			return nil
+		}
+		requires := r.requires(&pkg)
+		if requires[strings.ToLower(target.Spec.PackageName)] {
+			dependents = append(dependents, Dependent{Package: pkg, requires: requires})
+		}
+	}
+
+	// A package requires everything its dependencies require and more, so
+	// ordering by the number of requirements puts dependents first.
+	sort.SliceStable(dependents, func(i, j int) bool {
+		return len(dependents[i].requires) > len(dependents[j].requires)
+	})
+	return dependents
+}
+
+// requires returns the names of the packages the installed version of the
+// package depends on. A version which is no longer in the bundle is resolved
+// in the bundle it was installed from.
+func (r *Resolver) requires(pkg *api.Package) map[string]bool {
+	if pkg.Status.CurrentVersion == "" {
+		return nil
+	}
+	resolved, err := r.resolveInstalled(pkg)
+	if err != nil {
+		bundle := r.findInstalledBundle(pkg)
+		if bundle == nil {
+			return nil
+		}
+		if resolved, err = NewResolver(bundle).resolveInstalled(pkg); err != nil {
+			return nil
+		}
+	}
+	requires := map[string]bool{}
+	for _, dep := range resolved {
+		requires[strings.ToLower(dep.Package.Name)] = true
+	}
+	return requires
+}
+
+// resolveInstalled resolves the dependencies of the installed version of the
+// package.
+func (r *Resolver) resolveInstalled(pkg *api.Package) ([]Resolved, error) {
+	bundlePackage, err := r.bundle.FindPackage(pkg.Spec.PackageName)
+	if err != nil {
+		return nil, err
+	}
+	version, err := r.bundle.FindVersion(bundlePackage, pkg.Status.CurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	return r.Resolve(bundlePackage.Name, version)
+}
+
+// findInstalledBundle returns the bundle the installed version of the package
+// was taken from, according to its history, or nil if it isn't known.
+func (r *Resolver) findInstalledBundle(pkg *api.Package) *api.PackageBundle {
+	if r.installedBundle == nil {
+		return nil
+	}
+	history := pkg.Status.History
+	for i := len(history) - 1; i >= 0; i-- {
+		revision := history[i]
+		if revision.Result == api.RevisionResultFailed || revision.Version != pkg.Status.CurrentVersion {
+			continue
+		}
+		if revision.Bundle == "" || revision.Bundle == r.bundle.Name {
+			return nil
+		}
+		bundle, err := r.installedBundle(revision.Bundle)
+		if err != nil {
+			return nil
+		}
+		return bundle
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package dependency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+func givenInstalledPackage(packageName, name, version string) api.Package {
+	pkg := api.NewPackage(packageName, name, "eksa-packages-billy", "")
+	pkg.Status.CurrentVersion = version
+	return pkg
+}
+
+func dependentNames(dependents []Dependent) []string {
+	names := []string{}
+	for _, dependent := range dependents {
+		names = append(names, dependent.Package.Name)
+	}
+	return names
+}
+
+func TestResolver_Dependents(t *testing.T) {
+	bundle := givenBundle(
+		givenPackage("app", givenVersion("1.0.0", "web")),
+		givenPackage("web", givenVersion("2.0.0", "cert-manager")),
+		givenPackage("db", givenVersion("3.0.0")),
+		givenPackage("cert-manager", givenVersion("1.13.0")),
+	)
+	certManager := givenInstalledPackage("cert-manager", "my-cert-manager", "1.13.0")
+
+	t.Run("orders dependents before their dependencies", func(t *testing.T) {
+		pkgs := []api.Package{
+			givenInstalledPackage("web", "my-web", "2.0.0"),
+			certManager,
+			givenInstalledPackage("db", "my-db", "3.0.0"),
+			givenInstalledPackage("app", "my-app", "1.0.0"),
+		}
+
+		dependents := NewResolver(bundle).Dependents(&certManager, pkgs)
+
+		assert.Equal(t, []string{"my-app", "my-web"}, dependentNames(dependents))
+		assert.True(t, dependents[0].Requires("web"))
+		assert.True(t, dependents[0].Requires("Cert-Manager"))
+		assert.False(t, dependents[1].Requires("app"))
+	})
+
+	t.Run("ignores packages which aren't installed", func(t *testing.T) {
+		pkgs := []api.Package{
+			givenInstalledPackage("web", "my-web", ""),
+			certManager,
+		}
+
+		assert.Empty(t, NewResolver(bundle).Dependents(&certManager, pkgs))
+	})
+
+	t.Run("none while another package provides the dependency", func(t *testing.T) {
+		pkgs := []api.Package{
+			givenInstalledPackage("web", "my-web", "2.0.0"),
+			certManager,
+			givenInstalledPackage("cert-manager", "other-cert-manager", "1.13.0"),
+		}
+
+		assert.Empty(t, NewResolver(bundle).Dependents(&certManager, pkgs))
+	})
+
+	t.Run("unless the other package is being deleted", func(t *testing.T) {
+		other := givenInstalledPackage("cert-manager", "other-cert-manager", "1.13.0")
+		now := metav1.Now()
+		other.DeletionTimestamp = &now
+		pkgs := []api.Package{
+			givenInstalledPackage("web", "my-web", "2.0.0"),
+			certManager,
+			other,
+		}
+
+		assert.Equal(t, []string{"my-web"}, dependentNames(NewResolver(bundle).Dependents(&certManager, pkgs)))
+	})
+
+	t.Run("resolves versions dropped from the bundle in the bundle they were installed from", func(t *testing.T) {
+		older := givenBundle(givenPackage("web", givenVersion("1.0.0", "cert-manager")), givenPackage("cert-manager", givenVersion("1.12.0")))
+		older.Name = "v1-21-1000"
+		web := givenInstalledPackage("web", "my-web", "1.0.0")
+		web.Status.History = []api.PackageRevision{
+			{Revision: 1, Version: "1.0.0", Bundle: older.Name, Result: api.RevisionResultSucceeded},
+		}
+		pkgs := []api.Package{web, certManager}
+		lookup := func(name string) (*api.PackageBundle, error) {
+			assert.Equal(t, older.Name, name)
+			return older, nil
+		}
+
+		assert.Empty(t, NewResolver(bundle).Dependents(&certManager, pkgs))
+		assert.Equal(t, []string{"my-web"}, dependentNames(NewResolver(bundle).WithInstalledBundles(lookup).Dependents(&certManager, pkgs)))
+	})
+}
@@ -0,0 +1,75 @@
+package dependency
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+var (
+	requirementRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(.*)$`)
+	versionRegex     = regexp.MustCompile(`^v?(\d+(?:\.\d+){0,2})`)
+)
+
+// Requirement is a dependency of a package version on another package, such
+// as "cert-manager" or "cert-manager>=1.12".
+type Requirement struct {
+	// Name of the package depended upon.
+	Name string
+
+	// Constraints on the version of the package, nil if any version will do.
+	Constraints *semver.Constraints
+
+	// constraint is the constraint as written, for messages.
+	constraint string
+}
+
+// ParseRequirement parses a dependency of the form "name" or
+// "name<constraints>", where the constraints use semver syntax, e.g.
+// "cert-manager>=1.12, <2".
+func ParseRequirement(dependency string) (Requirement, error) {
+	matches := requirementRegex.FindStringSubmatch(strings.TrimSpace(dependency))
+	if matches == nil {
+		return Requirement{}, fmt.Errorf("invalid dependency %q", dependency)
+	}
+	requirement := Requirement{Name: matches[1]}
+	if matches[2] == "" {
+		return requirement, nil
+	}
+	constraints, err := semver.NewConstraint(matches[2])
+	if err != nil {
+		return Requirement{}, fmt.Errorf("invalid dependency %q: %w", dependency, err)
+	}
+	requirement.Constraints = constraints
+	requirement.constraint = matches[2]
+	return requirement, nil
+}
+
+// Allows returns true if the version satisfies the requirement.
+func (r Requirement) Allows(version string) bool {
+	if r.Constraints == nil {
+		return true
+	}
+	v, err := ParseVersion(version)
+	if err != nil {
+		return false
+	}
+	return r.Constraints.Check(v)
+}
+
+func (r Requirement) String() string {
+	return r.Name + r.constraint
+}
+
+// ParseVersion parses the semantic version at the start of a package version
+// name. Bundle version names carry build suffixes which are not semver
+// pre-releases, so "v1.12.3-abc123-helm" parses as 1.12.3.
+func ParseVersion(name string) (*semver.Version, error) {
+	matches := versionRegex.FindStringSubmatch(name)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid version %q", name)
+	}
+	return semver.NewVersion(matches[1])
+}
@@ -0,0 +1,72 @@
+package dependency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequirement(t *testing.T) {
+	t.Run("name only", func(t *testing.T) {
+		requirement, err := ParseRequirement("cert-manager")
+		require.NoError(t, err)
+
+		assert.Equal(t, "cert-manager", requirement.Name)
+		assert.Nil(t, requirement.Constraints)
+		assert.Equal(t, "cert-manager", requirement.String())
+		assert.True(t, requirement.Allows("anything"))
+	})
+
+	t.Run("with constraints", func(t *testing.T) {
+		requirement, err := ParseRequirement("cert-manager>=1.12, <2")
+		require.NoError(t, err)
+
+		assert.Equal(t, "cert-manager", requirement.Name)
+		assert.Equal(t, "cert-manager>=1.12, <2", requirement.String())
+		assert.True(t, requirement.Allows("v1.12.0-abc123-helm"))
+		assert.True(t, requirement.Allows("1.13.2"))
+		assert.False(t, requirement.Allows("v1.11.4"))
+		assert.False(t, requirement.Allows("2.0.0"))
+		assert.False(t, requirement.Allows("latest"))
+	})
+
+	t.Run("with space separated constraint", func(t *testing.T) {
+		requirement, err := ParseRequirement("harbor ~2.7")
+		require.NoError(t, err)
+
+		assert.Equal(t, "harbor", requirement.Name)
+		assert.True(t, requirement.Allows("2.7.1"))
+		assert.False(t, requirement.Allows("2.8.0"))
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := ParseRequirement(">=1.12")
+
+		assert.EqualError(t, err, `invalid dependency ">=1.12"`)
+	})
+
+	t.Run("invalid constraint", func(t *testing.T) {
+		_, err := ParseRequirement("cert-manager>=bogus")
+
+		assert.ErrorContains(t, err, `invalid dependency "cert-manager>=bogus"`)
+	})
+}
+
+func TestParseVersion(t *testing.T) {
+	for name, expected := range map[string]string{
+		"1.12.3":                   "1.12.3",
+		"v1.12.3":                  "1.12.3",
+		"v0.25.1-7fbc5c3d-helm":    "0.25.1",
+		"0.36.0-5d0771c9d3d9f8e2b": "0.36.0",
+		"1.2":                      "1.2.0",
+		"v3":                       "3.0.0",
+	} {
+		version, err := ParseVersion(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, version.String(), name)
+	}
+
+	_, err := ParseVersion("test-dep-name")
+	assert.EqualError(t, err, `invalid version "test-dep-name"`)
+}
@@ -0,0 +1,132 @@
+package dependency
+
+import (
+	"fmt"
+	"strings"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+// Resolved is a package version selected to satisfy a dependency.
+type Resolved struct {
+	Package api.BundlePackage
+	Version api.SourceVersion
+
+	// Requirement is the dependency as declared. Its constraints are nil
+	// unless a version was asked for.
+	Requirement Requirement
+}
+
+// Resolver resolves the dependencies of packages in a bundle.
+type Resolver struct {
+	bundle *api.PackageBundle
+
+	// installedBundle looks up the bundle an installed version was taken
+	// from, for versions which are no longer in the bundle.
+	installedBundle func(name string) (*api.PackageBundle, error)
+}
+
+func NewResolver(bundle *api.PackageBundle) *Resolver {
+	return &Resolver{bundle: bundle}
+}
+
+// WithInstalledBundles has the resolver look up the bundle an installed
+// version was taken from when it is no longer in the resolver's bundle.
+func (r *Resolver) WithInstalledBundles(lookup func(name string) (*api.PackageBundle, error)) *Resolver {
+	r.installedBundle = lookup
+	return r
+}
+
+// resolution tracks the state of a single resolution.
+type resolution struct {
+	// path is the chain of packages being resolved, used to report cycles.
+	path     []string
+	resolved map[string]*Resolved
+	ordered  []*Resolved
+}
+
+// Resolve returns the dependencies of a package version, direct and
+// transitive, in the order they must be installed.
+func (r *Resolver) Resolve(pkgName string, version api.SourceVersion) ([]Resolved, error) {
+	res := &resolution{resolved: map[string]*Resolved{}}
+	if err := r.visit(res, pkgName, version); err != nil {
+		return nil, err
+	}
+	ordered := make([]Resolved, len(res.ordered))
+	for i, resolved := range res.ordered {
+		ordered[i] = *resolved
+	}
+	return ordered, nil
+}
+
+// Validate resolves the dependencies of every package version in the bundle,
+// reporting cycles, missing packages and unsatisfiable constraints.
+func (r *Resolver) Validate() error {
+	for _, pkg := range r.bundle.Spec.Packages {
+		for _, version := range pkg.Source.Versions {
+			if _, err := r.Resolve(pkg.Name, version); err != nil {
+				return fmt.Errorf("%s@%s: %w", pkg.Name, version.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) visit(res *resolution, pkgName string, version api.SourceVersion) error {
+	res.path = append(res.path, pkgName)
+	defer func() { res.path = res.path[:len(res.path)-1] }()
+
+	for _, dependency := range version.Dependencies {
+		requirement, err := ParseRequirement(dependency)
+		if err != nil {
+			return err
+		}
+		if cycle := res.cycle(requirement.Name); cycle != nil {
+			return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+		if resolved, ok := res.resolved[requirement.Name]; ok {
+			if !requirement.Allows(resolved.Version.Name) {
+				return fmt.Errorf("%s requires %s, which conflicts with %s", pkgName, requirement, resolved.Requirement)
+			}
+			continue
+		}
+
+		pkg, err := r.bundle.FindPackage(requirement.Name)
+		if err != nil {
+			return err
+		}
+		selected, err := selectVersion(pkg, requirement)
+		if err != nil {
+			return err
+		}
+		if err := r.visit(res, pkg.Name, selected); err != nil {
+			return err
+		}
+		resolved := &Resolved{Package: pkg, Version: selected, Requirement: requirement}
+		res.resolved[requirement.Name] = resolved
+		res.ordered = append(res.ordered, resolved)
+	}
+	return nil
+}
+
+// cycle returns the dependency cycle closed by depending on pkgName, if any.
+func (res *resolution) cycle(pkgName string) []string {
+	for i, name := range res.path {
+		if strings.EqualFold(name, pkgName) {
+			cycle := append([]string{}, res.path[i:]...)
+			return append(cycle, pkgName)
+		}
+	}
+	return nil
+}
+
+// selectVersion returns the first version of the package, in bundle order,
+// that satisfies the requirement. The bundle lists the latest version first.
+func selectVersion(pkg api.BundlePackage, requirement Requirement) (api.SourceVersion, error) {
+	for _, version := range pkg.Source.Versions {
+		if requirement.Allows(version.Name) {
+			return version, nil
+		}
+	}
+	return api.SourceVersion{}, fmt.Errorf("no version of %s satisfies %s", pkg.Name, requirement)
+}
@@ -0,0 +1,141 @@
+package dependency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+func givenBundle(packages ...api.BundlePackage) *api.PackageBundle {
+	bundle := &api.PackageBundle{Spec: api.PackageBundleSpec{Packages: packages}}
+	bundle.Name = "v1-21-1001"
+	return bundle
+}
+
+func givenPackage(name string, versions ...api.SourceVersion) api.BundlePackage {
+	return api.BundlePackage{Name: name, Source: api.BundlePackageSource{Versions: versions}}
+}
+
+func givenVersion(name string, dependencies ...string) api.SourceVersion {
+	return api.SourceVersion{Name: name, Dependencies: dependencies}
+}
+
+func resolvedNames(resolved []Resolved) []string {
+	names := []string{}
+	for _, r := range resolved {
+		names = append(names, r.Package.Name+"@"+r.Version.Name)
+	}
+	return names
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	t.Run("no dependencies", func(t *testing.T) {
+		bundle := givenBundle(givenPackage("a", givenVersion("1.0.0")))
+
+		resolved, err := NewResolver(bundle).Resolve("a", bundle.Spec.Packages[0].Source.Versions[0])
+
+		require.NoError(t, err)
+		assert.Empty(t, resolved)
+	})
+
+	t.Run("orders transitive dependencies first", func(t *testing.T) {
+		bundle := givenBundle(
+			givenPackage("app", givenVersion("1.0.0", "web", "db")),
+			givenPackage("web", givenVersion("2.0.0", "cert-manager")),
+			givenPackage("db", givenVersion("3.0.0", "cert-manager")),
+			givenPackage("cert-manager", givenVersion("1.13.0"), givenVersion("1.11.0")),
+		)
+
+		resolved, err := NewResolver(bundle).Resolve("app", bundle.Spec.Packages[0].Source.Versions[0])
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"cert-manager@1.13.0", "web@2.0.0", "db@3.0.0"}, resolvedNames(resolved))
+	})
+
+	t.Run("selects the latest version satisfying the constraint", func(t *testing.T) {
+		bundle := givenBundle(
+			givenPackage("app", givenVersion("1.0.0", "cert-manager<1.13")),
+			givenPackage("cert-manager", givenVersion("v1.13.0-abc"), givenVersion("v1.12.1-def"), givenVersion("v1.11.0-123")),
+		)
+
+		resolved, err := NewResolver(bundle).Resolve("app", bundle.Spec.Packages[0].Source.Versions[0])
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"cert-manager@v1.12.1-def"}, resolvedNames(resolved))
+		assert.Equal(t, "cert-manager<1.13", resolved[0].Requirement.String())
+	})
+
+	t.Run("no version satisfies the constraint", func(t *testing.T) {
+		bundle := givenBundle(
+			givenPackage("app", givenVersion("1.0.0", "cert-manager>=2")),
+			givenPackage("cert-manager", givenVersion("1.13.0")),
+		)
+
+		_, err := NewResolver(bundle).Resolve("app", bundle.Spec.Packages[0].Source.Versions[0])
+
+		assert.EqualError(t, err, "no version of cert-manager satisfies cert-manager>=2")
+	})
+
+	t.Run("conflicting constraints", func(t *testing.T) {
+		bundle := givenBundle(
+			givenPackage("app", givenVersion("1.0.0", "web", "cert-manager<1.12")),
+			givenPackage("web", givenVersion("2.0.0", "cert-manager>=1.12")),
+			givenPackage("cert-manager", givenVersion("1.13.0"), givenVersion("1.11.0")),
+		)
+
+		_, err := NewResolver(bundle).Resolve("app", bundle.Spec.Packages[0].Source.Versions[0])
+
+		assert.EqualError(t, err, "app requires cert-manager<1.12, which conflicts with cert-manager>=1.12")
+	})
+
+	t.Run("missing package", func(t *testing.T) {
+		bundle := givenBundle(givenPackage("app", givenVersion("1.0.0", "missing")))
+
+		_, err := NewResolver(bundle).Resolve("app", bundle.Spec.Packages[0].Source.Versions[0])
+
+		assert.EqualError(t, err, "package not found in bundle (v1-21-1001): missing")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		bundle := givenBundle(
+			givenPackage("a", givenVersion("1.0.0", "b")),
+			givenPackage("b", givenVersion("1.0.0", "c")),
+			givenPackage("c", givenVersion("1.0.0", "b")),
+		)
+
+		_, err := NewResolver(bundle).Resolve("a", bundle.Spec.Packages[0].Source.Versions[0])
+
+		assert.EqualError(t, err, "dependency cycle: b -> c -> b")
+	})
+
+	t.Run("self dependency", func(t *testing.T) {
+		bundle := givenBundle(givenPackage("a", givenVersion("1.0.0", "a")))
+
+		_, err := NewResolver(bundle).Resolve("a", bundle.Spec.Packages[0].Source.Versions[0])
+
+		assert.EqualError(t, err, "dependency cycle: a -> a")
+	})
+}
+
+func TestResolver_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		bundle := givenBundle(
+			givenPackage("app", givenVersion("1.0.0", "cert-manager>=1.12"), givenVersion("0.9.0", "cert-manager")),
+			givenPackage("cert-manager", givenVersion("1.13.0")),
+		)
+
+		assert.NoError(t, NewResolver(bundle).Validate())
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		bundle := givenBundle(
+			givenPackage("app", givenVersion("1.0.0"), givenVersion("0.9.0", "cert-manager>=1.14")),
+			givenPackage("cert-manager", givenVersion("1.13.0")),
+		)
+
+		assert.EqualError(t, NewResolver(bundle).Validate(), "app@0.9.0: no version of cert-manager satisfies cert-manager>=1.14")
+	})
+}
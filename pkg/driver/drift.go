@@ -0,0 +1,191 @@
+package driver
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	auth "github.com/aws/eks-anywhere-packages/pkg/authenticator"
+)
+
+// drifted returns a description of each resource in the manifest whose live
+// state on the target cluster no longer matches it.
+func drifted(ctx context.Context, tcc auth.TargetClusterClient, manifest, namespace string) ([]string, error) {
+	objects, err := parseManifest(manifest, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var drifted []string
+	for _, obj := range objects {
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := tcc.GetObject(ctx, obj.key, live); err != nil {
+			if apierrors.IsNotFound(err) {
+				drifted = append(drifted, fmt.Sprintf("%s: deleted", obj))
+				continue
+			}
+			return nil, fmt.Errorf("getting %s: %w", obj, err)
+		}
+		if path := diffObject(obj.Object, live.Object); path != "" {
+			drifted = append(drifted, fmt.Sprintf("%s: %s changed", obj, path))
+		}
+	}
+	return drifted, nil
+}
+
+// diffObject returns the path of the first field set by the desired object
+// which differs in the live one, or nothing if there are none. Fields the
+// manifest doesn't set, such as defaults and status, are ignored, as is
+// metadata other than labels and annotations.
+func diffObject(desired, live map[string]interface{}) string {
+	desired = stored(desired)
+	for _, key := range sortedKeys(desired) {
+		value := desired[key]
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			metadata, _ := value.(map[string]interface{})
+			liveMetadata, _ := live[key].(map[string]interface{})
+			for _, field := range []string{"labels", "annotations"} {
+				if _, ok := metadata[field]; !ok {
+					continue
+				}
+				if path := diff(key+"."+field, metadata[field], liveMetadata[field]); path != "" {
+					return path
+				}
+			}
+		default:
+			if path := diff(key, value, live[key]); path != "" {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// stored returns the desired object as the API server stores it. Write-only
+// fields never come back from the server, so a Secret's stringData is folded
+// into its data the way the server does.
+func stored(desired map[string]interface{}) map[string]interface{} {
+	stringData, ok := desired["stringData"].(map[string]interface{})
+	if desired["apiVersion"] != "v1" || desired["kind"] != "Secret" || !ok {
+		return desired
+	}
+	object := make(map[string]interface{}, len(desired))
+	for key, value := range desired {
+		object[key] = value
+	}
+	delete(object, "stringData")
+	data := map[string]interface{}{}
+	if existing, ok := desired["data"].(map[string]interface{}); ok {
+		for key, value := range existing {
+			data[key] = value
+		}
+	}
+	for key, value := range stringData {
+		if s, ok := value.(string); ok {
+			data[key] = base64.StdEncoding.EncodeToString([]byte(s))
+		}
+	}
+	object["data"] = data
+	return object
+}
+
+// diff returns the path of the first field in desired which differs in live.
+func diff(path string, desired, live interface{}) string {
+	switch desired := desired.(type) {
+	case map[string]interface{}:
+		live, ok := live.(map[string]interface{})
+		if !ok {
+			// The server drops empty maps.
+			if len(desired) == 0 && live == nil {
+				return ""
+			}
+			return path
+		}
+		for _, key := range sortedKeys(desired) {
+			if p := diff(path+"."+key, desired[key], live[key]); p != "" {
+				return p
+			}
+		}
+		return ""
+	case []interface{}:
+		live, ok := live.([]interface{})
+		if !ok && len(desired) == 0 && live == nil {
+			// The server drops empty lists.
+			return ""
+		}
+		if !ok || len(live) != len(desired) {
+			return path
+		}
+		for i := range desired {
+			if p := diff(fmt.Sprintf("%s[%d]", path, i), desired[i], live[i]); p != "" {
+				return p
+			}
+		}
+		return ""
+	case nil:
+		// A null value in the manifest leaves the field unset.
+		return ""
+	}
+	if equalScalars(desired, live) {
+		return ""
+	}
+	return path
+}
+
+// equalScalars compares scalar values the way the API server normalizes
+// them, so numbers compare by value and quantities such as "1000m" and "1"
+// are equal.
+func equalScalars(desired, live interface{}) bool {
+	if reflect.DeepEqual(desired, live) {
+		return true
+	}
+	if d, ok := toFloat(desired); ok {
+		l, ok := toFloat(live)
+		return ok && d == l
+	}
+	if d, ok := desired.(string); ok {
+		l, ok := live.(string)
+		if !ok {
+			return false
+		}
+		dq, err := resource.ParseQuantity(d)
+		if err != nil {
+			return false
+		}
+		lq, err := resource.ParseQuantity(l)
+		return err == nil && dq.Cmp(lq) == 0
+	}
+	return false
+}
+
+// sortedKeys returns the keys of the map in order, so the same drift is
+// always reported the same way.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
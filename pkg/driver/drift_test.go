@@ -0,0 +1,215 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aws/eks-anywhere-packages/pkg/authenticator/mocks"
+)
+
+func TestDiffObject(t *testing.T) {
+	desired := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":   "web",
+			"labels": map[string]interface{}{"app": "web"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": float64(2),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":      "web",
+							"image":     "web:1.0",
+							"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1000m"}},
+							"args":      nil,
+						},
+					},
+				},
+			},
+		},
+	}
+	givenLive := func() map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":            "web",
+				"namespace":       "ns",
+				"resourceVersion": "42",
+				"labels":          map[string]interface{}{"app": "web", "extra": "yes"},
+			},
+			"spec": map[string]interface{}{
+				"replicas":             int64(2),
+				"revisionHistoryLimit": int64(10),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":            "web",
+								"image":           "web:1.0",
+								"imagePullPolicy": "IfNotPresent",
+								"resources":       map[string]interface{}{"limits": map[string]interface{}{"cpu": "1"}},
+							},
+						},
+					},
+				},
+			},
+			"status": map[string]interface{}{"replicas": int64(2)},
+		}
+	}
+
+	t.Run("ignores defaults, status and normalized values", func(t *testing.T) {
+		assert.Equal(t, "", diffObject(desired, givenLive()))
+	})
+
+	t.Run("detects changed fields", func(t *testing.T) {
+		live := givenLive()
+		live["spec"].(map[string]interface{})["replicas"] = int64(1)
+
+		assert.Equal(t, "spec.replicas", diffObject(desired, live))
+	})
+
+	t.Run("detects changed list elements", func(t *testing.T) {
+		live := givenLive()
+		containers := live["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+		containers[0].(map[string]interface{})["image"] = "web:2.0"
+
+		assert.Equal(t, "spec.template.spec.containers[0].image", diffObject(desired, live))
+	})
+
+	t.Run("detects removed labels", func(t *testing.T) {
+		live := givenLive()
+		delete(live["metadata"].(map[string]interface{})["labels"].(map[string]interface{}), "app")
+
+		assert.Equal(t, "metadata.labels.app", diffObject(desired, live))
+	})
+
+	t.Run("ignores empty maps and lists the server drops", func(t *testing.T) {
+		desired := map[string]interface{}{
+			"spec": map[string]interface{}{"selector": map[string]interface{}{}, "ports": []interface{}{}},
+		}
+
+		assert.Equal(t, "", diffObject(desired, map[string]interface{}{"spec": map[string]interface{}{}}))
+	})
+}
+
+func TestDiffSecret(t *testing.T) {
+	desired := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "credentials"},
+		"data":       map[string]interface{}{"username": "YWRtaW4="},
+		"stringData": map[string]interface{}{"password": "hunter2"},
+	}
+	givenLive := func() map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "credentials", "namespace": "ns"},
+			"data":       map[string]interface{}{"username": "YWRtaW4=", "password": "aHVudGVyMg=="},
+			"type":       "Opaque",
+		}
+	}
+
+	t.Run("compares stringData with the data it is stored as", func(t *testing.T) {
+		assert.Equal(t, "", diffObject(desired, givenLive()))
+		assert.Contains(t, desired, "stringData")
+	})
+
+	t.Run("detects changed stringData", func(t *testing.T) {
+		live := givenLive()
+		live["data"].(map[string]interface{})["password"] = "b29wcw=="
+
+		assert.Equal(t, "data.password", diffObject(desired, live))
+	})
+}
+
+func TestDrift(t *testing.T) {
+	manifest := `---
+# Source: test/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+# Source: test/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+# Source: test/templates/role.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: web
+`
+	live := map[client.ObjectKey]map[string]interface{}{
+		{Namespace: "ns", Name: "config"}: {"data": map[string]interface{}{"key": "edited"}},
+		{Namespace: "ns", Name: "web"}:    {"spec": map[string]interface{}{"ports": []interface{}{map[string]interface{}{"port": int64(80), "protocol": "TCP"}}}},
+	}
+
+	t.Run("describes drifted resources", func(t *testing.T) {
+		t.Parallel()
+		rel := &release.Release{Name: "test", Namespace: "ns", Manifest: manifest}
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(nil)
+		helm.cfg.Releases.Driver = newMockReleasesDriver(rel, nil)
+		helm.tcc.(*mocks.MockTargetClusterClient).EXPECT().
+			GetObject(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, key client.ObjectKey, object client.Object) error {
+				content, ok := live[key]
+				if !ok {
+					return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+				}
+				u := object.(*unstructured.Unstructured)
+				for k, v := range content {
+					u.Object[k] = v
+				}
+				return nil
+			}).Times(3)
+
+		drift, err := helm.Drift(ctx, "test")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"ConfigMap ns/config: data.key changed",
+			"ClusterRole web: deleted",
+		}, drift)
+	})
+
+	t.Run("returns an error when a resource can't be read", func(t *testing.T) {
+		t.Parallel()
+		rel := &release.Release{Name: "test", Namespace: "ns", Manifest: manifest}
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(nil)
+		helm.cfg.Releases.Driver = newMockReleasesDriver(rel, nil)
+		helm.tcc.(*mocks.MockTargetClusterClient).EXPECT().
+			GetObject(ctx, gomock.Any(), gomock.Any()).
+			Return(fmt.Errorf("oops"))
+
+		_, err = helm.Drift(ctx, "test")
+
+		assert.EqualError(t, err, "checking drift of helm release test: getting ConfigMap ns/config: oops")
+	})
+}
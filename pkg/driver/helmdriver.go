@@ -7,22 +7,45 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/opencontainers/go-digest"
+	"go.opentelemetry.io/otel/attribute"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	auth "github.com/aws/eks-anywhere-packages/pkg/authenticator"
+	"github.com/aws/eks-anywhere-packages/pkg/failure"
+	"github.com/aws/eks-anywhere-packages/pkg/metrics"
 	packagesRegistry "github.com/aws/eks-anywhere-packages/pkg/registry"
+	"github.com/aws/eks-anywhere-packages/pkg/tracing"
 )
 
 const (
-	varHelmUpgradeMaxHistory = 2
+	// DefaultMaxHistory is the number of revisions of a release kept when
+	// the package doesn't set its own limit.
+	DefaultMaxHistory = 2
+	// varHelmWaitTimeout is how long helm waits for resources when the
+	// package doesn't set a timeout.
+	varHelmWaitTimeout = 5 * time.Minute
+)
+
+// Reasons helm operations fail for.
+const (
+	helmErrorChart     = "chart"
+	helmErrorRelease   = "release"
+	helmErrorInstall   = "install"
+	helmErrorUpgrade   = "upgrade"
+	helmErrorRollback  = "rollback"
+	helmErrorUninstall = "uninstall"
 )
 
 // helmDriver implements PackageDriver to install packages from Helm charts.
@@ -32,6 +55,9 @@ type helmDriver struct {
 	tcc        auth.TargetClusterClient
 	log        logr.Logger
 	settings   *cli.EnvSettings
+
+	// clusterName is the cluster cfg was created for.
+	clusterName string
 }
 
 var _ PackageDriver = (*helmDriver)(nil)
@@ -51,7 +77,13 @@ func (d *helmDriver) Initialize(ctx context.Context, clusterName string) (err er
 	}
 	err = d.tcc.Initialize(ctx, clusterName)
 	if err != nil {
-		return fmt.Errorf("initialiing target cluster %s client for helm driver: %w", clusterName, err)
+		return failure.Errorf(api.FailureReasonClusterUnreachable, "initialiing target cluster %s client for helm driver: %w", clusterName, err)
+	}
+
+	// The helm configuration and its registry client are kept for as long
+	// as the driver is used for the same cluster.
+	if d.cfg != nil && d.clusterName == clusterName {
+		return nil
 	}
 
 	d.settings = cli.New()
@@ -66,46 +98,35 @@ func (d *helmDriver) Initialize(ctx context.Context, clusterName string) (err er
 	d.cfg = &action.Configuration{RegistryClient: client}
 	err = d.cfg.Init(d.tcc, d.settings.Namespace(), os.Getenv("HELM_DRIVER"), helmLog(d.log))
 	if err != nil {
-		return fmt.Errorf("initializing helm driver: %w", err)
+		d.cfg = nil
+		return failure.Errorf(api.FailureReasonClusterUnreachable, "initializing helm driver: %w", err)
 	}
+	d.clusterName = clusterName
 
 	return nil
 }
 
 func (d *helmDriver) Install(ctx context.Context,
-	name, namespace string, createNamespace bool, source api.PackageOCISource, values map[string]interface{},
-) error {
+	name, namespace string, createNamespace bool, source api.PackageOCISource, values map[string]interface{}, options api.InstallOptions,
+) (int, error) {
 	var err error
 	install := action.NewInstall(d.cfg)
 	install.Version = source.Version
 	install.ReleaseName = name
 	install.CreateNamespace = createNamespace
+	install.Wait = isSet(options.Wait)
+	install.Atomic = isSet(options.Atomic)
+	install.Timeout = helmTimeout(options)
+	install.SkipCRDs = isSet(options.SkipCRDs)
+	install.DisableHooks = isSet(options.DisableHooks)
 
-	helmChart, err := d.getChart(install, source)
+	helmChart, err := d.getChart(ctx, install, source)
 	if err != nil {
-		return fmt.Errorf("loading helm chart %s: %w", name, err)
-	}
-	// If no target namespace provided read chart values to find namespace
-	if namespace == "" {
-		if chartNS, ok := helmChart.Values["defaultNamespace"]; ok {
-			namespace = chartNS.(string)
-		} else {
-			// Fall back case of assuming its default
-			namespace = "default"
-		}
+		return 0, helmError(helmErrorChart, fmt.Errorf("loading helm chart %s: %w", name, err))
 	}
+	namespace = chartNamespace(helmChart, namespace)
 	install.Namespace = namespace
-
-	// Update values with imagePullSecrets
-	// If no secret values we should still continue as it could be case of public registry or local registry
-	secretvals, err := d.secretAuth.GetSecretValues(ctx, namespace)
-	if err != nil {
-		secretvals = nil
-		// Continue as its possible that a private registry is being used here and thus no data necessary
-	}
-	for key, val := range secretvals {
-		values[key] = val
-	}
+	d.addSecretValues(ctx, namespace, values)
 
 	// Check if there exists a matching helm release.
 	get := action.NewGet(d.cfg)
@@ -119,26 +140,26 @@ func (d *helmDriver) Install(ctx context.Context,
 			if err := d.secretAuth.AddSecretToAllNamespace(ctx); err != nil {
 				d.log.Info("failed to Update Secret in all namespaces", "error", err)
 			}
-			err = d.createRelease(ctx, install, helmChart, values)
+			revision, err := d.createRelease(ctx, install, helmChart, values)
 			if err != nil {
 				err1 := d.secretAuth.DelFromConfigMap(ctx, name, namespace)
 				if err1 != nil {
 					d.log.Info("failed to remove namespace from configmap")
 				}
-				return err
+				return 0, err
 			}
 			// Failsafe in event namespace is created via the charts
 			if err := d.secretAuth.AddSecretToAllNamespace(ctx); err != nil {
 				d.log.Info("failed to Update Secret in all namespaces", "error", err)
 			}
-			return nil
+			return revision, nil
 		}
-		return fmt.Errorf("getting helm release %s: %w", name, err)
+		return 0, helmError(helmErrorRelease, fmt.Errorf("getting helm release %s: %w", name, err))
 	}
 
-	err = d.upgradeRelease(ctx, name, helmChart, values)
+	revision, err := d.upgradeRelease(ctx, name, helmChart, values, options)
 	if err != nil {
-		return fmt.Errorf("upgrading helm chart %s: %w", name, err)
+		return 0, fmt.Errorf("upgrading helm chart %s: %w", name, err)
 	}
 
 	// Update installed-namespaces on successful install
@@ -150,27 +171,145 @@ func (d *helmDriver) Install(ctx context.Context,
 		d.log.Info("failed to Update Secret in all namespaces", "error", err)
 	}
 
-	return nil
+	return revision, nil
 }
 
-func (d *helmDriver) getChart(install *action.Install, source api.PackageOCISource) (*chart.Chart, error) {
-	url := source.GetChartUri()
+// helmFailures are the failure reasons of helm errors which aren't classified
+// more precisely, by the reason they are counted under.
+var helmFailures = map[string]api.FailureReasonEnum{
+	helmErrorChart:     api.FailureReasonInvalidArtifact,
+	helmErrorRelease:   api.FailureReasonClusterUnreachable,
+	helmErrorInstall:   api.FailureReasonInstallFailed,
+	helmErrorUpgrade:   api.FailureReasonInstallFailed,
+	helmErrorRollback:  api.FailureReasonRollbackFailed,
+	helmErrorUninstall: api.FailureReasonUninstallFailed,
+}
+
+// helmConflicts are the messages of helm errors caused by another operation
+// on the release, or by resources belonging to something else.
+var helmConflicts = []string{
+	"another operation (install/upgrade/rollback) is in progress",
+	"cannot re-use a name that is still in use",
+	"cannot be imported into the current release",
+}
+
+// helmError counts a failed helm operation by the reason it failed for,
+// returning its error with its failure reason.
+func helmError(reason string, err error) error {
+	metrics.HelmError(reason)
+	if isHelmConflict(err) {
+		return failure.Wrap(api.FailureReasonHelmConflict, err)
+	}
+	return failure.Wrap(helmFailures[reason], err)
+}
+
+// isHelmConflict returns true if an error is a conflict with another
+// operation or owner.
+func isHelmConflict(err error) bool {
+	if apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) {
+		return true
+	}
+	for _, conflict := range helmConflicts {
+		if strings.Contains(err.Error(), conflict) {
+			return true
+		}
+	}
+	return false
+}
+
+// spanAttributes returns the attributes of spans of a release.
+func (d *helmDriver) spanAttributes(name string) []attribute.KeyValue {
+	return []attribute.KeyValue{tracing.PackageKey.String(name), tracing.ClusterKey.String(d.clusterName)}
+}
+
+// isSet returns true if an option is set and true.
+func isSet(option *bool) bool {
+	return option != nil && *option
+}
+
+// maxHistory returns the number of revisions of a release to keep for the
+// options.
+func maxHistory(options api.InstallOptions) int {
+	if options.MaxHistory != nil {
+		return *options.MaxHistory
+	}
+	return DefaultMaxHistory
+}
+
+// helmTimeout returns the timeout of helm's operations for the options.
+func helmTimeout(options api.InstallOptions) time.Duration {
+	if options.Timeout != nil {
+		return options.Timeout.Duration
+	}
+	if isSet(options.Wait) || isSet(options.Atomic) {
+		return varHelmWaitTimeout
+	}
+	return 0
+}
+
+// chartNamespace returns the namespace to install a chart into. If no target
+// namespace is provided, the chart's values are read to find one.
+func chartNamespace(helmChart *chart.Chart, namespace string) string {
+	if namespace != "" {
+		return namespace
+	}
+	if chartNS, ok := helmChart.Values["defaultNamespace"].(string); ok {
+		return chartNS
+	}
+	// Fall back case of assuming its default
+	return "default"
+}
+
+// addSecretValues updates values with imagePullSecrets.
+func (d *helmDriver) addSecretValues(ctx context.Context, namespace string, values map[string]interface{}) {
+	// If no secret values we should still continue as it could be case of public registry or local registry
+	secretvals, err := d.secretAuth.GetSecretValues(ctx, namespace)
+	if err != nil {
+		// Continue as its possible that a private registry is being used here and thus no data necessary
+		return
+	}
+	for key, val := range secretvals {
+		values[key] = val
+	}
+}
+
+func (d *helmDriver) getChart(ctx context.Context, install *action.Install, source api.PackageOCISource) (_ *chart.Chart, err error) {
+	url, err := chartReference(source)
+	if err != nil {
+		return nil, failure.Wrap(api.FailureReasonInvalidBundle, err)
+	}
+	_, span := tracing.Start(ctx, "Locate helm chart", append(d.spanAttributes(install.ReleaseName), tracing.ArtifactKey.String(url))...)
+	defer func() { tracing.End(span, err) }()
 	chartPath, err := install.LocateChart(url, d.settings)
 	if err != nil {
-		return nil, fmt.Errorf("locating helm chart %s tag %s: %w", url, source.Digest, err)
+		return nil, failure.Errorf(packagesRegistry.ErrorReason(err), "locating helm chart %s tag %s: %w", url, source.Version, err)
 	}
 	return loader.Load(chartPath)
 }
 
+// chartReference returns the reference of a package's chart pinned to the
+// digest recorded in the bundle, so that a chart retagged in a registry is
+// never installed in its place. Helm pulls the chart by the digest, and
+// refuses it if the version's tag refers to anything else.
+func chartReference(source api.PackageOCISource) (string, error) {
+	url := source.GetChartUri()
+	if _, err := digest.Parse(source.Digest); err != nil {
+		return "", fmt.Errorf("pinning helm chart %s tag %s to digest %q: %w", url, source.Version, source.Digest, err)
+	}
+	return url + "@" + source.Digest, nil
+}
+
 func (d *helmDriver) createRelease(ctx context.Context,
 	install *action.Install, helmChart *chart.Chart, values map[string]interface{},
-) error {
-	_, err := install.RunWithContext(ctx, helmChart, values)
+) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "Install helm release", d.spanAttributes(install.ReleaseName)...)
+	defer func() { tracing.End(span, err) }()
+	rel, err := install.RunWithContext(ctx, helmChart, values)
 	if err != nil {
-		return fmt.Errorf("installing helm chart %s: %w", install.ReleaseName, err)
+		return 0, helmError(helmErrorInstall, fmt.Errorf("installing helm chart %s: %w", install.ReleaseName, err))
 	}
 
-	return nil
+	return rel.Version, nil
 }
 
 // helmChartURLIsPrefixed detects if the given URL has an acceptable scheme
@@ -181,35 +320,156 @@ func helmChartURLIsPrefixed(url string) bool {
 		strings.HasPrefix(url, "oci://")
 }
 
-// upgradeRelease instructs helm to upgrade a release.
+// upgradeRelease instructs helm to upgrade a release, returning its new
+// revision.
 func (d *helmDriver) upgradeRelease(ctx context.Context, name string,
-	helmChart *chart.Chart, values map[string]interface{},
-) (err error) {
+	helmChart *chart.Chart, values map[string]interface{}, options api.InstallOptions,
+) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "Upgrade helm release", d.spanAttributes(name)...)
+	defer func() { tracing.End(span, err) }()
 	// upgrade unless changes in the values are detected. For POC, run helm
 	// every time and rely on its idempotency.
 	upgrade := action.NewUpgrade(d.cfg)
 	// Limit history saved as secret for resource limit
-	upgrade.MaxHistory = varHelmUpgradeMaxHistory
-	_, err = upgrade.RunWithContext(ctx, name, helmChart, values)
+	upgrade.MaxHistory = maxHistory(options)
+	upgrade.Wait = isSet(options.Wait)
+	upgrade.Atomic = isSet(options.Atomic)
+	upgrade.Timeout = helmTimeout(options)
+	upgrade.SkipCRDs = isSet(options.SkipCRDs)
+	upgrade.DisableHooks = isSet(options.DisableHooks)
+	rel, err := upgrade.RunWithContext(ctx, name, helmChart, values)
 	if err != nil {
-		return fmt.Errorf("upgrading helm release %s: %w", name, err)
+		if upgrade.Atomic && d.rolledBackFrom(name, rel) {
+			err = fmt.Errorf("%w: %w", ErrRolledBack, err)
+		}
+		return 0, helmError(helmErrorUpgrade, fmt.Errorf("upgrading helm release %s: %w", name, err))
 	}
 
-	return nil
+	return rel.Version, nil
+}
+
+// rolledBackFrom returns true if a release was rolled back from its failed
+// revision, as helm does when an atomic upgrade fails.
+func (d *helmDriver) rolledBackFrom(name string, failed *release.Release) bool {
+	if failed == nil {
+		return false
+	}
+	get := action.NewGet(d.cfg)
+	rel, err := get.Run(name)
+	if err != nil {
+		return false
+	}
+	return rel.Version > failed.Version && rel.Info != nil && rel.Info.Status == release.StatusDeployed
+}
+
+// newRollback returns a helm rollback configured by the install options.
+func (d *helmDriver) newRollback(options api.InstallOptions) *action.Rollback {
+	rollback := action.NewRollback(d.cfg)
+	// Limit history saved as secret for resource limit
+	rollback.MaxHistory = maxHistory(options)
+	rollback.Wait = isSet(options.Wait)
+	rollback.Timeout = helmTimeout(options)
+	rollback.DisableHooks = isSet(options.DisableHooks)
+	return rollback
+}
+
+// Rollback instructs helm to roll a release back to its previous revision
+// when the current revision failed or was left pending.
+func (d *helmDriver) Rollback(_ context.Context, name string, options api.InstallOptions) (bool, error) {
+	get := action.NewGet(d.cfg)
+	rel, err := get.Run(name)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return false, nil
+		}
+		return false, helmError(helmErrorRelease, fmt.Errorf("getting helm release %s: %w", name, err))
+	}
+	if rel.Info == nil || (rel.Info.Status != release.StatusFailed && !rel.Info.Status.IsPending()) {
+		return false, nil
+	}
+
+	rollback := d.newRollback(options)
+	if err := rollback.Run(name); err != nil {
+		return false, helmError(helmErrorRollback, fmt.Errorf("rolling back helm release %s: %w", name, err))
+	}
+
+	return true, nil
+}
+
+// RollbackTo instructs helm to roll a release back to an earlier revision.
+func (d *helmDriver) RollbackTo(_ context.Context, name string, revision int, options api.InstallOptions) (int, error) {
+	rollback := d.newRollback(options)
+	rollback.Version = revision
+	if err := rollback.Run(name); err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			// Helm prunes revisions beyond the release's history.
+			err = fmt.Errorf("%w: %w", ErrRevisionNotKept, err)
+		}
+		return 0, helmError(helmErrorRollback, fmt.Errorf("rolling back helm release %s to revision %d: %w", name, revision, err))
+	}
+
+	get := action.NewGet(d.cfg)
+	rel, err := get.Run(name)
+	if err != nil {
+		return 0, fmt.Errorf("getting helm release %s: %w", name, err)
+	}
+	return rel.Version, nil
+}
+
+// NotReady checks the readiness of the workloads in a release.
+func (d *helmDriver) NotReady(ctx context.Context, name string) ([]string, error) {
+	get := action.NewGet(d.cfg)
+	rel, err := get.Run(name)
+	if err != nil {
+		return nil, fmt.Errorf("getting helm release %s: %w", name, err)
+	}
+	notReady, err := notReady(ctx, d.tcc, rel.Manifest, rel.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("checking readiness of helm release %s: %w", name, err)
+	}
+	return notReady, nil
+}
+
+// Drift compares the resources in a release's manifest with their live state.
+func (d *helmDriver) Drift(ctx context.Context, name string) ([]string, error) {
+	get := action.NewGet(d.cfg)
+	rel, err := get.Run(name)
+	if err != nil {
+		return nil, fmt.Errorf("getting helm release %s: %w", name, err)
+	}
+	drifted, err := drifted(ctx, d.tcc, rel.Manifest, rel.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("checking drift of helm release %s: %w", name, err)
+	}
+	return drifted, nil
 }
 
 func (d *helmDriver) Uninstall(ctx context.Context, name string) (err error) {
-	uninstall := action.NewUninstall(d.cfg)
-	rel, err := uninstall.Run(name)
+	ctx, span := tracing.Start(ctx, "Uninstall helm release", d.spanAttributes(name)...)
+	defer func() { tracing.End(span, err) }()
+	get := action.NewGet(d.cfg)
+	rel, err := get.Run(name)
 	if err != nil {
 		if errors.Is(err, driver.ErrReleaseNotFound) {
 			return nil
 		}
-		return fmt.Errorf("uninstalling helm chart %s: %w", name, err)
+		return helmError(helmErrorRelease, fmt.Errorf("getting helm release %s: %w", name, err))
 	}
-	err = d.secretAuth.DelFromConfigMap(ctx, name, rel.Release.Namespace)
+
+	// Remove the namespace from the configmap before the release, so the
+	// release is still there to find the namespace when this is retried.
+	err = d.secretAuth.DelFromConfigMap(ctx, name, rel.Namespace)
 	if err != nil {
-		d.log.Info("failed to remove namespace from configmap")
+		return fmt.Errorf("removing %s from configmap: %w", name, err)
+	}
+
+	uninstall := action.NewUninstall(d.cfg)
+	_, err = uninstall.Run(name)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil
+		}
+		return helmError(helmErrorUninstall, fmt.Errorf("uninstalling helm chart %s: %w", name, err))
 	}
 	return nil
 }
@@ -3,16 +3,31 @@ package driver
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
+	"github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
-
+	"helm.sh/helm/v3/pkg/storage/driver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	"github.com/aws/eks-anywhere-packages/pkg/authenticator/mocks"
+	"github.com/aws/eks-anywhere-packages/pkg/failure"
 )
 
 var ctx context.Context = context.Background()
@@ -39,6 +54,131 @@ func TestHelmChartURLIsPrefixed(t *testing.T) {
 	})
 }
 
+func TestHelmTimeout(t *testing.T) {
+	yes, no := true, false
+	assert.Zero(t, helmTimeout(api.InstallOptions{}))
+	assert.Zero(t, helmTimeout(api.InstallOptions{Wait: &no}))
+	assert.Equal(t, varHelmWaitTimeout, helmTimeout(api.InstallOptions{Wait: &yes}))
+	assert.Equal(t, varHelmWaitTimeout, helmTimeout(api.InstallOptions{Atomic: &yes}))
+	assert.Equal(t, time.Hour, helmTimeout(api.InstallOptions{Timeout: &metav1.Duration{Duration: time.Hour}}))
+}
+
+func TestNewRollback(t *testing.T) {
+	helm, err := givenInitializedHelmDriver(t)
+	require.NoError(t, err)
+
+	rollback := helm.newRollback(api.InstallOptions{})
+	assert.Equal(t, DefaultMaxHistory, rollback.MaxHistory)
+	assert.False(t, rollback.Wait)
+	assert.False(t, rollback.DisableHooks)
+
+	yes, history := true, 5
+	rollback = helm.newRollback(api.InstallOptions{
+		Wait:         &yes,
+		DisableHooks: &yes,
+		MaxHistory:   &history,
+		Timeout:      &metav1.Duration{Duration: time.Hour},
+	})
+	assert.Equal(t, 5, rollback.MaxHistory)
+	assert.True(t, rollback.Wait)
+	assert.True(t, rollback.DisableHooks)
+	assert.Equal(t, time.Hour, rollback.Timeout)
+}
+
+func TestRolledBackFrom(t *testing.T) {
+	failed := &release.Release{Name: "name-does-not-matter", Version: 2, Info: &release.Info{Status: release.StatusFailed}}
+
+	t.Run("detects a release rolled back past its failed revision", func(t *testing.T) {
+		t.Parallel()
+
+		rel := &release.Release{Name: "name-does-not-matter", Version: 3, Info: &release.Info{Status: release.StatusDeployed}}
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(nil)
+		helm.cfg.Releases.Driver = newMockReleasesDriver(rel, nil)
+
+		assert.True(t, helm.rolledBackFrom("name-does-not-matter", failed))
+	})
+
+	t.Run("ignores a release left at its failed revision", func(t *testing.T) {
+		t.Parallel()
+
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(nil)
+		helm.cfg.Releases.Driver = newMockReleasesDriver(failed, nil)
+
+		assert.False(t, helm.rolledBackFrom("name-does-not-matter", failed))
+		assert.False(t, helm.rolledBackFrom("name-does-not-matter", nil))
+	})
+}
+
+func TestChartReference(t *testing.T) {
+	source := api.PackageOCISource{
+		Registry:   "public.ecr.aws/eks-anywhere",
+		Repository: "hello-eks-anywhere",
+		Version:    "0.1.0",
+		Digest:     "sha256:0526725a65691944f1c2ea13d1b2ac1d2ea0e1e8e4b8f0c7e6d5a4b3c2d1e0f9",
+	}
+
+	t.Run("pins the chart to its digest", func(t *testing.T) {
+		url, err := chartReference(source)
+		require.NoError(t, err)
+		assert.Equal(t, "oci://public.ecr.aws/eks-anywhere/hello-eks-anywhere@"+source.Digest, url)
+	})
+
+	t.Run("refuses a chart without a digest", func(t *testing.T) {
+		unpinned := source
+		unpinned.Digest = ""
+		_, err := chartReference(unpinned)
+		assert.ErrorContains(t, err, `pinning helm chart oci://public.ecr.aws/eks-anywhere/hello-eks-anywhere tag 0.1.0 to digest ""`)
+	})
+
+	t.Run("refuses a chart with an invalid digest", func(t *testing.T) {
+		invalid := source
+		invalid.Digest = "sha256:deadbeef"
+		_, err := chartReference(invalid)
+		assert.ErrorIs(t, err, digest.ErrDigestInvalidLength)
+	})
+}
+
+func TestInstallChartDigestMismatch(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json",` +
+		`"config":{"mediaType":"application/vnd.cncf.helm.config.v1+json","digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a","size":2},"layers":[]}`)
+	tagged := digest.FromBytes(manifest)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The registry has retagged the version to a chart other than the
+		// one in the bundle.
+		if !strings.HasSuffix(r.URL.Path, "/manifests/0.1.0") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Docker-Content-Digest", tagged.String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(manifest)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(manifest)
+		}
+	}))
+	defer server.Close()
+
+	helm, err := givenInitializedHelmDriver(t)
+	require.NoError(t, err)
+	helm.cfg.RegistryClient, err = registry.NewClient(registry.ClientOptPlainHTTP())
+	require.NoError(t, err)
+	source := api.PackageOCISource{
+		Registry:   strings.TrimPrefix(server.URL, "http://"),
+		Repository: "hello-eks-anywhere",
+		Version:    "0.1.0",
+		Digest:     digest.FromString("the chart in the bundle").String(),
+	}
+
+	revision, err := helm.Install(ctx, "hello-eks-anywhere", "eksa-packages", false, source, map[string]interface{}{}, api.InstallOptions{})
+
+	assert.ErrorContains(t, err, "chart reference digest mismatch: "+tagged.String()+" is not "+source.Digest)
+	assert.Equal(t, 0, revision)
+}
+
 func TestHelmDriverInitialize(t *testing.T) {
 	t.Run("golden path", func(t *testing.T) {
 		t.Parallel()
@@ -48,6 +188,20 @@ func TestHelmDriverInitialize(t *testing.T) {
 
 		assert.NoError(t, err)
 	})
+
+	t.Run("keeps the helm configuration of the cluster", func(t *testing.T) {
+		t.Parallel()
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		cfg := helm.cfg
+		helm.secretAuth.(*mocks.MockAuthenticator).EXPECT().Initialize("billy")
+		helm.tcc.(*mocks.MockTargetClusterClient).EXPECT().Initialize(ctx, "billy")
+
+		err = helm.Initialize(ctx, "billy")
+
+		require.NoError(t, err)
+		assert.Same(t, cfg, helm.cfg)
+	})
 }
 
 func TestIsConfigChanged(t *testing.T) {
@@ -136,6 +290,224 @@ func TestIsConfigChanged(t *testing.T) {
 	})
 }
 
+func TestRollback(t *testing.T) {
+	t.Run("does nothing when the release isn't found", func(t *testing.T) {
+		t.Parallel()
+
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(nil)
+		helm.cfg.Releases.Driver = newMockReleasesDriver(nil, driver.ErrReleaseNotFound)
+
+		rolledBack, err := helm.Rollback(ctx, "name-does-not-exist", api.InstallOptions{})
+		assert.NoError(t, err)
+		assert.False(t, rolledBack)
+	})
+
+	t.Run("returns an error when the release can't be read", func(t *testing.T) {
+		t.Parallel()
+
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(fmt.Errorf("blah"))
+
+		rolledBack, err := helm.Rollback(ctx, "name-does-not-matter", api.InstallOptions{})
+		assert.Error(t, err)
+		assert.False(t, rolledBack)
+	})
+
+	t.Run("does nothing when the release is deployed", func(t *testing.T) {
+		t.Parallel()
+
+		rel := &release.Release{Name: "name-does-not-matter", Info: &release.Info{Status: release.StatusDeployed}}
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(nil)
+		helm.cfg.Releases.Driver = newMockReleasesDriver(rel, nil)
+
+		rolledBack, err := helm.Rollback(ctx, "name-does-not-matter", api.InstallOptions{})
+		assert.NoError(t, err)
+		assert.False(t, rolledBack)
+	})
+}
+
+func TestRollbackTo(t *testing.T) {
+	t.Run("returns an error when the revision isn't found", func(t *testing.T) {
+		t.Parallel()
+
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(nil)
+		helm.cfg.Releases.Driver = newMockReleasesDriver(nil, driver.ErrReleaseNotFound)
+
+		revision, err := helm.RollbackTo(ctx, "name-does-not-exist", 1, api.InstallOptions{})
+		assert.ErrorContains(t, err, "rolling back helm release name-does-not-exist to revision 1")
+		assert.ErrorIs(t, err, ErrRevisionNotKept)
+		assert.Equal(t, 0, revision)
+	})
+}
+
+func TestUninstall(t *testing.T) {
+	t.Run("does nothing when the release isn't found", func(t *testing.T) {
+		t.Parallel()
+
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(nil)
+		helm.cfg.Releases.Driver = newMockReleasesDriver(nil, driver.ErrReleaseNotFound)
+
+		err = helm.Uninstall(ctx, "name-does-not-exist")
+		assert.NoError(t, err)
+	})
+
+	t.Run("returns an error when the release can't be read", func(t *testing.T) {
+		t.Parallel()
+
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(fmt.Errorf("blah"))
+
+		err = helm.Uninstall(ctx, "name-does-not-matter")
+		assert.Error(t, err)
+	})
+
+	t.Run("keeps the release when the configmap can't be updated", func(t *testing.T) {
+		t.Parallel()
+
+		rel := &release.Release{Name: "name-does-not-matter", Namespace: "ns", Info: &release.Info{Status: release.StatusDeployed}}
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(nil)
+		helm.cfg.Releases.Driver = newMockReleasesDriver(rel, nil)
+		helm.secretAuth.(*mocks.MockAuthenticator).EXPECT().
+			DelFromConfigMap(ctx, "name-does-not-matter", "ns").
+			Return(fmt.Errorf("oops"))
+
+		err = helm.Uninstall(ctx, "name-does-not-matter")
+		assert.EqualError(t, err, "removing name-does-not-matter from configmap: oops")
+	})
+}
+
+func TestNotReady(t *testing.T) {
+	manifest := `---
+# Source: test/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+# Source: test/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+# Source: test/templates/statefulset.yaml
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  namespace: other
+---
+# Source: test/templates/job.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+---
+# Source: test/crds/crd.yaml
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: things.example.com
+`
+	live := map[client.ObjectKey]map[string]interface{}{
+		{Namespace: "ns", Name: "web"}: {
+			"metadata": map[string]interface{}{"generation": int64(1)},
+			"spec":     map[string]interface{}{"replicas": int64(1)},
+			"status": map[string]interface{}{
+				"observedGeneration": int64(1), "replicas": int64(1), "readyReplicas": int64(1),
+				"availableReplicas": int64(1), "updatedReplicas": int64(1),
+				"conditions": []interface{}{map[string]interface{}{"type": "Available", "status": "True"}},
+			},
+		},
+		{Namespace: "other", Name: "db"}: {
+			"metadata": map[string]interface{}{"generation": int64(1)},
+			"spec":     map[string]interface{}{"replicas": int64(2)},
+			"status": map[string]interface{}{
+				"observedGeneration": int64(1), "replicas": int64(2), "readyReplicas": int64(1),
+				"currentReplicas": int64(2), "updatedReplicas": int64(2),
+			},
+		},
+		{Name: "things.example.com"}: {
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Established", "status": "True"},
+					map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+				},
+			},
+		},
+	}
+	givenReadinessDriver := func(t *testing.T, getErr error) *helmDriver {
+		rel := &release.Release{Name: "test", Namespace: "ns", Manifest: manifest}
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(nil)
+		helm.cfg.Releases.Driver = newMockReleasesDriver(rel, nil)
+		helm.tcc.(*mocks.MockTargetClusterClient).EXPECT().
+			GetObject(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, key client.ObjectKey, object client.Object) error {
+				if getErr != nil {
+					return getErr
+				}
+				content, ok := live[key]
+				if !ok {
+					return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+				}
+				u := object.(*unstructured.Unstructured)
+				for k, v := range content {
+					u.Object[k] = v
+				}
+				return nil
+			}).AnyTimes()
+		return helm
+	}
+
+	t.Run("describes workloads which aren't ready", func(t *testing.T) {
+		t.Parallel()
+		helm := givenReadinessDriver(t, nil)
+
+		notReady, err := helm.NotReady(ctx, "test")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"StatefulSet other/db: Ready: 1/2",
+			"Job ns/migrate: not found",
+		}, notReady)
+	})
+
+	t.Run("returns an error when a workload can't be read", func(t *testing.T) {
+		t.Parallel()
+		helm := givenReadinessDriver(t, fmt.Errorf("oops"))
+
+		_, err := helm.NotReady(ctx, "test")
+
+		assert.EqualError(t, err, "checking readiness of helm release test: getting Deployment ns/web: oops")
+	})
+
+	t.Run("returns an error when the release isn't found", func(t *testing.T) {
+		t.Parallel()
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		helm.cfg.KubeClient = newMockKube(nil)
+		helm.cfg.Releases.Driver = newMockReleasesDriver(nil, driver.ErrReleaseNotFound)
+
+		_, err = helm.NotReady(ctx, "test")
+
+		assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+	})
+}
+
 func givenHelmDriver(t *testing.T) *helmDriver {
 	mockSecretAuth := mocks.NewMockAuthenticator(gomock.NewController(t))
 	mockSecretAuth.EXPECT().Initialize("billy")
@@ -228,3 +600,24 @@ func shallowCopy(t *testing.T, src map[string]interface{}) map[string]interface{
 	}
 	return dst
 }
+
+func TestHelmError(t *testing.T) {
+	t.Run("reason of the operation", func(t *testing.T) {
+		err := helmError(helmErrorUninstall, fmt.Errorf("boom"))
+		assert.EqualError(t, err, "boom")
+		assert.Equal(t, api.FailureReasonUninstallFailed, failure.Reason(err, api.FailureReasonUnknown))
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		err := helmError(helmErrorUpgrade, fmt.Errorf("another operation (install/upgrade/rollback) is in progress"))
+		assert.Equal(t, api.FailureReasonHelmConflict, failure.Reason(err, api.FailureReasonUnknown))
+
+		err = helmError(helmErrorInstall, apierrors.NewAlreadyExists(schema.GroupResource{Resource: "configmaps"}, "hello"))
+		assert.Equal(t, api.FailureReasonHelmConflict, failure.Reason(err, api.FailureReasonUnknown))
+	})
+
+	t.Run("keeps the reason of the cause", func(t *testing.T) {
+		err := helmError(helmErrorChart, failure.Errorf(api.FailureReasonRegistryAuthFailed, "unauthorized"))
+		assert.Equal(t, api.FailureReasonRegistryAuthFailed, failure.Reason(err, api.FailureReasonUnknown))
+	})
+}
@@ -0,0 +1,125 @@
+package driver
+
+import (
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/release"
+	"sigs.k8s.io/yaml"
+)
+
+// containerFields are the fields of a pod spec listing containers.
+var containerFields = map[string]bool{
+	"containers":          true,
+	"initContainers":      true,
+	"ephemeralContainers": true,
+}
+
+// manifestImages returns the images of every container in a manifest, in
+// order and without duplicates. Pod specs are found wherever they are nested,
+// so the images of pods, workloads and jobs are all included.
+func manifestImages(manifest string) ([]string, error) {
+	objects, err := parseManifest(manifest, "")
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool)
+	for _, obj := range objects {
+		eachContainer(obj.Object, func(container map[string]interface{}) {
+			if image, ok := container["image"].(string); ok && image != "" {
+				found[image] = true
+			}
+		})
+	}
+	images := make([]string, 0, len(found))
+	for image := range found {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+// releaseManifest returns the manifest of a release followed by those of its
+// hooks, which helm keeps apart.
+func releaseManifest(rel *release.Release) string {
+	var manifest strings.Builder
+	manifest.WriteString(rel.Manifest)
+	for _, hook := range rel.Hooks {
+		manifest.WriteString("\n---\n")
+		manifest.WriteString(hook.Manifest)
+	}
+	return manifest.String()
+}
+
+// overrideImageRegistry returns a manifest with the registry of every
+// container image replaced by the given one, as charts do with their
+// sourceRegistry value. Images which don't name a registry are left as they
+// are, and a manifest with nothing to replace is returned unchanged.
+func overrideImageRegistry(manifest, registry string) (string, error) {
+	objects, err := parseManifest(manifest, "")
+	if err != nil {
+		return "", err
+	}
+	replaced := false
+	for _, obj := range objects {
+		eachContainer(obj.Object, func(container map[string]interface{}) {
+			image, ok := container["image"].(string)
+			if !ok {
+				return
+			}
+			if override := withRegistry(image, registry); override != image {
+				container["image"] = override
+				replaced = true
+			}
+		})
+	}
+	if !replaced {
+		return manifest, nil
+	}
+
+	var overridden strings.Builder
+	for _, obj := range objects {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return "", err
+		}
+		overridden.WriteString("---\n")
+		overridden.Write(data)
+	}
+	return overridden.String(), nil
+}
+
+// withRegistry returns an image with its registry replaced, or the image
+// itself if it doesn't name one.
+func withRegistry(image, registry string) string {
+	slash := strings.Index(image, "/")
+	if slash < 0 {
+		return image
+	}
+	if domain := image[:slash]; !strings.ContainsAny(domain, ".:") && domain != "localhost" {
+		return image
+	}
+	return strings.TrimSuffix(registry, "/") + image[slash:]
+}
+
+// eachContainer calls fn with each container of the pod specs found in a
+// resource, wherever they are nested.
+func eachContainer(value interface{}, fn func(container map[string]interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if containers, ok := field.([]interface{}); ok && containerFields[key] {
+				for _, c := range containers {
+					if container, ok := c.(map[string]interface{}); ok {
+						fn(container)
+					}
+				}
+			}
+			eachContainer(field, fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			eachContainer(item, fn)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestManifestImages(t *testing.T) {
+	t.Run("finds the images of every container", func(t *testing.T) {
+		manifest := `---
+# Source: hello/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: registry.example.com/init@sha256:1111
+      containers:
+      - name: hello
+        image: registry.example.com/hello@sha256:2222
+      - name: sidecar
+        image: registry.example.com/sidecar:latest
+---
+# Source: hello/templates/cronjob.yaml
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: cleanup
+            image: registry.example.com/hello@sha256:2222
+---
+# Source: hello/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: hello
+data:
+  image: not-a-container
+`
+		images, err := manifestImages(manifest)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"registry.example.com/hello@sha256:2222",
+			"registry.example.com/init@sha256:1111",
+			"registry.example.com/sidecar:latest",
+		}, images)
+	})
+
+	t.Run("finds nothing without containers", func(t *testing.T) {
+		images, err := manifestImages("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: hello\n")
+		require.NoError(t, err)
+		assert.Empty(t, images)
+	})
+
+	t.Run("fails for an invalid manifest", func(t *testing.T) {
+		_, err := manifestImages("---\n# Source: hello/bad.yaml\n: :\n")
+		assert.ErrorContains(t, err, "parsing manifest")
+	})
+}
+
+func TestReleaseManifest(t *testing.T) {
+	rel := &release.Release{
+		Manifest: "---\n# Source: hello/templates/pod.yaml\napiVersion: v1\nkind: Pod\nmetadata:\n  name: hello\n" +
+			"spec:\n  containers:\n  - name: hello\n    image: registry.example.com/hello@sha256:1111\n",
+		Hooks: []*release.Hook{{
+			Path: "hello/templates/job.yaml",
+			Manifest: "# Source: hello/templates/job.yaml\napiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n" +
+				"spec:\n  template:\n    spec:\n      containers:\n      - name: migrate\n        image: registry.example.com/migrate@sha256:2222\n",
+		}},
+	}
+
+	images, err := manifestImages(releaseManifest(rel))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"registry.example.com/hello@sha256:1111",
+		"registry.example.com/migrate@sha256:2222",
+	}, images)
+}
+
+func TestOverrideImageRegistry(t *testing.T) {
+	manifest := `---
+# Source: addon/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: addon
+spec:
+  template:
+    spec:
+      containers:
+      - name: addon
+        image: public.ecr.aws/eks-anywhere/addon@sha256:1111
+      - name: proxy
+        image: nginx:latest
+`
+
+	t.Run("replaces the registry of every image naming one", func(t *testing.T) {
+		overridden, err := overrideImageRegistry(manifest, "mirror.example.com:5000/eks/")
+		require.NoError(t, err)
+		images, err := manifestImages(overridden)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mirror.example.com:5000/eks/eks-anywhere/addon@sha256:1111", "nginx:latest"}, images)
+	})
+
+	t.Run("returns a manifest with nothing to replace unchanged", func(t *testing.T) {
+		unchanged := "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: addon\n"
+		overridden, err := overrideImageRegistry(unchanged, "mirror.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, unchanged, overridden)
+	})
+
+	t.Run("fails for an invalid manifest", func(t *testing.T) {
+		_, err := overrideImageRegistry("---\n# Source: hello/bad.yaml\n: :\n", "mirror.example.com")
+		assert.ErrorContains(t, err, "parsing manifest")
+	})
+}
+
+func TestWithRegistry(t *testing.T) {
+	assert.Equal(t, "mirror.example.com/hello@sha256:1111", withRegistry("registry.example.com/hello@sha256:1111", "mirror.example.com"))
+	assert.Equal(t, "mirror.example.com/team/hello:v1", withRegistry("localhost:5000/team/hello:v1", "mirror.example.com"))
+	assert.Equal(t, "mirror.example.com/team/hello:v1", withRegistry("localhost/team/hello:v1", "mirror.example.com"))
+	assert.Equal(t, "library/hello:v1", withRegistry("library/hello:v1", "mirror.example.com"))
+	assert.Equal(t, "hello", withRegistry("hello", "mirror.example.com"))
+}
@@ -0,0 +1,80 @@
+package driver
+
+import (
+	"fmt"
+	"sort"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// clusterScopedKinds are the common kinds of resource which aren't namespaced,
+// so the release namespace doesn't apply to them.
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"PersistentVolume":               true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// manifestObject is a resource of a release manifest.
+type manifestObject struct {
+	*unstructured.Unstructured
+
+	key client.ObjectKey
+}
+
+func (o manifestObject) String() string {
+	if o.key.Namespace == "" {
+		return o.GetKind() + " " + o.key.Name
+	}
+	return o.GetKind() + " " + o.key.String()
+}
+
+// id identifies the resource by its group, kind, namespace and name, so
+// resources of the same kind and name from different API groups are told
+// apart. The version is left out, as a resource moving to another version of
+// its API is still the same resource, which mustn't be pruned.
+func (o manifestObject) id() string {
+	return o.GroupVersionKind().GroupKind().String() + " " + o.key.String()
+}
+
+// parseManifest returns the resources of a release manifest, in the order
+// they appear, with namespaced resources defaulting to the release namespace.
+func parseManifest(manifest, namespace string) ([]manifestObject, error) {
+	var objects []manifestObject
+	docs := releaseutil.SplitManifests(manifest)
+	names := make([]string, 0, len(docs))
+	for name := range docs {
+		names = append(names, name)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(names))
+	for _, name := range names {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(docs[name]), &obj.Object); err != nil {
+			return nil, fmt.Errorf("parsing manifest: %w", err)
+		}
+		if obj.Object == nil {
+			continue
+		}
+		key := client.ObjectKey{Name: obj.GetName()}
+		if !clusterScopedKinds[obj.GetKind()] {
+			key.Namespace = obj.GetNamespace()
+			if key.Namespace == "" {
+				key.Namespace = namespace
+			}
+		}
+		objects = append(objects, manifestObject{Unstructured: obj, key: key})
+	}
+	return objects, nil
+}
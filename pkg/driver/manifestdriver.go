@@ -0,0 +1,403 @@
+package driver
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/cli"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+	"github.com/aws/eks-anywhere-packages/pkg/artifacts"
+	auth "github.com/aws/eks-anywhere-packages/pkg/authenticator"
+	"github.com/aws/eks-anywhere-packages/pkg/failure"
+	packagesRegistry "github.com/aws/eks-anywhere-packages/pkg/registry"
+)
+
+const (
+	// manifestFieldOwner is the field manager of the resources applied by
+	// the manifest driver.
+	manifestFieldOwner = "eks-anywhere-packages"
+
+	// inventoryPrefix prefixes the name of the config map recording the
+	// resources applied for a package.
+	inventoryPrefix = "eksa-packages.inventory."
+
+	// sourceRegistryValue is the value overriding the registry of the images
+	// of a package.
+	sourceRegistryValue = "sourceRegistry"
+
+	inventoryManifestKey  = "manifest"
+	inventoryNamespaceKey = "namespace"
+	inventoryRevisionKey  = "revision"
+)
+
+// ErrNoInventory is returned for packages the manifest driver hasn't
+// installed.
+var ErrNoInventory = errors.New("inventory not found")
+
+// manifestDriver implements PackageDriver to install packages from OCI
+// artifacts of plain Kubernetes manifests or Kustomize overlays. Resources
+// are applied to the target cluster with server-side apply, and recorded in
+// an inventory so that those removed from a package are pruned. Packages
+// installed this way take no configuration other than the registry of their
+// images, and Helm's install options don't apply to them.
+type manifestDriver struct {
+	puller             artifacts.Puller
+	tcc                auth.TargetClusterClient
+	log                logr.Logger
+	clusterName        string
+	inventoryNamespace string
+}
+
+var _ PackageDriver = (*manifestDriver)(nil)
+
+func NewManifest(log logr.Logger, puller artifacts.Puller, tcc auth.TargetClusterClient) *manifestDriver {
+	return &manifestDriver{
+		puller: puller,
+		tcc:    tcc,
+		log:    log,
+		// Inventories are kept alongside Helm's release records.
+		inventoryNamespace: cli.New().Namespace(),
+	}
+}
+
+// inventory records the resources applied for a package.
+type inventory struct {
+	manifest  string
+	namespace string
+	revision  int
+}
+
+func (d *manifestDriver) Initialize(ctx context.Context, clusterName string) error {
+	d.clusterName = clusterName
+	if err := d.tcc.Initialize(ctx, clusterName); err != nil {
+		return failure.Errorf(api.FailureReasonClusterUnreachable, "initializing target cluster %s client for manifest driver: %w", clusterName, err)
+	}
+	return nil
+}
+
+func (d *manifestDriver) Install(ctx context.Context,
+	name, namespace string, createNamespace bool, source api.PackageOCISource, values map[string]interface{}, _ api.InstallOptions,
+) (_ int, err error) {
+	defer func() { err = failure.Wrap(api.FailureReasonInstallFailed, err) }()
+	if namespace == "" {
+		namespace = "default"
+	}
+	manifest, err := d.render(ctx, source, values)
+	if err != nil {
+		return 0, fmt.Errorf("loading manifest %s: %w", name, err)
+	}
+	objects, err := parseManifest(manifest, namespace)
+	if err != nil {
+		return 0, failure.Errorf(api.FailureReasonInvalidArtifact, "loading manifest %s: %w", name, err)
+	}
+	previous, err := d.getInventory(ctx, name)
+	if err != nil && !errors.Is(err, ErrNoInventory) {
+		return 0, err
+	}
+
+	if createNamespace {
+		ns := &unstructured.Unstructured{}
+		ns.SetAPIVersion("v1")
+		ns.SetKind("Namespace")
+		ns.SetName(namespace)
+		if err := d.tcc.ApplyObject(ctx, ns, manifestFieldOwner); err != nil {
+			return 0, fmt.Errorf("creating namespace %s: %w", namespace, err)
+		}
+	}
+	applied := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		obj.SetNamespace(obj.key.Namespace)
+		if err := d.tcc.ApplyObject(ctx, obj.Unstructured, manifestFieldOwner); err != nil {
+			return 0, fmt.Errorf("applying %s: %w", obj, err)
+		}
+		applied[obj.id()] = true
+	}
+
+	revision := 1
+	if previous != nil {
+		revision = previous.revision + 1
+		if err := d.prune(ctx, previous, applied); err != nil {
+			return 0, err
+		}
+	}
+	err = d.saveInventory(ctx, name, &inventory{manifest: manifest, namespace: namespace, revision: revision})
+	if err != nil {
+		return 0, err
+	}
+	return revision, nil
+}
+
+// render pulls a package's artifact and returns its manifest, with the
+// registry of its images overridden by the values.
+func (d *manifestDriver) render(ctx context.Context, source api.PackageOCISource, values map[string]interface{}) (string, error) {
+	uri := source.GetArtifactUri()
+	data, err := d.puller.Pull(ctx, uri, d.clusterName)
+	if err != nil {
+		return "", failure.Errorf(packagesRegistry.ErrorReason(err), "pulling %s: %w", uri, err)
+	}
+	manifest, err := renderManifest(data)
+	if err != nil {
+		return "", failure.Wrap(api.FailureReasonInvalidArtifact, err)
+	}
+	if registry, ok := values[sourceRegistryValue].(string); ok && registry != "" {
+		manifest, err = overrideImageRegistry(manifest, registry)
+		if err != nil {
+			return "", failure.Errorf(api.FailureReasonInvalidArtifact, "overriding image registry: %w", err)
+		}
+	}
+	return manifest, nil
+}
+
+// renderManifest returns the manifest of an artifact, which is either a
+// manifest itself or a gzipped tar archive of manifests. An archive with a
+// kustomization at its root is built with Kustomize, otherwise its YAML
+// files are concatenated in order.
+func renderManifest(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return string(data), nil
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("reading archive: %w", err)
+	}
+	fs := filesys.MakeFsInMemory()
+	var files []string
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("reading archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean("/" + header.Name)
+		content, err := io.ReadAll(archive)
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", name, err)
+		}
+		if err := fs.WriteFile(name, content); err != nil {
+			return "", fmt.Errorf("reading %s: %w", name, err)
+		}
+		files = append(files, name)
+	}
+
+	for _, kustomization := range []string{"/kustomization.yaml", "/kustomization.yml", "/Kustomization"} {
+		if !fs.Exists(kustomization) {
+			continue
+		}
+		resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fs, "/")
+		if err != nil {
+			return "", fmt.Errorf("building kustomization: %w", err)
+		}
+		manifest, err := resources.AsYaml()
+		if err != nil {
+			return "", fmt.Errorf("building kustomization: %w", err)
+		}
+		return string(manifest), nil
+	}
+
+	sort.Strings(files)
+	var manifest strings.Builder
+	for _, name := range files {
+		if ext := path.Ext(name); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		content, err := fs.ReadFile(name)
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", name, err)
+		}
+		manifest.WriteString("---\n# Source: " + strings.TrimPrefix(name, "/") + "\n")
+		manifest.Write(content)
+		if !bytes.HasSuffix(content, []byte("\n")) {
+			manifest.WriteString("\n")
+		}
+	}
+	return manifest.String(), nil
+}
+
+// prune deletes the resources of the previous inventory which weren't
+// applied again.
+func (d *manifestDriver) prune(ctx context.Context, previous *inventory, applied map[string]bool) error {
+	objects, err := parseManifest(previous.manifest, previous.namespace)
+	if err != nil {
+		return fmt.Errorf("reading inventory: %w", err)
+	}
+	// Delete in reverse order, so resources go before their namespaces.
+	for i := len(objects) - 1; i >= 0; i-- {
+		obj := objects[i]
+		if applied[obj.id()] {
+			continue
+		}
+		d.log.Info("Pruning", "resource", obj.String())
+		if err := d.delete(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *manifestDriver) delete(ctx context.Context, obj manifestObject) error {
+	obj.SetNamespace(obj.key.Namespace)
+	if err := d.tcc.DeleteObject(ctx, obj.Unstructured); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("deleting %s: %w", obj, err)
+	}
+	return nil
+}
+
+func (d *manifestDriver) inventoryKey(name string) client.ObjectKey {
+	return client.ObjectKey{Namespace: d.inventoryNamespace, Name: inventoryPrefix + name}
+}
+
+// getInventory returns the inventory of a package, or ErrNoInventory if
+// there is none.
+func (d *manifestDriver) getInventory(ctx context.Context, name string) (*inventory, error) {
+	cm := &corev1.ConfigMap{}
+	if err := d.tcc.GetObject(ctx, d.inventoryKey(name), cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("getting inventory of %s: %w", name, ErrNoInventory)
+		}
+		return nil, fmt.Errorf("getting inventory of %s: %w", name, err)
+	}
+	revision, err := strconv.Atoi(cm.Data[inventoryRevisionKey])
+	if err != nil {
+		return nil, fmt.Errorf("reading inventory of %s revision: %w", name, err)
+	}
+	return &inventory{
+		manifest:  cm.Data[inventoryManifestKey],
+		namespace: cm.Data[inventoryNamespaceKey],
+		revision:  revision,
+	}, nil
+}
+
+func (d *manifestDriver) saveInventory(ctx context.Context, name string, inv *inventory) error {
+	key := d.inventoryKey(name)
+	cm := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Data: map[string]string{
+			inventoryManifestKey:  inv.manifest,
+			inventoryNamespaceKey: inv.namespace,
+			inventoryRevisionKey:  strconv.Itoa(inv.revision),
+		},
+	}
+	if err := d.tcc.ApplyObject(ctx, cm, manifestFieldOwner); err != nil {
+		return fmt.Errorf("saving inventory of %s: %w", name, err)
+	}
+	return nil
+}
+
+// Plan renders the package's artifact, and compares the result with the
+// manifest last applied.
+func (d *manifestDriver) Plan(ctx context.Context,
+	name, namespace string, source api.PackageOCISource, values map[string]interface{},
+) (*Plan, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	manifest, err := d.render(ctx, source, values)
+	if err != nil {
+		return nil, fmt.Errorf("loading manifest %s: %w", name, err)
+	}
+	current := ""
+	inv, err := d.getInventory(ctx, name)
+	if err != nil && !errors.Is(err, ErrNoInventory) {
+		return nil, err
+	}
+	if inv != nil {
+		current = inv.manifest
+	}
+	changes, err := diffManifests(current, manifest, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("planning manifest %s: %w", name, err)
+	}
+	images, err := manifestImages(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("planning manifest %s: %w", name, err)
+	}
+	return &Plan{Changes: changes, Manifest: manifest, Images: images}, nil
+}
+
+// Rollback does nothing, as applying a manifest doesn't leave a failed
+// revision to roll back from.
+func (d *manifestDriver) Rollback(_ context.Context, _ string, _ api.InstallOptions) (bool, error) {
+	return false, nil
+}
+
+// RollbackTo fails, as the manifest driver only keeps the last revision.
+func (d *manifestDriver) RollbackTo(_ context.Context, name string, revision int, _ api.InstallOptions) (int, error) {
+	return 0, failure.Errorf(api.FailureReasonRollbackFailed, "rolling back %s to revision %d: earlier revisions aren't kept by the manifest driver", name, revision)
+}
+
+// NotReady checks the readiness of the workloads last applied.
+func (d *manifestDriver) NotReady(ctx context.Context, name string) ([]string, error) {
+	inv, err := d.getInventory(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	notReady, err := notReady(ctx, d.tcc, inv.manifest, inv.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("checking readiness of %s: %w", name, err)
+	}
+	return notReady, nil
+}
+
+// Drift compares the resources last applied with their live state.
+func (d *manifestDriver) Drift(ctx context.Context, name string) ([]string, error) {
+	inv, err := d.getInventory(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	drifted, err := drifted(ctx, d.tcc, inv.manifest, inv.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("checking drift of %s: %w", name, err)
+	}
+	return drifted, nil
+}
+
+// Uninstall deletes the resources last applied, and then the inventory.
+func (d *manifestDriver) Uninstall(ctx context.Context, name string) (err error) {
+	defer func() { err = failure.Wrap(api.FailureReasonUninstallFailed, err) }()
+	inv, err := d.getInventory(ctx, name)
+	if err != nil {
+		if errors.Is(err, ErrNoInventory) {
+			return nil
+		}
+		return err
+	}
+	if err := d.prune(ctx, inv, nil); err != nil {
+		return fmt.Errorf("uninstalling %s: %w", name, err)
+	}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: d.inventoryKey(name).Name, Namespace: d.inventoryNamespace}}
+	if err := d.tcc.DeleteObject(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("deleting inventory of %s: %w", name, err)
+	}
+	return nil
+}
+
+// IsConfigChanged is always false, as packages installed from manifests take
+// no configuration.
+func (d *manifestDriver) IsConfigChanged(_ context.Context, _ string, _ map[string]interface{}) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,344 @@
+package driver
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+	artifactmocks "github.com/aws/eks-anywhere-packages/pkg/artifacts/mocks"
+	"github.com/aws/eks-anywhere-packages/pkg/authenticator/mocks"
+)
+
+const testManifest = `---
+# Source: addon/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+# Source: addon/clusterrole.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: addon
+`
+
+func givenArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, archive.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := archive.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func givenManifestDriver(t *testing.T) (*manifestDriver, *artifactmocks.MockPuller, *mocks.MockTargetClusterClient) {
+	ctrl := gomock.NewController(t)
+	puller := artifactmocks.NewMockPuller(ctrl)
+	tcc := mocks.NewMockTargetClusterClient(ctrl)
+	d := NewManifest(logr.Discard(), puller, tcc)
+	d.inventoryNamespace = "default"
+	d.clusterName = "billy"
+	return d, puller, tcc
+}
+
+func givenInventory(manifest, namespace, revision string) func(context.Context, client.ObjectKey, client.Object) error {
+	return func(_ context.Context, key client.ObjectKey, object client.Object) error {
+		if key != (client.ObjectKey{Namespace: "default", Name: "eksa-packages.inventory.addon"}) {
+			return fmt.Errorf("unexpected key %s", key)
+		}
+		object.(*corev1.ConfigMap).Data = map[string]string{
+			inventoryManifestKey:  manifest,
+			inventoryNamespaceKey: namespace,
+			inventoryRevisionKey:  revision,
+		}
+		return nil
+	}
+}
+
+var notFound = apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "eksa-packages.inventory.addon")
+
+func TestRenderManifest(t *testing.T) {
+	t.Run("returns a plain manifest as is", func(t *testing.T) {
+		manifest, err := renderManifest([]byte(testManifest))
+		require.NoError(t, err)
+		assert.Equal(t, testManifest, manifest)
+	})
+
+	t.Run("concatenates the manifests of an archive in order", func(t *testing.T) {
+		data := givenArchive(t, map[string]string{
+			"./b.yaml":     "kind: B",
+			"a/a.yml":      "kind: A\n",
+			"README.md":    "# not a manifest",
+			"c/values.txt": "ignored",
+		})
+		manifest, err := renderManifest(data)
+		require.NoError(t, err)
+		assert.Equal(t, "---\n# Source: a/a.yml\nkind: A\n---\n# Source: b.yaml\nkind: B\n", manifest)
+	})
+
+	t.Run("builds the kustomization of an archive", func(t *testing.T) {
+		data := givenArchive(t, map[string]string{
+			"kustomization.yaml": "namePrefix: prod-\nresources:\n- base/configmap.yaml\n",
+			"base/configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n" +
+				"data:\n  key: value\n",
+		})
+		manifest, err := renderManifest(data)
+		require.NoError(t, err)
+		assert.Equal(t, "apiVersion: v1\ndata:\n  key: value\nkind: ConfigMap\nmetadata:\n  name: prod-config\n", manifest)
+	})
+
+	t.Run("fails for an invalid kustomization", func(t *testing.T) {
+		data := givenArchive(t, map[string]string{"kustomization.yaml": "resources:\n- missing.yaml\n"})
+		_, err := renderManifest(data)
+		assert.ErrorContains(t, err, "building kustomization")
+	})
+
+	t.Run("fails for a corrupt archive", func(t *testing.T) {
+		_, err := renderManifest([]byte{0x1f, 0x8b, 0x00})
+		assert.ErrorContains(t, err, "reading archive")
+	})
+}
+
+func TestManifestDriver_Install(t *testing.T) {
+	source := api.PackageOCISource{Registry: "public.ecr.aws/eks-anywhere", Repository: "addon", Digest: "sha256:deadbeef", Version: "v1"}
+
+	t.Run("applies the manifest and records it", func(t *testing.T) {
+		d, puller, tcc := givenManifestDriver(t)
+		puller.EXPECT().Pull(ctx, "public.ecr.aws/eks-anywhere/addon@sha256:deadbeef", "billy").Return([]byte(testManifest), nil)
+		tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.Any()).Return(notFound)
+		var applied []string
+		tcc.EXPECT().ApplyObject(ctx, gomock.Any(), manifestFieldOwner).
+			DoAndReturn(func(_ context.Context, object client.Object, _ string) error {
+				applied = append(applied, object.GetObjectKind().GroupVersionKind().Kind+" "+client.ObjectKeyFromObject(object).String())
+				return nil
+			}).Times(4)
+
+		revision, err := d.Install(ctx, "addon", "ns", true, source, map[string]interface{}{}, api.InstallOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, revision)
+		assert.Equal(t, []string{
+			"Namespace /ns",
+			"ConfigMap ns/config",
+			"ClusterRole /addon",
+			"ConfigMap default/eksa-packages.inventory.addon",
+		}, applied)
+	})
+
+	t.Run("prunes resources no longer in the manifest", func(t *testing.T) {
+		d, puller, tcc := givenManifestDriver(t)
+		previous := testManifest + `---
+# Source: addon/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: old
+`
+		puller.EXPECT().Pull(ctx, gomock.Any(), "billy").Return([]byte(testManifest), nil)
+		tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.Any()).DoAndReturn(givenInventory(previous, "ns", "4"))
+		tcc.EXPECT().ApplyObject(ctx, gomock.Any(), manifestFieldOwner).Return(nil).Times(2)
+		tcc.EXPECT().DeleteObject(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, object client.Object) error {
+				assert.Equal(t, "Service", object.GetObjectKind().GroupVersionKind().Kind)
+				assert.Equal(t, client.ObjectKey{Namespace: "ns", Name: "old"}, client.ObjectKeyFromObject(object))
+				return nil
+			})
+		tcc.EXPECT().ApplyObject(ctx, gomock.Any(), manifestFieldOwner).
+			DoAndReturn(func(_ context.Context, object client.Object, _ string) error {
+				cm := object.(*corev1.ConfigMap)
+				assert.Equal(t, testManifest, cm.Data[inventoryManifestKey])
+				assert.Equal(t, "5", cm.Data[inventoryRevisionKey])
+				return nil
+			})
+
+		revision, err := d.Install(ctx, "addon", "ns", false, source, map[string]interface{}{}, api.InstallOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 5, revision)
+	})
+
+	t.Run("prunes resources by their API group", func(t *testing.T) {
+		d, puller, tcc := givenManifestDriver(t)
+		certificate := `---
+# Source: addon/certificate.yaml
+apiVersion: %s
+kind: Certificate
+metadata:
+  name: addon
+`
+		previous := testManifest + fmt.Sprintf(certificate, "cert-manager.io/v1alpha2")
+		manifest := testManifest + fmt.Sprintf(certificate, "acme.example.com/v1")
+		puller.EXPECT().Pull(ctx, gomock.Any(), "billy").Return([]byte(manifest), nil)
+		tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.Any()).DoAndReturn(givenInventory(previous, "ns", "1"))
+		tcc.EXPECT().ApplyObject(ctx, gomock.Any(), manifestFieldOwner).Return(nil).Times(4)
+		tcc.EXPECT().DeleteObject(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, object client.Object) error {
+				assert.Equal(t, "cert-manager.io/v1alpha2, Kind=Certificate", object.GetObjectKind().GroupVersionKind().String())
+				assert.Equal(t, client.ObjectKey{Namespace: "ns", Name: "addon"}, client.ObjectKeyFromObject(object))
+				return nil
+			})
+
+		_, err := d.Install(ctx, "addon", "ns", false, source, map[string]interface{}{}, api.InstallOptions{})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("keeps resources moving to another version of their API", func(t *testing.T) {
+		d, puller, tcc := givenManifestDriver(t)
+		certificate := `---
+# Source: addon/certificate.yaml
+apiVersion: %s
+kind: Certificate
+metadata:
+  name: addon
+`
+		previous := testManifest + fmt.Sprintf(certificate, "cert-manager.io/v1alpha2")
+		manifest := testManifest + fmt.Sprintf(certificate, "cert-manager.io/v1")
+		puller.EXPECT().Pull(ctx, gomock.Any(), "billy").Return([]byte(manifest), nil)
+		tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.Any()).DoAndReturn(givenInventory(previous, "ns", "1"))
+		tcc.EXPECT().ApplyObject(ctx, gomock.Any(), manifestFieldOwner).Return(nil).Times(4)
+
+		_, err := d.Install(ctx, "addon", "ns", false, source, map[string]interface{}{}, api.InstallOptions{})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("fails when the artifact can't be pulled", func(t *testing.T) {
+		d, puller, _ := givenManifestDriver(t)
+		puller.EXPECT().Pull(ctx, gomock.Any(), "billy").Return(nil, fmt.Errorf("boom"))
+
+		_, err := d.Install(ctx, "addon", "ns", false, source, map[string]interface{}{}, api.InstallOptions{})
+
+		assert.EqualError(t, err, "loading manifest addon: pulling public.ecr.aws/eks-anywhere/addon@sha256:deadbeef: boom")
+	})
+
+	t.Run("fails when a resource can't be applied", func(t *testing.T) {
+		d, puller, tcc := givenManifestDriver(t)
+		puller.EXPECT().Pull(ctx, gomock.Any(), "billy").Return([]byte(testManifest), nil)
+		tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.Any()).Return(notFound)
+		tcc.EXPECT().ApplyObject(ctx, gomock.Any(), manifestFieldOwner).Return(fmt.Errorf("boom"))
+
+		_, err := d.Install(ctx, "addon", "ns", false, source, map[string]interface{}{}, api.InstallOptions{})
+
+		assert.EqualError(t, err, "applying ConfigMap ns/config: boom")
+	})
+}
+
+func TestManifestDriver_Uninstall(t *testing.T) {
+	t.Run("deletes the resources and the inventory", func(t *testing.T) {
+		d, _, tcc := givenManifestDriver(t)
+		tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.Any()).DoAndReturn(givenInventory(testManifest, "ns", "1"))
+		var deleted []string
+		tcc.EXPECT().DeleteObject(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, object client.Object) error {
+				deleted = append(deleted, client.ObjectKeyFromObject(object).String())
+				return nil
+			}).Times(3)
+
+		err := d.Uninstall(ctx, "addon")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/addon", "ns/config", "default/eksa-packages.inventory.addon"}, deleted)
+	})
+
+	t.Run("ignores resources already deleted", func(t *testing.T) {
+		d, _, tcc := givenManifestDriver(t)
+		tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.Any()).DoAndReturn(givenInventory(testManifest, "ns", "1"))
+		tcc.EXPECT().DeleteObject(ctx, gomock.Any()).Return(notFound).Times(3)
+
+		assert.NoError(t, d.Uninstall(ctx, "addon"))
+	})
+
+	t.Run("does nothing without an inventory", func(t *testing.T) {
+		d, _, tcc := givenManifestDriver(t)
+		tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.Any()).Return(notFound)
+
+		assert.NoError(t, d.Uninstall(ctx, "addon"))
+	})
+}
+
+func TestManifestDriver_NotReady(t *testing.T) {
+	t.Run("checks the resources last applied", func(t *testing.T) {
+		d, _, tcc := givenManifestDriver(t)
+		manifest := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n"
+		tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(givenInventory(manifest, "ns", "1"))
+		tcc.EXPECT().GetObject(ctx, client.ObjectKey{Namespace: "ns", Name: "web"}, gomock.AssignableToTypeOf(&unstructured.Unstructured{})).
+			Return(apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "web"))
+
+		notReady, err := d.NotReady(ctx, "addon")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Deployment ns/web: not found"}, notReady)
+	})
+
+	t.Run("fails without an inventory", func(t *testing.T) {
+		d, _, tcc := givenManifestDriver(t)
+		tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.Any()).Return(notFound)
+
+		_, err := d.NotReady(ctx, "addon")
+
+		assert.ErrorIs(t, err, ErrNoInventory)
+	})
+}
+
+func TestManifestDriver_Plan(t *testing.T) {
+	d, puller, tcc := givenManifestDriver(t)
+	source := api.PackageOCISource{Registry: "public.ecr.aws/eks-anywhere", Repository: "addon", Version: "v2"}
+	puller.EXPECT().Pull(ctx, "public.ecr.aws/eks-anywhere/addon:v2", "billy").Return([]byte(testManifest), nil)
+	tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.Any()).Return(notFound)
+
+	plan, err := d.Plan(ctx, "addon", "ns", source, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ConfigMap ns/config: added", "ClusterRole addon: added"}, plan.Changes)
+	assert.Equal(t, testManifest, plan.Manifest)
+	assert.Empty(t, plan.Images)
+}
+
+func TestManifestDriver_PlanOverridesImageRegistry(t *testing.T) {
+	d, puller, tcc := givenManifestDriver(t)
+	source := api.PackageOCISource{Registry: "public.ecr.aws/eks-anywhere", Repository: "addon", Version: "v2"}
+	manifest := "---\napiVersion: v1\nkind: Pod\nmetadata:\n  name: addon\n" +
+		"spec:\n  containers:\n  - name: addon\n    image: public.ecr.aws/addon@sha256:1111\n"
+	puller.EXPECT().Pull(ctx, "public.ecr.aws/eks-anywhere/addon:v2", "billy").Return([]byte(manifest), nil)
+	tcc.EXPECT().GetObject(ctx, gomock.Any(), gomock.Any()).Return(notFound)
+
+	plan, err := d.Plan(ctx, "addon", "ns", source, map[string]interface{}{sourceRegistryValue: "mirror.example.com"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mirror.example.com/addon@sha256:1111"}, plan.Images)
+}
+
+func TestManifestDriver_Rollback(t *testing.T) {
+	d, _, _ := givenManifestDriver(t)
+
+	rolledBack, err := d.Rollback(ctx, "addon", api.InstallOptions{})
+	assert.NoError(t, err)
+	assert.False(t, rolledBack)
+
+	_, err = d.RollbackTo(ctx, "addon", 1, api.InstallOptions{})
+	assert.ErrorContains(t, err, "earlier revisions aren't kept by the manifest driver")
+}
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	v1alpha1 "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+	driver "github.com/aws/eks-anywhere-packages/pkg/driver"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,6 +36,21 @@ func (m *MockPackageDriver) EXPECT() *MockPackageDriverMockRecorder {
 	return m.recorder
 }
 
+// Drift mocks base method.
+func (m *MockPackageDriver) Drift(ctx context.Context, name string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drift", ctx, name)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Drift indicates an expected call of Drift.
+func (mr *MockPackageDriverMockRecorder) Drift(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drift", reflect.TypeOf((*MockPackageDriver)(nil).Drift), ctx, name)
+}
+
 // Initialize mocks base method.
 func (m *MockPackageDriver) Initialize(ctx context.Context, clusterName string) error {
 	m.ctrl.T.Helper()
@@ -50,17 +66,18 @@ func (mr *MockPackageDriverMockRecorder) Initialize(ctx, clusterName interface{}
 }
 
 // Install mocks base method.
-func (m *MockPackageDriver) Install(ctx context.Context, name, namespace string, createNamespace bool, source v1alpha1.PackageOCISource, values map[string]interface{}) error {
+func (m *MockPackageDriver) Install(ctx context.Context, name, namespace string, createNamespace bool, source v1alpha1.PackageOCISource, values map[string]interface{}, options v1alpha1.InstallOptions) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Install", ctx, name, namespace, createNamespace, source, values)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Install", ctx, name, namespace, createNamespace, source, values, options)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Install indicates an expected call of Install.
-func (mr *MockPackageDriverMockRecorder) Install(ctx, name, namespace, createNamespace, source, values interface{}) *gomock.Call {
+func (mr *MockPackageDriverMockRecorder) Install(ctx, name, namespace, createNamespace, source, values, options interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Install", reflect.TypeOf((*MockPackageDriver)(nil).Install), ctx, name, namespace, createNamespace, source, values)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Install", reflect.TypeOf((*MockPackageDriver)(nil).Install), ctx, name, namespace, createNamespace, source, values, options)
 }
 
 // IsConfigChanged mocks base method.
@@ -78,6 +95,66 @@ func (mr *MockPackageDriverMockRecorder) IsConfigChanged(ctx, name, values inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsConfigChanged", reflect.TypeOf((*MockPackageDriver)(nil).IsConfigChanged), ctx, name, values)
 }
 
+// NotReady mocks base method.
+func (m *MockPackageDriver) NotReady(ctx context.Context, name string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotReady", ctx, name)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NotReady indicates an expected call of NotReady.
+func (mr *MockPackageDriverMockRecorder) NotReady(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotReady", reflect.TypeOf((*MockPackageDriver)(nil).NotReady), ctx, name)
+}
+
+// Plan mocks base method.
+func (m *MockPackageDriver) Plan(ctx context.Context, name, namespace string, source v1alpha1.PackageOCISource, values map[string]interface{}) (*driver.Plan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Plan", ctx, name, namespace, source, values)
+	ret0, _ := ret[0].(*driver.Plan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Plan indicates an expected call of Plan.
+func (mr *MockPackageDriverMockRecorder) Plan(ctx, name, namespace, source, values interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Plan", reflect.TypeOf((*MockPackageDriver)(nil).Plan), ctx, name, namespace, source, values)
+}
+
+// Rollback mocks base method.
+func (m *MockPackageDriver) Rollback(ctx context.Context, name string, options v1alpha1.InstallOptions) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", ctx, name, options)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockPackageDriverMockRecorder) Rollback(ctx, name, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockPackageDriver)(nil).Rollback), ctx, name, options)
+}
+
+// RollbackTo mocks base method.
+func (m *MockPackageDriver) RollbackTo(ctx context.Context, name string, revision int, options v1alpha1.InstallOptions) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackTo", ctx, name, revision, options)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RollbackTo indicates an expected call of RollbackTo.
+func (mr *MockPackageDriverMockRecorder) RollbackTo(ctx, name, revision, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackTo", reflect.TypeOf((*MockPackageDriver)(nil).RollbackTo), ctx, name, revision, options)
+}
+
 // Uninstall mocks base method.
 func (m *MockPackageDriver) Uninstall(ctx context.Context, name string) error {
 	m.ctrl.T.Helper()
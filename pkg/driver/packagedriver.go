@@ -2,10 +2,19 @@ package driver
 
 import (
 	"context"
+	"errors"
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
 )
 
+// ErrRevisionNotKept is returned when rolling back to a revision of a release
+// which is no longer kept.
+var ErrRevisionNotKept = errors.New("revision is no longer kept")
+
+// ErrRolledBack is returned when an install or upgrade fails and the driver
+// has already rolled the package back to its previous release.
+var ErrRolledBack = errors.New("release was rolled back")
+
 //go:generate mockgen -source packagedriver.go -destination=mocks/packagedriver.go -package=mocks PackageDriver
 
 // PackageDriver is an interface for converting a CRD to a series of Kubernetes
@@ -17,8 +26,29 @@ type PackageDriver interface {
 	// Initialize the package driver
 	Initialize(ctx context.Context, clusterName string) error
 
-	// Install or upgrade an package.
-	Install(ctx context.Context, name, namespace string, createNamespace bool, source api.PackageOCISource, values map[string]interface{}) error
+	// Install or upgrade an package, returning the revision of its release.
+	Install(ctx context.Context, name, namespace string, createNamespace bool, source api.PackageOCISource, values map[string]interface{}, options api.InstallOptions) (int, error)
+
+	// Plan the install or upgrade of a package without applying it,
+	// describing the changes it would make and the images it would run.
+	// The values aren't modified.
+	Plan(ctx context.Context, name, namespace string, source api.PackageOCISource, values map[string]interface{}) (*Plan, error)
+
+	// Rollback a package to its previous release if its last upgrade
+	// failed. Returns true if a rollback was performed.
+	Rollback(ctx context.Context, name string, options api.InstallOptions) (bool, error)
+
+	// RollbackTo rolls a package back to an earlier revision of its
+	// release, returning the new revision.
+	RollbackTo(ctx context.Context, name string, revision int, options api.InstallOptions) (int, error)
+
+	// NotReady returns a description of each of the package's workloads
+	// which isn't ready yet, or nothing once they all are.
+	NotReady(ctx context.Context, name string) ([]string, error)
+
+	// Drift returns a description of each of the package's resources whose
+	// live state no longer matches the release.
+	Drift(ctx context.Context, name string) ([]string, error)
 
 	// Uninstall an package.
 	Uninstall(ctx context.Context, name string) error
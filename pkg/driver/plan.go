@@ -0,0 +1,124 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+// Plan describes the changes installing or upgrading a package would make.
+type Plan struct {
+	// Changes summarizes each resource which would be added, changed or
+	// removed.
+	Changes []string
+
+	// Manifest is the manifest which would be applied.
+	Manifest string
+
+	// Images are those of the containers in the manifest.
+	Images []string
+}
+
+// Plan renders a chart with a dry run of the install or upgrade of its
+// release, and compares the result with the release's current manifest.
+func (d *helmDriver) Plan(ctx context.Context,
+	name, namespace string, source api.PackageOCISource, values map[string]interface{},
+) (*Plan, error) {
+	install := action.NewInstall(d.cfg)
+	install.Version = source.Version
+	install.ReleaseName = name
+
+	helmChart, err := d.getChart(ctx, install, source)
+	if err != nil {
+		return nil, fmt.Errorf("loading helm chart %s: %w", name, err)
+	}
+	namespace = chartNamespace(helmChart, namespace)
+	values = d.withSecretValues(ctx, namespace, values)
+
+	current := ""
+	get := action.NewGet(d.cfg)
+	rel, err := get.Run(name)
+	if err != nil {
+		if !errors.Is(err, driver.ErrReleaseNotFound) {
+			return nil, fmt.Errorf("getting helm release %s: %w", name, err)
+		}
+		install.Namespace = namespace
+		install.DryRun = true
+		rel, err = install.RunWithContext(ctx, helmChart, values)
+		if err != nil {
+			return nil, fmt.Errorf("planning install of helm chart %s: %w", name, err)
+		}
+	} else {
+		current = rel.Manifest
+		upgrade := action.NewUpgrade(d.cfg)
+		upgrade.DryRun = true
+		rel, err = upgrade.RunWithContext(ctx, name, helmChart, values)
+		if err != nil {
+			return nil, fmt.Errorf("planning upgrade of helm release %s: %w", name, err)
+		}
+	}
+
+	changes, err := diffManifests(current, rel.Manifest, rel.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("planning helm release %s: %w", name, err)
+	}
+	images, err := manifestImages(releaseManifest(rel))
+	if err != nil {
+		return nil, fmt.Errorf("planning helm release %s: %w", name, err)
+	}
+	return &Plan{Changes: changes, Manifest: rel.Manifest, Images: images}, nil
+}
+
+// withSecretValues returns a copy of values with imagePullSecrets, leaving
+// the caller's values as they were.
+func (d *helmDriver) withSecretValues(ctx context.Context, namespace string, values map[string]interface{}) map[string]interface{} {
+	rendered := make(map[string]interface{}, len(values))
+	for key, val := range values {
+		rendered[key] = val
+	}
+	d.addSecretValues(ctx, namespace, rendered)
+	return rendered
+}
+
+// diffManifests returns a description of each resource which differs between
+// the current and planned manifests.
+func diffManifests(current, planned, namespace string) ([]string, error) {
+	currentObjects, err := parseManifest(current, namespace)
+	if err != nil {
+		return nil, err
+	}
+	plannedObjects, err := parseManifest(planned, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]manifestObject, len(currentObjects))
+	for _, obj := range currentObjects {
+		existing[obj.id()] = obj
+	}
+	var changes []string
+	for _, obj := range plannedObjects {
+		cur, ok := existing[obj.id()]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s: added", obj))
+			continue
+		}
+		delete(existing, obj.id())
+		if path := diffObject(obj.Object, cur.Object); path != "" {
+			changes = append(changes, fmt.Sprintf("%s: %s changed", obj, path))
+		} else if path := diffObject(cur.Object, obj.Object); path != "" {
+			changes = append(changes, fmt.Sprintf("%s: %s removed", obj, path))
+		}
+	}
+	for _, obj := range currentObjects {
+		if _, ok := existing[obj.id()]; ok {
+			changes = append(changes, fmt.Sprintf("%s: removed", obj))
+		}
+	}
+	return changes, nil
+}
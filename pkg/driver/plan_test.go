@@ -0,0 +1,124 @@
+package driver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/eks-anywhere-packages/pkg/authenticator/mocks"
+)
+
+func TestWithSecretValues(t *testing.T) {
+	t.Run("adds the image pull secrets to a copy of the values", func(t *testing.T) {
+		helm, err := givenInitializedHelmDriver(t)
+		require.NoError(t, err)
+		secrets := []interface{}{map[string]interface{}{"name": "ecr-token"}}
+		helm.secretAuth.(*mocks.MockAuthenticator).EXPECT().GetSecretValues(ctx, "ns").
+			Return(map[string]interface{}{"imagePullSecrets": secrets}, nil)
+		values := map[string]interface{}{"greeting": "hello"}
+
+		rendered := helm.withSecretValues(ctx, "ns", values)
+
+		assert.Equal(t, map[string]interface{}{"greeting": "hello", "imagePullSecrets": secrets}, rendered)
+		assert.Equal(t, map[string]interface{}{"greeting": "hello"}, values)
+	})
+}
+
+func TestDiffManifests(t *testing.T) {
+	current := `---
+# Source: hello/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: hello
+data:
+  greeting: hello
+  farewell: goodbye
+---
+# Source: hello/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: hello
+spec:
+  ports:
+  - port: 80
+---
+# Source: hello/templates/clusterrole.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: hello
+`
+
+	t.Run("reports nothing for the same manifest", func(t *testing.T) {
+		changes, err := diffManifests(current, current, "ns")
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("reports added, changed and removed resources", func(t *testing.T) {
+		planned := `---
+# Source: hello/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: hello
+data:
+  greeting: hi
+  farewell: goodbye
+---
+# Source: hello/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: hello
+spec: {}
+---
+# Source: hello/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: hello
+  namespace: other
+`
+		changes, err := diffManifests(current, planned, "ns")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"ConfigMap ns/hello: data.greeting changed",
+			"Service ns/hello: spec.ports removed",
+			"Deployment other/hello: added",
+			"ClusterRole hello: removed",
+		}, changes)
+	})
+
+	t.Run("reports every resource of a new release as added", func(t *testing.T) {
+		changes, err := diffManifests("", current, "ns")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"ConfigMap ns/hello: added",
+			"Service ns/hello: added",
+			"ClusterRole hello: added",
+		}, changes)
+	})
+
+	t.Run("tells resources of different API groups apart", func(t *testing.T) {
+		certificate := `---
+# Source: hello/templates/certificate.yaml
+apiVersion: %s
+kind: Certificate
+metadata:
+  name: hello
+`
+		changes, err := diffManifests(fmt.Sprintf(certificate, "cert-manager.io/v1"), fmt.Sprintf(certificate, "acme.example.com/v1"), "ns")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Certificate ns/hello: added", "Certificate ns/hello: removed"}, changes)
+	})
+
+	t.Run("fails for an invalid manifest", func(t *testing.T) {
+		_, err := diffManifests(current, "---\n# Source: bad.yaml\nkind: [\n", "ns")
+		assert.ErrorContains(t, err, "parsing manifest")
+	})
+}
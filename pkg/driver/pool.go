@@ -0,0 +1,85 @@
+package driver
+
+import (
+	"fmt"
+	"sync"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+// Factory creates the driver installing packages with a kind of source on a
+// cluster.
+type Factory func(clusterName string, kind api.DriverEnum) (PackageDriver, error)
+
+// Pool keeps the drivers of each cluster, so that packages on different
+// clusters can be reconciled concurrently.
+//
+// Drivers hold the state of the cluster they were last initialized for, so
+// the drivers of a cluster are only handed to one reconcile at a time.
+type Pool struct {
+	newDriver Factory
+
+	mu       sync.Mutex
+	clusters map[string]*ClusterDrivers
+}
+
+// ClusterDrivers are the drivers of a cluster, held by a reconcile from
+// Pool.TryAcquire until Release.
+type ClusterDrivers struct {
+	clusterName string
+	newDriver   Factory
+
+	mu      sync.Mutex
+	drivers map[api.DriverEnum]PackageDriver
+}
+
+func NewPool(newDriver Factory) *Pool {
+	return &Pool{
+		newDriver: newDriver,
+		clusters:  make(map[string]*ClusterDrivers),
+	}
+}
+
+// TryAcquire returns the drivers of a cluster, or false if another reconcile
+// holds them, so that workers aren't blocked waiting for a busy cluster. They
+// must be released once done with.
+func (p *Pool) TryAcquire(clusterName string) (*ClusterDrivers, bool) {
+	p.mu.Lock()
+	cluster, ok := p.clusters[clusterName]
+	if !ok {
+		cluster = &ClusterDrivers{
+			clusterName: clusterName,
+			newDriver:   p.newDriver,
+			drivers:     make(map[api.DriverEnum]PackageDriver),
+		}
+		p.clusters[clusterName] = cluster
+	}
+	p.mu.Unlock()
+
+	if !cluster.mu.TryLock() {
+		return nil, false
+	}
+	return cluster, true
+}
+
+// Get returns the driver installing packages with a kind of source, which is
+// the Helm driver unless another is given.
+func (c *ClusterDrivers) Get(kind api.DriverEnum) (PackageDriver, error) {
+	if kind == "" {
+		kind = api.DriverHelm
+	}
+	if d, ok := c.drivers[kind]; ok {
+		return d, nil
+	}
+	d, err := c.newDriver(c.clusterName, kind)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s driver for cluster %q: %w", kind, c.clusterName, err)
+	}
+	c.drivers[kind] = d
+	return d, nil
+}
+
+// Release the drivers for other reconciles of the cluster.
+func (c *ClusterDrivers) Release() {
+	c.mu.Unlock()
+}
@@ -0,0 +1,80 @@
+package driver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+func givenPool() (*Pool, *[]string) {
+	var created []string
+	return NewPool(func(clusterName string, kind api.DriverEnum) (PackageDriver, error) {
+		if clusterName == "broken" {
+			return nil, fmt.Errorf("boom")
+		}
+		created = append(created, clusterName+"/"+string(kind))
+		return NewManifest(logr.Discard(), nil, nil), nil
+	}), &created
+}
+
+func givenDriver(t *testing.T, drivers *ClusterDrivers, kind api.DriverEnum) PackageDriver {
+	d, err := drivers.Get(kind)
+	assert.NoError(t, err)
+	return d
+}
+
+func givenAcquired(t *testing.T, pool *Pool, clusterName string) *ClusterDrivers {
+	drivers, ok := pool.TryAcquire(clusterName)
+	assert.True(t, ok, "drivers of %s not acquired", clusterName)
+	return drivers
+}
+
+func TestPool(t *testing.T) {
+	t.Run("creates each driver of a cluster once", func(t *testing.T) {
+		pool, created := givenPool()
+
+		billy := givenAcquired(t, pool, "billy")
+		helm := givenDriver(t, billy, "")
+		assert.Same(t, helm, givenDriver(t, billy, api.DriverHelm))
+		manifest := givenDriver(t, billy, api.DriverManifest)
+		assert.NotSame(t, helm, manifest)
+		billy.Release()
+
+		billy = givenAcquired(t, pool, "billy")
+		assert.Same(t, helm, givenDriver(t, billy, api.DriverHelm))
+		billy.Release()
+
+		bob := givenAcquired(t, pool, "bob")
+		assert.NotSame(t, helm, givenDriver(t, bob, api.DriverHelm))
+		bob.Release()
+
+		assert.Equal(t, []string{"billy/helm", "billy/manifest", "bob/helm"}, *created)
+	})
+
+	t.Run("reports drivers failing to be created", func(t *testing.T) {
+		pool, created := givenPool()
+
+		broken := givenAcquired(t, pool, "broken")
+		defer broken.Release()
+		_, err := broken.Get(api.DriverHelm)
+		assert.EqualError(t, err, `creating helm driver for cluster "broken": boom`)
+		assert.Empty(t, *created)
+	})
+
+	t.Run("holds the drivers of a cluster for one reconcile at a time", func(t *testing.T) {
+		pool, _ := givenPool()
+
+		billy := givenAcquired(t, pool, "billy")
+		givenAcquired(t, pool, "bob").Release()
+
+		_, ok := pool.TryAcquire("billy")
+		assert.False(t, ok, "acquired drivers already held")
+
+		billy.Release()
+		givenAcquired(t, pool, "billy").Release()
+	})
+}
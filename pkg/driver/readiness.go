@@ -0,0 +1,57 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+
+	auth "github.com/aws/eks-anywhere-packages/pkg/authenticator"
+)
+
+// readinessKinds are the kinds of resource whose readiness is checked after
+// a package is installed.
+var readinessKinds = map[schema.GroupKind]bool{
+	{Group: "apps", Kind: "Deployment"}:                               true,
+	{Group: "apps", Kind: "StatefulSet"}:                              true,
+	{Group: "apps", Kind: "DaemonSet"}:                                true,
+	{Group: "batch", Kind: "Job"}:                                     true,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}: true,
+}
+
+// notReady returns a description of each resource in the manifest which
+// isn't ready on the target cluster.
+func notReady(ctx context.Context, tcc auth.TargetClusterClient, manifest, namespace string) ([]string, error) {
+	objects, err := parseManifest(manifest, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var notReady []string
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		if !readinessKinds[gvk.GroupKind()] {
+			continue
+		}
+
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(gvk)
+		if err := tcc.GetObject(ctx, obj.key, live); err != nil {
+			if apierrors.IsNotFound(err) {
+				notReady = append(notReady, fmt.Sprintf("%s: not found", obj))
+				continue
+			}
+			return nil, fmt.Errorf("getting %s: %w", obj, err)
+		}
+		result, err := status.Compute(live)
+		if err != nil {
+			return nil, fmt.Errorf("computing status of %s: %w", obj, err)
+		}
+		if result.Status != status.CurrentStatus {
+			notReady = append(notReady, fmt.Sprintf("%s: %s", obj, result.Message))
+		}
+	}
+	return notReady, nil
+}
@@ -0,0 +1,54 @@
+// Package failure classifies errors by the reason they happened for, which
+// package and package bundle controller statuses report next to their detail.
+package failure
+
+import (
+	"errors"
+	"fmt"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+// Error is an error with the reason it happened for.
+type Error struct {
+	Reason api.FailureReasonEnum
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap gives an error a reason, unless it wraps an error which already has
+// one. The most specific reason is kept, so that a registry refusing
+// credentials while installing is reported as such rather than as a failed
+// install.
+func Wrap(reason api.FailureReasonEnum, err error) error {
+	if err == nil {
+		return nil
+	}
+	var failure *Error
+	if errors.As(err, &failure) {
+		return err
+	}
+	return &Error{Reason: reason, Err: err}
+}
+
+// Errorf formats an error with a reason, unless it wraps an error which
+// already has one.
+func Errorf(reason api.FailureReasonEnum, format string, args ...interface{}) error {
+	return Wrap(reason, fmt.Errorf(format, args...))
+}
+
+// Reason returns the reason of an error, or fallback if it has none.
+func Reason(err error, fallback api.FailureReasonEnum) api.FailureReasonEnum {
+	var failure *Error
+	if errors.As(err, &failure) {
+		return failure.Reason
+	}
+	return fallback
+}
@@ -0,0 +1,41 @@
+package failure_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+	"github.com/aws/eks-anywhere-packages/pkg/failure"
+)
+
+func TestWrap(t *testing.T) {
+	t.Run("gives errors a reason", func(t *testing.T) {
+		cause := fmt.Errorf("boom")
+		err := failure.Wrap(api.FailureReasonInstallFailed, cause)
+
+		assert.EqualError(t, err, "boom")
+		assert.True(t, errors.Is(err, cause))
+		assert.Equal(t, api.FailureReasonInstallFailed, failure.Reason(err, api.FailureReasonUnknown))
+	})
+
+	t.Run("keeps the most specific reason", func(t *testing.T) {
+		cause := failure.Errorf(api.FailureReasonRegistryAuthFailed, "pulling: %s", "unauthorized")
+		err := failure.Errorf(api.FailureReasonInstallFailed, "installing: %w", cause)
+
+		assert.EqualError(t, err, "installing: pulling: unauthorized")
+		assert.Equal(t, api.FailureReasonRegistryAuthFailed, failure.Reason(err, api.FailureReasonUnknown))
+	})
+
+	t.Run("ignores nil errors", func(t *testing.T) {
+		assert.NoError(t, failure.Wrap(api.FailureReasonInstallFailed, nil))
+	})
+}
+
+func TestReason(t *testing.T) {
+	assert.Equal(t, api.FailureReasonUnknown, failure.Reason(fmt.Errorf("boom"), api.FailureReasonUnknown))
+	assert.Equal(t, api.FailureReasonClusterUnreachable,
+		failure.Reason(fmt.Errorf("initializing: %w", failure.Wrap(api.FailureReasonClusterUnreachable, fmt.Errorf("boom"))), api.FailureReasonUnknown))
+}
@@ -0,0 +1,66 @@
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// parseSchedule parses a window's cron schedule, in UTC unless it specifies
+// a time zone.
+func parseSchedule(window api.MaintenanceWindow) (cron.Schedule, error) {
+	spec := window.Schedule
+	if !strings.HasPrefix(spec, "CRON_TZ=") && !strings.HasPrefix(spec, "TZ=") {
+		spec = "CRON_TZ=UTC " + spec
+	}
+	schedule, err := parser.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window schedule %q: %w", window.Schedule, err)
+	}
+	if window.Duration.Duration <= 0 {
+		return nil, fmt.Errorf("invalid maintenance window duration %s: must be positive", window.Duration.Duration)
+	}
+	return schedule, nil
+}
+
+// Validate returns an error if any of the windows is invalid.
+func Validate(windows []api.MaintenanceWindow) error {
+	for _, w := range windows {
+		if _, err := parseSchedule(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Next returns whether any of the windows is open at the given time and, if
+// not, when the next one opens, which is zero if none ever will. It is always
+// open when there are no windows.
+func Next(windows []api.MaintenanceWindow, now time.Time) (open bool, next time.Time, err error) {
+	if len(windows) == 0 {
+		return true, time.Time{}, nil
+	}
+	for _, w := range windows {
+		schedule, err := parseSchedule(w)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		// The window is open if it last opened less than its duration ago.
+		// Schedules which never fire return a zero time.
+		opened := schedule.Next(now.Add(-w.Duration.Duration))
+		if !opened.IsZero() && !opened.After(now) {
+			return true, time.Time{}, nil
+		}
+		opens := schedule.Next(now)
+		if !opens.IsZero() && (next.IsZero() || opens.Before(next)) {
+			next = opens
+		}
+	}
+	return false, next, nil
+}
@@ -0,0 +1,88 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+func givenWindow(schedule string, duration time.Duration) api.MaintenanceWindow {
+	return api.MaintenanceWindow{Schedule: schedule, Duration: metav1.Duration{Duration: duration}}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(nil))
+	assert.NoError(t, Validate([]api.MaintenanceWindow{
+		givenWindow("0 2 * * 6", time.Hour),
+		givenWindow("CRON_TZ=Europe/Paris @daily", time.Hour),
+	}))
+	assert.EqualError(t, Validate([]api.MaintenanceWindow{givenWindow("0 2 * *", time.Hour)}),
+		`invalid maintenance window schedule "0 2 * *": expected exactly 5 fields, found 4: [0 2 * *]`)
+	assert.EqualError(t, Validate([]api.MaintenanceWindow{givenWindow("0 2 * * 6", 0)}),
+		"invalid maintenance window duration 0s: must be positive")
+}
+
+func TestNext(t *testing.T) {
+	// A Saturday.
+	now := time.Date(2024, 6, 1, 3, 30, 0, 0, time.UTC)
+
+	t.Run("open without windows", func(t *testing.T) {
+		open, next, err := Next(nil, now)
+
+		assert.NoError(t, err)
+		assert.True(t, open)
+		assert.True(t, next.IsZero())
+	})
+
+	t.Run("open within a window", func(t *testing.T) {
+		open, _, err := Next([]api.MaintenanceWindow{givenWindow("0 2 * * 6", 2*time.Hour)}, now)
+
+		assert.NoError(t, err)
+		assert.True(t, open)
+	})
+
+	t.Run("closed at the end of a window", func(t *testing.T) {
+		open, next, err := Next([]api.MaintenanceWindow{givenWindow("30 2 * * 6", time.Hour)}, now)
+
+		assert.NoError(t, err)
+		assert.False(t, open)
+		assert.Equal(t, time.Date(2024, 6, 8, 2, 30, 0, 0, time.UTC), next)
+	})
+
+	t.Run("closed returns the earliest window", func(t *testing.T) {
+		open, next, err := Next([]api.MaintenanceWindow{
+			givenWindow("0 2 * * 6", time.Hour),
+			givenWindow("0 22 * * *", time.Hour),
+		}, now)
+
+		assert.NoError(t, err)
+		assert.False(t, open)
+		assert.Equal(t, time.Date(2024, 6, 1, 22, 0, 0, 0, time.UTC), next)
+	})
+
+	t.Run("honors time zones", func(t *testing.T) {
+		open, next, err := Next([]api.MaintenanceWindow{givenWindow("CRON_TZ=America/New_York 0 2 * * *", time.Hour)}, now)
+
+		assert.NoError(t, err)
+		assert.False(t, open)
+		assert.Equal(t, time.Date(2024, 6, 1, 6, 0, 0, 0, time.UTC), next.UTC())
+	})
+
+	t.Run("never opens for impossible schedules", func(t *testing.T) {
+		open, next, err := Next([]api.MaintenanceWindow{givenWindow("0 2 30 2 *", time.Hour)}, now)
+
+		assert.NoError(t, err)
+		assert.False(t, open)
+		assert.True(t, next.IsZero())
+	})
+
+	t.Run("errors for invalid windows", func(t *testing.T) {
+		_, _, err := Next([]api.MaintenanceWindow{givenWindow("bogus", time.Hour)}, now)
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,223 @@
+// Package metrics holds the Prometheus collectors of the package controller,
+// served by the controller-runtime metrics endpoint.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "eksa_packages"
+
+// Outcomes of operations.
+const (
+	ResultSucceeded = "succeeded"
+	ResultFailed    = "failed"
+)
+
+// Package operations.
+const (
+	OperationInstall   = "install"
+	OperationUpgrade   = "upgrade"
+	OperationUninstall = "uninstall"
+)
+
+// Results of checking the registry for the latest bundle.
+const (
+	BundleCheckFailed    = "failed"
+	BundleCheckNew       = "new"
+	BundleCheckUnchanged = "unchanged"
+)
+
+var (
+	packages = newPackageStates(prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "packages"),
+		"Number of packages by state and cluster.",
+		[]string{"state", "cluster"}, nil,
+	))
+
+	operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of package installs, upgrades and uninstalls by outcome.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 12),
+	}, []string{"operation", "result"})
+
+	helmErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "helm_errors_total",
+		Help:      "Number of failed helm operations by reason.",
+	}, []string{"reason"})
+
+	bundleChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bundle_checks_total",
+		Help:      "Number of checks of the registry for the latest bundle by cluster and result.",
+	}, []string{"cluster", "result"})
+
+	latestBundleAge = newSinceVec(prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "latest_bundle_age_seconds"),
+		"Seconds since the latest bundle of each cluster was found.",
+		[]string{"cluster"}, nil,
+	))
+
+	registryPullAge = newSinceVec(prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "registry_last_pull_age_seconds"),
+		"Seconds since the last successful pull from each registry.",
+		[]string{"registry"}, nil,
+	))
+
+	signatureFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "signature_verification_failures_total",
+		Help:      "Number of bundles whose signature failed verification.",
+	})
+
+	ecrCredentialRefreshes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ecr_credential_refreshes_total",
+		Help:      "Number of refreshes of the ECR credential by result.",
+	}, []string{"result"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		packages,
+		operationDuration,
+		helmErrors,
+		bundleChecks,
+		latestBundleAge,
+		registryPullAge,
+		signatureFailures,
+		ecrCredentialRefreshes,
+	)
+}
+
+// RecordPackage records the state of a package on a cluster.
+func RecordPackage(key, state, cluster string) {
+	packages.set(key, state, cluster)
+}
+
+// ForgetPackage stops counting a deleted package.
+func ForgetPackage(key string) {
+	packages.delete(key)
+}
+
+// ObserveOperation records how long an operation on a package took since it
+// started, and whether it failed.
+func ObserveOperation(operation string, started time.Time, err error) {
+	operationDuration.WithLabelValues(operation, result(err)).Observe(time.Since(started).Seconds())
+}
+
+// HelmError counts a failed helm operation.
+func HelmError(reason string) {
+	helmErrors.WithLabelValues(reason).Inc()
+}
+
+// BundleChecked counts a check of the registry for the latest bundle of a
+// cluster.
+func BundleChecked(cluster, result string) {
+	bundleChecks.WithLabelValues(cluster, result).Inc()
+}
+
+// LatestBundleFound records when the latest bundle of a cluster was found.
+func LatestBundleFound(cluster string, found time.Time) {
+	latestBundleAge.set(cluster, found)
+}
+
+// RegistryPulled records a successful pull from a registry.
+func RegistryPulled(registry string) {
+	registryPullAge.set(registry, time.Now())
+}
+
+// SignatureVerificationFailed counts a bundle failing signature verification.
+func SignatureVerificationFailed() {
+	signatureFailures.Inc()
+}
+
+// ECRCredentialRefreshed counts a refresh of the ECR credential.
+func ECRCredentialRefreshed(err error) {
+	ecrCredentialRefreshes.WithLabelValues(result(err)).Inc()
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultFailed
+	}
+	return ResultSucceeded
+}
+
+// packageStates counts packages by state and cluster when scraped, from the
+// last state recorded for each.
+type packageStates struct {
+	desc *prometheus.Desc
+
+	mu       sync.Mutex
+	packages map[string][2]string
+}
+
+func newPackageStates(desc *prometheus.Desc) *packageStates {
+	return &packageStates{desc: desc, packages: make(map[string][2]string)}
+}
+
+func (p *packageStates) set(key, state, cluster string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.packages[key] = [2]string{state, cluster}
+}
+
+func (p *packageStates) delete(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.packages, key)
+}
+
+func (p *packageStates) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.desc
+}
+
+func (p *packageStates) Collect(ch chan<- prometheus.Metric) {
+	p.mu.Lock()
+	counts := make(map[[2]string]int)
+	for _, labels := range p.packages {
+		counts[labels]++
+	}
+	p.mu.Unlock()
+	for labels, count := range counts {
+		ch <- prometheus.MustNewConstMetric(p.desc, prometheus.GaugeValue, float64(count), labels[0], labels[1])
+	}
+}
+
+// sinceVec reports the seconds since the time recorded for each label value,
+// as of when it is scraped.
+type sinceVec struct {
+	desc *prometheus.Desc
+
+	mu    sync.Mutex
+	times map[string]time.Time
+}
+
+func newSinceVec(desc *prometheus.Desc) *sinceVec {
+	return &sinceVec{desc: desc, times: make(map[string]time.Time)}
+}
+
+func (s *sinceVec) set(label string, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.times[label] = t
+}
+
+func (s *sinceVec) Describe(ch chan<- *prometheus.Desc) {
+	ch <- s.desc
+}
+
+func (s *sinceVec) Collect(ch chan<- prometheus.Metric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for label, t := range s.times {
+		ch <- prometheus.MustNewConstMetric(s.desc, prometheus.GaugeValue, time.Since(t).Seconds(), label)
+	}
+}
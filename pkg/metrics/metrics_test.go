@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageStates(t *testing.T) {
+	p := newPackageStates(prometheus.NewDesc("packages", "Number of packages.", []string{"state", "cluster"}, nil))
+	p.set("eksa-packages-billy/harbor", "installed", "billy")
+	p.set("eksa-packages-billy/hello", "installing", "billy")
+	p.set("eksa-packages-billy/hello", "installed", "billy")
+	p.set("eksa-packages-bob/hello", "installed", "bob")
+	p.set("eksa-packages-bob/harbor", "failed", "bob")
+	p.delete("eksa-packages-bob/harbor")
+
+	expected := `
+# HELP packages Number of packages.
+# TYPE packages gauge
+packages{cluster="billy",state="installed"} 2
+packages{cluster="bob",state="installed"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(p, strings.NewReader(expected)))
+}
+
+func TestSinceVec(t *testing.T) {
+	s := newSinceVec(prometheus.NewDesc("age", "Age.", []string{"cluster"}, nil))
+	s.set("billy", time.Now().Add(-time.Hour))
+	s.set("bob", time.Now())
+
+	assert.Equal(t, 2, testutil.CollectAndCount(s))
+	metrics, err := collect(s)
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Hour.Seconds(), metrics["billy"], 60)
+	assert.InDelta(t, 0, metrics["bob"], 60)
+}
+
+func TestCounters(t *testing.T) {
+	HelmError("chart")
+	HelmError("chart")
+	assert.Equal(t, 2.0, testutil.ToFloat64(helmErrors.WithLabelValues("chart")))
+
+	ECRCredentialRefreshed(nil)
+	ECRCredentialRefreshed(fmt.Errorf("boom"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(ecrCredentialRefreshes.WithLabelValues(ResultSucceeded)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(ecrCredentialRefreshes.WithLabelValues(ResultFailed)))
+
+	ObserveOperation(OperationInstall, time.Now(), fmt.Errorf("boom"))
+	assert.Equal(t, 1, testutil.CollectAndCount(operationDuration))
+}
+
+// collect returns the values of a collector's metrics by their only label.
+func collect(c prometheus.Collector) (map[string]float64, error) {
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(c); err != nil {
+		return nil, err
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			values[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,88 @@
+package packages
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+// planHash identifies the installation of a source with values, so a plan is
+// only approved for exactly what it was made from. The rendered manifest
+// isn't hashed, as charts may render differently on every run.
+func planHash(source api.PackageOCISource, values map[string]interface{}) (string, error) {
+	hash, err := shortHash(struct {
+		Source api.PackageOCISource   `json:"source"`
+		Values map[string]interface{} `json:"values"`
+	}{source, values})
+	if err != nil {
+		return "", fmt.Errorf("hashing plan: %w", err)
+	}
+	return hash, nil
+}
+
+// approved returns true if the installation of the source with values may go
+// ahead for a package requiring approval. Otherwise it is planned, and the
+// package waits for the plan to be approved.
+func (mc *ManagerContext) approved(values map[string]interface{}) bool {
+	hash, err := planHash(mc.Source, values)
+	if err != nil {
+		mc.fail(err, api.FailureReasonUnknown)
+		mc.RequeueAfter = retryLong
+		return false
+	}
+	if mc.Package.IsApproved(hash) {
+		mc.Log.Info("Plan approved", "name", mc.Package.Name, "hash", hash)
+		return true
+	}
+
+	plan, err := mc.PackageDriver.Plan(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, mc.Source, values)
+	if err != nil {
+		mc.Log.Error(err, "Planning failed")
+		mc.fail(err, api.FailureReasonInstallFailed)
+		mc.RequeueAfter = retryLong
+		return false
+	}
+	if len(plan.Changes) == 0 && mc.Package.Status.CurrentVersion == mc.Source.Version {
+		// Nothing would change, so there is nothing to approve.
+		return true
+	}
+
+	if previous := mc.Package.Status.Plan; previous == nil || previous.Hash != hash {
+		mc.Log.Info("Waiting for approval", "name", mc.Package.Name, "hash", hash, "changes", plan.Changes)
+		mc.event(corev1.EventTypeNormal, EventReasonPlanPending, "Plan %s to install %s: %s",
+			hash, mc.Source.Version, strings.Join(plan.Changes, "; "))
+	}
+	mc.Package.Status.Plan = &api.PackagePlan{
+		Hash:        hash,
+		FromVersion: mc.Package.Status.CurrentVersion,
+		ToVersion:   mc.Source.Version,
+		Changes:     plan.Changes,
+	}
+	mc.Package.Status.State = api.StatePendingApproval
+	mc.Package.Status.Detail = fmt.Sprintf("Waiting for approval: set the %s annotation to %s", api.ApproveAnnotation, hash)
+	mc.Package.Status.Reason = ""
+	mc.RequeueAfter = retryLong
+	return false
+}
+
+// processPendingApproval waits for the plan of a package to be approved, and
+// plans again if the source changes in the meantime. Changes of configuration
+// are caught when the approved plan is checked before installing.
+func processPendingApproval(mc *ManagerContext) bool {
+	plan := mc.Package.Status.Plan
+	if plan != nil && mc.Package.Status.Source == mc.Source && !mc.Package.IsApproved(plan.Hash) {
+		mc.RequeueAfter = retryLong
+		return false
+	}
+	if plan != nil && mc.Package.IsApproved(plan.Hash) {
+		mc.event(corev1.EventTypeNormal, EventReasonPlanApproved, "Plan %s approved", plan.Hash)
+	}
+	mc.Package.Status.State = api.StateInstalling
+	mc.Package.Status.Detail = ""
+	mc.Package.Status.Reason = ""
+	mc.RequeueAfter = retryNow
+	return true
+}
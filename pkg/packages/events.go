@@ -0,0 +1,28 @@
+package packages
+
+// Reasons of the events recorded against packages.
+const (
+	EventReasonInstalled              = "Installed"
+	EventReasonInstallFailed          = "InstallFailed"
+	EventReasonUpgradePending         = "UpgradePending"
+	EventReasonPlanPending            = "PlanPending"
+	EventReasonPlanApproved           = "PlanApproved"
+	EventReasonUpgrading              = "Upgrading"
+	EventReasonUpgraded               = "Upgraded"
+	EventReasonUpgradeFailed          = "UpgradeFailed"
+	EventReasonRolledBack             = "RolledBack"
+	EventReasonRollbackFailed         = "RollbackFailed"
+	EventReasonReady                  = "Ready"
+	EventReasonDegraded               = "Degraded"
+	EventReasonConfigChanged          = "ConfigChanged"
+	EventReasonInvalidConfig          = "InvalidConfig"
+	EventReasonUnexpectedImages       = "UnexpectedImages"
+	EventReasonDriftDetected          = "DriftDetected"
+	EventReasonHealingDrift           = "HealingDrift"
+	EventReasonWaitingForDependencies = "WaitingForDependencies"
+	EventReasonUninstallingDependents = "UninstallingDependents"
+	EventReasonSuspended              = "Suspended"
+	EventReasonResumed                = "Resumed"
+	EventReasonUninstalled            = "Uninstalled"
+	EventReasonUninstallFailed        = "UninstallFailed"
+)
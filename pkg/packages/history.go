@@ -0,0 +1,181 @@
+package packages
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+	"github.com/aws/eks-anywhere-packages/pkg/driver"
+)
+
+const (
+	// maxRevisionHistory is the number of revisions kept in a package's
+	// status.
+	maxRevisionHistory = 10
+
+	// hashLength is the number of hex digits of the hashes reported in a
+	// package's status, enough to tell them apart while staying easy to copy.
+	hashLength = 12
+)
+
+// shortHash returns a short hash of the JSON encoding of v. Maps are encoded
+// with sorted keys, so the same values always hash the same.
+func shortHash(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:hashLength], nil
+}
+
+// configHash identifies the configuration of a revision.
+func configHash(values map[string]interface{}) string {
+	hash, err := shortHash(values)
+	if err != nil {
+		// Values parsed from YAML always encode, so this isn't expected.
+		return ""
+	}
+	return hash
+}
+
+// recordRevision adds a revision to the package's history, numbering it after
+// the last one and dropping the oldest beyond the limit. Revisions whose
+// release has been pruned from the release's own history are marked as having
+// no release to roll back to.
+func (mc *ManagerContext) recordRevision(revision api.PackageRevision) {
+	history := mc.Package.Status.History
+	revision.Revision = 1
+	if len(history) > 0 {
+		revision.Revision = history[len(history)-1].Revision + 1
+	}
+	revision.Time = metav1.Now()
+	history = append(history, revision)
+	if len(history) > maxRevisionHistory {
+		history = history[len(history)-maxRevisionHistory:]
+	}
+	if kept := mc.keptReleases(); kept > 0 && revision.HelmRevision > 0 {
+		for i := range history {
+			if history[i].HelmRevision <= revision.HelmRevision-kept {
+				history[i].HelmRevision = 0
+			}
+		}
+	}
+	mc.Package.Status.History = history
+}
+
+// keptReleases returns the number of revisions of the package's release which
+// are kept, or zero if they all are.
+func (mc *ManagerContext) keptReleases() int {
+	options := mc.Package.GetInstallOptions(mc.PBC.Spec.InstallOptions)
+	if options.MaxHistory != nil {
+		return *options.MaxHistory
+	}
+	return driver.DefaultMaxHistory
+}
+
+// installedRevision returns the revision for an installation of the source.
+func (mc *ManagerContext) installedRevision(values map[string]interface{}) api.PackageRevision {
+	revision := api.PackageRevision{
+		Version:    mc.Source.Version,
+		Digest:     mc.Source.Digest,
+		ConfigHash: configHash(values),
+	}
+	if mc.Bundle != nil {
+		revision.Bundle = mc.Bundle.Name
+	}
+	return revision
+}
+
+// findRevision returns the revision of the package's history with the given
+// number, or nil if it isn't there.
+func (mc *ManagerContext) findRevision(number int64) *api.PackageRevision {
+	for i := range mc.Package.Status.History {
+		if mc.Package.Status.History[i].Revision == number {
+			return &mc.Package.Status.History[i]
+		}
+	}
+	return nil
+}
+
+// isRolledBack returns true if the package's release was last rolled back to
+// the revision its spec refers to.
+func (mc *ManagerContext) isRolledBack() bool {
+	history := mc.Package.Status.History
+	if len(history) == 0 {
+		return false
+	}
+	last := history[len(history)-1]
+	return last.Result == api.RevisionResultRolledBack && last.RollbackOf == mc.Package.Spec.RollbackTo
+}
+
+// rollbackToRevision rolls the package's release back to the revision its
+// spec refers to, then verifies its workloads as after an installation.
+func (mc *ManagerContext) rollbackToRevision() bool {
+	number := mc.Package.Spec.RollbackTo
+	mc.RequeueAfter = retryLong
+	target := mc.findRevision(number)
+	if target == nil {
+		return mc.cannotRollBack(fmt.Sprintf("Revision %d is not in the history of the package", number))
+	}
+	if target.Result == api.RevisionResultFailed || target.HelmRevision == 0 {
+		return mc.cannotRollBack(fmt.Sprintf("Revision %d has no release to roll back to", number))
+	}
+	if err := mc.PackageDriver.Initialize(mc.Ctx, mc.Package.GetClusterName()); err != nil {
+		mc.fail(err, api.FailureReasonClusterUnreachable)
+		mc.Log.Error(err, "Initialization failed")
+		return true
+	}
+
+	options := mc.Package.GetInstallOptions(mc.PBC.Spec.InstallOptions)
+	helmRevision, err := mc.PackageDriver.RollbackTo(mc.Ctx, mc.Package.Name, target.HelmRevision, options)
+	if err != nil {
+		mc.Log.Error(err, "Rollback failed", "name", mc.Package.Name, "revision", number)
+		mc.event(corev1.EventTypeWarning, EventReasonRollbackFailed, "Rollback to revision %d failed: %s", number, err)
+		mc.fail(err, api.FailureReasonRollbackFailed)
+		if errors.Is(err, driver.ErrRevisionNotKept) {
+			target.HelmRevision = 0
+		}
+		return true
+	}
+	mc.Log.Info("Rolled back", "name", mc.Package.Name, "revision", number, "version", target.Version)
+	mc.event(corev1.EventTypeNormal, EventReasonRolledBack, "Rolled back to revision %d of %s", number, target.Version)
+	rolledBack := *target
+	rolledBack.HelmRevision = helmRevision
+	rolledBack.RollbackOf = number
+	rolledBack.Result = api.RevisionResultRolledBack
+	rolledBack.Detail = ""
+	mc.recordRevision(rolledBack)
+
+	now := metav1.Now()
+	mc.Package.Status.Source.Version = rolledBack.Version
+	mc.Package.Status.Source.Digest = rolledBack.Digest
+	mc.Package.Status.CurrentVersion = rolledBack.Version
+	mc.Package.Status.State = api.StateVerifying
+	mc.Package.Status.Detail = ""
+	mc.Package.Status.Reason = ""
+	mc.Package.Status.LastAttemptTime = &now
+	mc.RequeueAfter = retryReadyCheck
+	return true
+}
+
+// cannotRollBack reports a revision the package can't be rolled back to.
+// Retrying won't change that, so the package waits for its spec to change,
+// and the warning is only raised once.
+func (mc *ManagerContext) cannotRollBack(detail string) bool {
+	mc.RequeueAfter = retryNever
+	if mc.Package.Status.Reason == api.FailureReasonRollbackFailed && mc.Package.Status.Detail == detail {
+		return false
+	}
+	mc.Log.Info("Rollback impossible", "name", mc.Package.Name, "detail", detail)
+	mc.event(corev1.EventTypeWarning, EventReasonRollbackFailed, "%s", detail)
+	mc.Package.Status.Detail = detail
+	mc.Package.Status.Reason = api.FailureReasonRollbackFailed
+	return true
+}
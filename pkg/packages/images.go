@@ -0,0 +1,84 @@
+package packages
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+	"github.com/aws/eks-anywhere-packages/pkg/failure"
+)
+
+// verifyImages renders the package with its final values and checks that
+// every image it would run is one of the version's images in the configured
+// image registry. Versions listing no images aren't checked, which the
+// package's conditions report. The result is kept until the source or values
+// change, so retries don't render the package again.
+func (mc *ManagerContext) verifyImages(values map[string]interface{}) error {
+	if len(mc.Version.Images) == 0 {
+		mc.Package.SetCondition(api.ImagesVerifiedCondition, metav1.ConditionUnknown, "NoImagesListed",
+			fmt.Sprintf("The bundle lists no images of %s to verify", mc.Source.Version))
+		mc.Package.Status.ImagesVerifiedHash = ""
+		return nil
+	}
+	hash, err := planHash(mc.Source, values)
+	if err != nil {
+		return err
+	}
+	condition := meta.FindStatusCondition(mc.Package.Status.Conditions, api.ImagesVerifiedCondition)
+	if condition != nil && mc.Package.Status.ImagesVerifiedHash == hash {
+		switch condition.Status {
+		case metav1.ConditionTrue:
+			return nil
+		case metav1.ConditionFalse:
+			return failure.Errorf(api.FailureReasonUnexpectedImages, "%s", condition.Message)
+		}
+	}
+
+	plan, err := mc.PackageDriver.Plan(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, mc.Source, values)
+	if err != nil {
+		return fmt.Errorf("rendering %s to verify its images: %w", mc.Package.Name, err)
+	}
+	unexpected := unexpectedImages(plan.Images, mc.getImageRegistry(values), mc.Version.Images)
+	if len(unexpected) > 0 {
+		err := failure.Errorf(api.FailureReasonUnexpectedImages, "images of %s not in the bundle for registry %s: %s",
+			mc.Package.Name, mc.getImageRegistry(values), strings.Join(unexpected, ", "))
+		mc.Package.SetCondition(api.ImagesVerifiedCondition, metav1.ConditionFalse, "UnexpectedImages", err.Error())
+		mc.Package.Status.ImagesVerifiedHash = hash
+		return err
+	}
+	mc.Package.SetCondition(api.ImagesVerifiedCondition, metav1.ConditionTrue, "ImagesVerified", "")
+	mc.Package.Status.ImagesVerifiedHash = hash
+	return nil
+}
+
+// unexpectedImages returns the images which aren't any of the expected images
+// in the registry. Images must be referenced by digest to be expected.
+func unexpectedImages(images []string, registry string, expected []api.VersionImages) []string {
+	allowed := make(map[string]bool, len(expected))
+	for _, image := range expected {
+		allowed[registry+"/"+image.Repository+"@"+image.Digest] = true
+	}
+	var unexpected []string
+	for _, image := range images {
+		if !allowed[imageByDigest(image)] {
+			unexpected = append(unexpected, image)
+		}
+	}
+	return unexpected
+}
+
+// imageByDigest drops the tag of an image reference which has a digest.
+func imageByDigest(image string) string {
+	at := strings.LastIndex(image, "@")
+	if at < 0 {
+		return image
+	}
+	name := image[:at]
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		name = name[:colon]
+	}
+	return name + image[at:]
+}
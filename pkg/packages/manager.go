@@ -2,19 +2,27 @@ package packages
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
-	"sigs.k8s.io/yaml"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	"github.com/aws/eks-anywhere-packages/pkg/bundle"
+	"github.com/aws/eks-anywhere-packages/pkg/dependency"
 	"github.com/aws/eks-anywhere-packages/pkg/driver"
-	"github.com/aws/eks-anywhere-packages/pkg/utils"
+	"github.com/aws/eks-anywhere-packages/pkg/failure"
+	"github.com/aws/eks-anywhere-packages/pkg/maintenance"
+	"github.com/aws/eks-anywhere-packages/pkg/metrics"
 )
 
 const (
@@ -25,6 +33,16 @@ const (
 	retryLong      = time.Duration(60) * time.Second
 	retryVeryLong  = time.Duration(180) * time.Second
 	sourceRegistry = "sourceRegistry"
+
+	// maxInstallRetries is the number of consecutive failed installation
+	// attempts after which a package is marked failed. The last attempts are
+	// made retryMaxBackoff apart.
+	maxInstallRetries = 8
+	// retryMaxBackoff caps the delay between installation attempts.
+	retryMaxBackoff = time.Duration(15) * time.Minute
+	// retryReadyCheck is the delay between checks that an installed
+	// package's workloads are ready.
+	retryReadyCheck = time.Duration(5) * time.Second
 )
 
 type ManagerContext struct {
@@ -37,23 +55,158 @@ type ManagerContext struct {
 	RequeueAfter  time.Duration
 	Log           logr.Logger
 	Bundle        *api.PackageBundle
+	// Bundles are the package bundles in the cluster, which are searched for
+	// upgrades newer than the active bundle.
+	Bundles       []api.PackageBundle
 	ManagerClient bundle.Client
+	Recorder      record.EventRecorder
+	// InstalledDriver is the driver which installed the package, if the
+	// package has since moved to another.
+	InstalledDriver driver.PackageDriver
 }
 
-func NewManagerContext(ctx context.Context, log logr.Logger, packageDriver driver.PackageDriver) *ManagerContext {
+func NewManagerContext(ctx context.Context, log logr.Logger, packageDriver driver.PackageDriver, recorder record.EventRecorder) *ManagerContext {
 	return &ManagerContext{
 		Ctx:           ctx,
 		Log:           log,
 		PackageDriver: packageDriver,
+		Recorder:      recorder,
 	}
 }
 
-func (mc *ManagerContext) SetUninstalling(namespace, name string) {
-	mc.Package.Namespace = namespace
-	mc.Package.Name = name
+// event records an event against the package.
+func (mc *ManagerContext) event(eventType, reason, messageFmt string, args ...interface{}) {
+	mc.Recorder.Eventf(&mc.Package, eventType, reason, messageFmt, args...)
+}
+
+func (mc *ManagerContext) SetUninstalling() {
 	mc.Package.Status.State = api.StateUninstalling
 }
 
+// fail reports an error in the package status, along with its reason or
+// fallback if it has none.
+func (mc *ManagerContext) fail(err error, fallback api.FailureReasonEnum) {
+	mc.Package.Status.Detail = err.Error()
+	mc.Package.Status.Reason = failure.Reason(err, fallback)
+}
+
+// installFailed records a failed installation attempt. The package is retried
+// with exponential backoff until maxInstallRetries is reached, at which point
+// it is marked failed and no longer retried.
+func (mc *ManagerContext) installFailed(err error) {
+	mc.fail(err, api.FailureReasonInstallFailed)
+	mc.Package.Status.RetryCount++
+	if mc.Package.Status.RetryCount >= maxInstallRetries {
+		mc.Log.Info("Giving up on installation", "name", mc.Package.Name, "retries", mc.Package.Status.RetryCount)
+		mc.Package.Status.State = api.StateFailed
+		mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
+		mc.RequeueAfter = retryNever
+		return
+	}
+	mc.RequeueAfter = retryBackoff(mc.Package.Status.RetryCount)
+}
+
+// rollback returns a package whose upgrade failed to the previously installed
+// release, so it is not left half upgraded while the upgrade is retried. A
+// release the driver already rolled back, as an atomic upgrade does, is only
+// recorded.
+func (mc *ManagerContext) rollback(upgradeErr error, options api.InstallOptions) {
+	if !errors.Is(upgradeErr, driver.ErrRolledBack) {
+		rolledBack, err := mc.PackageDriver.Rollback(mc.Ctx, mc.Package.Name, options)
+		if err != nil {
+			mc.Log.Error(err, "Rollback failed", "name", mc.Package.Name)
+			return
+		}
+		if !rolledBack {
+			return
+		}
+	}
+	mc.Log.Info("Rolled back", "name", mc.Package.Name, "version", mc.Package.Status.CurrentVersion)
+	mc.event(corev1.EventTypeWarning, EventReasonRolledBack, "Rolled back to %s after upgrade to %s failed",
+		mc.Package.Status.CurrentVersion, mc.Source.Version)
+	mc.Package.Status.RolledBack = &api.PackageRollback{
+		Version:       mc.Package.Status.CurrentVersion,
+		FailedVersion: mc.Source.Version,
+		Reason:        upgradeErr.Error(),
+		Time:          metav1.Now(),
+	}
+}
+
+// changeDriver uninstalls a package which has moved to another driver from
+// the driver which installed it, so that the resources it left behind aren't
+// installed over. Its resources are then the new driver's to replace.
+func (mc *ManagerContext) changeDriver() error {
+	if mc.InstalledDriver == nil {
+		return nil
+	}
+	if err := mc.InstalledDriver.Initialize(mc.Ctx, mc.Package.GetClusterName()); err != nil {
+		return err
+	}
+	if err := mc.InstalledDriver.Uninstall(mc.Ctx, mc.Package.Name); err != nil {
+		return failure.Wrap(api.FailureReasonUninstallFailed, fmt.Errorf("uninstalling %s from its previous driver: %w", mc.Package.Name, err))
+	}
+	mc.Log.Info("Uninstalled from previous driver", "name", mc.Package.Name, "driver", mc.Package.Status.InstalledDriver)
+	mc.event(corev1.EventTypeNormal, EventReasonUninstalled, "Uninstalled %s to change its driver", mc.Package.Status.CurrentVersion)
+	mc.Package.Status.InstalledDriver = mc.Source.GetDriver()
+	mc.InstalledDriver = nil
+	return nil
+}
+
+// retryBackoff returns the delay before the next installation attempt.
+func retryBackoff(retries int32) time.Duration {
+	backoff := retryShort
+	for i := int32(1); i < retries && backoff < retryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > retryMaxBackoff {
+		backoff = retryMaxBackoff
+	}
+	return backoff
+}
+
+// backoffRemaining returns how long to wait before the next installation
+// attempt, or zero if it may be made now.
+func (mc *ManagerContext) backoffRemaining() time.Duration {
+	status := mc.Package.Status
+	if status.State != api.StateInstalling || status.RetryCount == 0 || status.LastAttemptTime == nil {
+		return 0
+	}
+	remaining := time.Until(status.LastAttemptTime.Add(retryBackoff(status.RetryCount)))
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// setReadyCondition derives the ready condition from the package state,
+// returning true if it changed.
+func (mc *ManagerContext) setReadyCondition() bool {
+	status := metav1.ConditionFalse
+	switch mc.Package.Status.State {
+	case api.StateInstalled, api.StateUpgradePending:
+		status = metav1.ConditionTrue
+	case api.StatePendingApproval:
+		// An installed version keeps running while changes await approval.
+		if mc.Package.Status.CurrentVersion != "" {
+			status = metav1.ConditionTrue
+		}
+	case api.StateSuspended:
+		// Workloads aren't checked while suspended.
+		status = metav1.ConditionUnknown
+	}
+	reason := api.ConditionReason(string(mc.Package.Status.State))
+	return mc.Package.SetCondition(api.ReadyCondition, status, reason, mc.Package.Status.Detail)
+}
+
+// setConfigValidCondition records whether the package configuration could be
+// parsed, returning true if the condition changed.
+func (mc *ManagerContext) setConfigValidCondition(err error) bool {
+	if err != nil {
+		return mc.Package.SetCondition(api.ConfigValidCondition, metav1.ConditionFalse, "InvalidConfig", err.Error())
+	}
+	return mc.Package.SetCondition(api.ConfigValidCondition, metav1.ConditionTrue, "ValidConfig", "")
+}
+
 func (mc *ManagerContext) getImageRegistry(values map[string]interface{}) string {
 	if val, ok := values[sourceRegistry]; ok {
 		if val != "" {
@@ -72,6 +225,49 @@ func processInitializing(mc *ManagerContext) bool {
 	return true
 }
 
+// isSuspended returns true if reconciliation of the package is suspended,
+// either on its own or with every package on the cluster.
+func (mc *ManagerContext) isSuspended() bool {
+	return mc.Package.Spec.Suspend || mc.PBC.Spec.Suspend
+}
+
+// suspend reports the package as suspended without touching its release,
+// returning true if the status changed.
+func (mc *ManagerContext) suspend() bool {
+	mc.RequeueAfter = retryLong
+	detail := "Reconciliation suspended"
+	if !mc.Package.Spec.Suspend {
+		detail = "Reconciliation of all packages suspended by the package bundle controller"
+	}
+	if mc.Package.Status.State == api.StateSuspended && mc.Package.Status.Detail == detail {
+		return false
+	}
+	if mc.Package.Status.State != api.StateSuspended {
+		mc.Log.Info("Suspending", "name", mc.Package.Name)
+		mc.event(corev1.EventTypeNormal, EventReasonSuspended, "%s", detail)
+	}
+	mc.Package.Status.State = api.StateSuspended
+	mc.Package.Status.Detail = detail
+	mc.Package.Status.Reason = ""
+	return true
+}
+
+// processResuming picks up a package which is no longer suspended as if it
+// had just been installed, so that any changes made in the meantime are
+// reconciled.
+func processResuming(mc *ManagerContext) bool {
+	mc.Log.Info("Resuming", "name", mc.Package.Name)
+	mc.event(corev1.EventTypeNormal, EventReasonResumed, "Reconciliation resumed")
+	mc.Package.Status.State = api.StateInstalled
+	if mc.Package.Status.CurrentVersion == "" {
+		mc.Package.Status.State = api.StateInitializing
+	}
+	mc.Package.Status.Detail = ""
+	mc.Package.Status.Reason = ""
+	mc.RequeueAfter = retryNow
+	return true
+}
+
 func processUpdating(mc *ManagerContext) bool {
 	mc.Log.Info("Updating package ", "name", mc.Package.Name)
 	mc.Package.Status.State = api.StateInstallingDependencies
@@ -81,14 +277,16 @@ func processUpdating(mc *ManagerContext) bool {
 
 func processInstallingDependencies(mc *ManagerContext) bool {
 	mc.Log.Info("Installing dependencies", "chart", mc.Source)
-	dependencies, err := mc.Bundle.GetDependencies(mc.Version)
+	dependencies, err := dependency.NewResolver(mc.Bundle).Resolve(mc.Package.Spec.PackageName, mc.Version)
 	if err != nil {
 		mc.Package.Status.Detail = fmt.Sprintf(
-			"invalid package bundle. (%s@%s bundle: %s)",
+			"invalid package bundle. (%s@%s bundle: %s): %s",
 			mc.Package.Name,
-			mc.Version,
+			mc.Version.Name,
 			mc.Bundle.Name,
+			err,
 		)
+		mc.Package.Status.Reason = api.FailureReasonInvalidBundle
 		mc.Log.Info(mc.Package.Status.Detail)
 		mc.RequeueAfter = retryLong
 		return true
@@ -96,27 +294,43 @@ func processInstallingDependencies(mc *ManagerContext) bool {
 	pkgs, err := mc.ManagerClient.GetPackageList(mc.Ctx, mc.Package.Namespace)
 	if err != nil {
 		mc.RequeueAfter = retryShort
-		mc.Package.Status.Detail = err.Error()
+		mc.fail(err, api.FailureReasonUnknown)
 		return true
 	}
-	pkgsNotReady := []api.Package{}
+	pkgsNotReady := []string{}
 
+	// Dependencies are resolved in install order, so each is created after
+	// the packages it depends on.
 	for _, dep := range dependencies {
 		var pkg *api.Package
 		for i := range pkgs.Items {
 			items := pkgs.Items
-			if items[i].Spec.PackageName == dep.Name {
+			if items[i].Spec.PackageName == dep.Package.Name {
 				pkg = &items[i]
 			}
 		}
 		if pkg != nil {
-			if pkg.Status.State != api.StateInstalled {
-				pkgsNotReady = append(pkgsNotReady, *pkg)
+			// Only packages created as dependencies are owned by their
+			// dependents, those created by users are left alone.
+			if pkg.IsDependency() && !pkg.IsPinned() && pkg.AddDependent(&mc.Package) {
+				if err := mc.ManagerClient.Save(mc.Ctx, pkg); err != nil {
+					mc.Log.Error(err, "adding dependent to dependency package", "dependency", pkg.Name)
+				}
+			}
+			// A dependency upgrading, suspended or otherwise busy keeps
+			// running the installed version, which dependents can use.
+			if !pkg.IsInstalled() || !dep.Requirement.Allows(pkg.Status.CurrentVersion) {
+				pkgsNotReady = append(pkgsNotReady, dep.Requirement.String())
 			}
 		} else {
-			p := api.NewPackage(dep.Name, dep.Name, mc.Package.Namespace, mc.Package.Spec.Config)
+			p := api.NewPackage(dep.Package.Name, dep.Package.Name, mc.Package.Namespace, mc.Package.Spec.DependencyConfig[dep.Package.Name])
 			p.Spec.TargetNamespace = mc.Package.Spec.TargetNamespace
-			pkgsNotReady = append(pkgsNotReady, p)
+			p.Annotations = map[string]string{api.DependencyAnnotation: "true"}
+			p.AddDependent(&mc.Package)
+			if dep.Requirement.Constraints != nil {
+				p.Spec.PackageVersion = dep.Version.Name
+			}
+			pkgsNotReady = append(pkgsNotReady, dep.Requirement.String())
 			err := mc.ManagerClient.CreatePackage(mc.Ctx, &p)
 			if err != nil {
 				mc.Log.Error(err, "creating dependency package")
@@ -125,13 +339,17 @@ func processInstallingDependencies(mc *ManagerContext) bool {
 	}
 
 	if len(pkgsNotReady) > 0 {
-		depsStr := utils.Map(pkgsNotReady, func(pkg api.Package) string { return pkg.Spec.PackageName })
-		mc.Package.Status.Detail = "Waiting for dependencies: " + strings.Join(depsStr, ", ")
+		mc.Package.Status.Detail = "Waiting for dependencies: " + strings.Join(pkgsNotReady, ", ")
+		mc.Package.Status.Reason = ""
+		mc.event(corev1.EventTypeNormal, EventReasonWaitingForDependencies, "%s", mc.Package.Status.Detail)
+		mc.Package.SetCondition(api.DependenciesReadyCondition, metav1.ConditionFalse, "WaitingForDependencies", mc.Package.Status.Detail)
 		mc.RequeueAfter = retrySoon
 		return true
 	}
+	mc.Package.SetCondition(api.DependenciesReadyCondition, metav1.ConditionTrue, "DependenciesInstalled", "")
 	mc.Package.Status.State = api.StateInstalling
 	mc.Package.Status.Detail = ""
+	mc.Package.Status.Reason = ""
 	return true
 }
 
@@ -140,9 +358,12 @@ func processInstalling(mc *ManagerContext) bool {
 	mc.Log.Info("installing/updating", "chart", mc.Source)
 	var err error
 	var values map[string]interface{}
-	if values, err = mc.Package.GetValues(); err != nil {
-		mc.Package.Status.Detail = err.Error()
+	values, err = mc.getValues()
+	mc.setConfigValidCondition(err)
+	if err != nil {
+		mc.fail(err, api.FailureReasonInvalidConfig)
 		mc.Log.Error(err, "Install failed")
+		mc.event(corev1.EventTypeWarning, EventReasonInvalidConfig, "Invalid configuration: %s", err)
 		return true
 	}
 	values[sourceRegistry] = mc.getImageRegistry(values)
@@ -150,47 +371,251 @@ func processInstalling(mc *ManagerContext) bool {
 		mc.Source.Registry = mc.PBC.GetDefaultRegistry()
 	}
 	if err := mc.PackageDriver.Initialize(mc.Ctx, mc.Package.GetClusterName()); err != nil {
-		mc.Package.Status.Detail = err.Error()
+		mc.Log.Error(err, "Initialization failed")
+		// An unreachable cluster counts as a failed attempt, so it is
+		// retried with backoff rather than indefinitely.
+		now := metav1.Now()
+		mc.Package.Status.LastAttemptTime = &now
+		mc.installFailed(failure.Wrap(api.FailureReasonClusterUnreachable, err))
+		return true
+	}
+
+	if mc.Package.Spec.Approval == api.ApprovalManual && !mc.approved(values) {
+		return true
+	}
+
+	now := metav1.Now()
+	mc.Package.Status.LastAttemptTime = &now
+	if err := mc.verifyImages(values); err != nil {
+		mc.Log.Error(err, "Image verification failed")
+		mc.event(corev1.EventTypeWarning, EventReasonUnexpectedImages, "%s", err)
+		mc.installFailed(err)
+		return true
+	}
+	if err := mc.changeDriver(); err != nil {
+		mc.Log.Error(err, "Uninstall from previous driver failed")
+		mc.event(corev1.EventTypeWarning, EventReasonUninstallFailed, "Uninstall of %s before changing its driver failed: %s",
+			mc.Package.Status.CurrentVersion, err)
+		mc.installFailed(err)
 		return true
 	}
 
 	createNamespace := mc.PBC.Spec.CreateNamespace
-	if err := mc.PackageDriver.Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, createNamespace, mc.Source, values); err != nil {
-		mc.Package.Status.Detail = err.Error()
+	revision := mc.installedRevision(values)
+	options := mc.Package.GetInstallOptions(mc.PBC.Spec.InstallOptions)
+	operation := metrics.OperationInstall
+	if mc.Package.Status.CurrentVersion != "" {
+		operation = metrics.OperationUpgrade
+	}
+	started := time.Now()
+	helmRevision, err := mc.PackageDriver.Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, createNamespace, mc.Source, values, options)
+	metrics.ObserveOperation(operation, started, err)
+	if err != nil {
 		mc.Log.Error(err, "Install failed")
+		revision.Result = api.RevisionResultFailed
+		revision.Detail = err.Error()
+		mc.recordRevision(revision)
+		if mc.Package.Status.CurrentVersion != "" {
+			mc.event(corev1.EventTypeWarning, EventReasonUpgradeFailed, "Upgrade to %s failed: %s", mc.Source.Version, err)
+			mc.rollback(err, options)
+		} else {
+			mc.event(corev1.EventTypeWarning, EventReasonInstallFailed, "Install of %s failed: %s", mc.Source.Version, err)
+		}
+		mc.installFailed(err)
 		return true
 	}
 	mc.Log.Info("Installed", "name", mc.Package.Name, "chart", mc.Package.Status.Source)
-	mc.Package.Status.State = api.StateInstalled
+	mc.Package.Status.InstalledDriver = mc.Source.GetDriver()
+	revision.HelmRevision = helmRevision
+	revision.Result = api.RevisionResultSucceeded
+	mc.recordRevision(revision)
+	if previous := mc.Package.Status.CurrentVersion; previous != "" && previous != mc.Source.Version {
+		mc.event(corev1.EventTypeNormal, EventReasonUpgraded, "Upgraded from %s to %s", previous, mc.Source.Version)
+	} else {
+		mc.event(corev1.EventTypeNormal, EventReasonInstalled, "Installed %s", mc.Source.Version)
+	}
+	mc.Package.Status.State = api.StateVerifying
 	mc.Package.Status.CurrentVersion = mc.Source.Version
 	mc.Package.Status.Detail = ""
-	if len(mc.Package.GetClusterName()) == 0 {
-		mc.Package.Status.Detail = "Deprecated package namespace. Move to eksa-packages-" + os.Getenv("CLUSTER_NAME")
-	}
+	mc.Package.Status.Reason = ""
+	mc.Package.Status.RetryCount = 0
+	mc.Package.Status.RolledBack = nil
+	mc.Package.Status.Plan = nil
 	mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
+	mc.RequeueAfter = retryReadyCheck
 	return true
 }
 
-func processInstalled(mc *ManagerContext) bool {
+// processVerifying waits for the workloads of an installed package to become
+// ready, marking it degraded if they aren't within its ready timeout.
+func processVerifying(mc *ManagerContext) bool {
+	if err := mc.PackageDriver.Initialize(mc.Ctx, mc.Package.GetClusterName()); err != nil {
+		mc.fail(err, api.FailureReasonClusterUnreachable)
+		mc.Log.Error(err, "Initialization failed")
+		mc.RequeueAfter = retryShort
+		return true
+	}
+	notReady, err := mc.PackageDriver.NotReady(mc.Ctx, mc.Package.Name)
+	if err != nil {
+		mc.fail(err, api.FailureReasonClusterUnreachable)
+		mc.Log.Error(err, "Readiness check failed")
+		mc.RequeueAfter = retryShort
+		return true
+	}
+
+	if len(notReady) == 0 {
+		mc.Log.Info("Ready", "name", mc.Package.Name)
+		mc.event(corev1.EventTypeNormal, EventReasonReady, "Workloads of %s are ready", mc.Package.Status.CurrentVersion)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Detail = ""
+		mc.Package.Status.Reason = ""
+		if len(mc.Package.GetClusterName()) == 0 {
+			mc.Package.Status.Detail = "Deprecated package namespace. Move to eksa-packages-" + os.Getenv("CLUSTER_NAME")
+		}
+		mc.RequeueAfter = retryNow
+		return true
+	}
+
+	previous := mc.Package.Status.DeepCopy()
+	timeout := mc.Package.GetReadyTimeout()
+	attempted := mc.Package.Status.LastAttemptTime
+	if mc.Package.Status.State == api.StateVerifying && attempted != nil && time.Since(attempted.Time) > timeout {
+		mc.Log.Info("Degraded", "name", mc.Package.Name, "notReady", notReady)
+		mc.event(corev1.EventTypeWarning, EventReasonDegraded, "Workloads not ready after %s: %s", timeout, strings.Join(notReady, "; "))
+		mc.Package.Status.State = api.StateDegraded
+	}
+	if mc.Package.Status.State == api.StateDegraded {
+		mc.Package.Status.Detail = "Workloads not ready: " + strings.Join(notReady, "; ")
+		mc.Package.Status.Reason = api.FailureReasonWorkloadsNotReady
+		mc.RequeueAfter = retryLong
+	} else {
+		mc.Package.Status.Detail = "Waiting for workloads to be ready: " + strings.Join(notReady, "; ")
+		mc.Package.Status.Reason = ""
+		mc.RequeueAfter = retryReadyCheck
+	}
+	return previous.State != mc.Package.Status.State || previous.Detail != mc.Package.Status.Detail ||
+		previous.Reason != mc.Package.Status.Reason
+}
+
+// processDegraded keeps checking the workloads of a degraded package, while
+// still applying any upgrade, change of configuration or rollback.
+func processDegraded(mc *ManagerContext) bool {
+	if mc.Package.Spec.RollbackTo != 0 {
+		if !mc.isRolledBack() {
+			return mc.rollbackToRevision()
+		}
+		return processVerifying(mc)
+	}
 	if mc.Package.Status.Source != mc.Source {
-		mc.Package.Status.Source = mc.Source
+		if mc.upgradePending() {
+			return true
+		}
+		return mc.upgrade()
+	}
+	if !reflect.DeepEqual(mc.Package.Spec, mc.Package.Status.Spec) {
+		mc.Log.Info("Package changed, reinstalling degraded package", "name", mc.Package.Name)
 		mc.Package.Status.State = api.StateUpdating
-		mc.RequeueAfter = retryShort
+		mc.RequeueAfter = retryNow
 		return true
 	}
-	var err error
-	newValues := make(map[string]interface{})
+	return processVerifying(mc)
+}
 
-	err = yaml.Unmarshal([]byte(mc.Package.Spec.Config), &newValues)
+// upgrade starts upgrading the package to the source.
+func (mc *ManagerContext) upgrade() bool {
+	mc.event(corev1.EventTypeNormal, EventReasonUpgrading, "Upgrading from %s to %s", mc.Package.Status.Source.Version, mc.Source.Version)
+	mc.Package.Status.Source = mc.Source
+	mc.Package.Status.State = api.StateUpdating
+	mc.Package.Status.Detail = ""
+	mc.Package.Status.Reason = ""
+	mc.Package.Status.RetryCount = 0
+	mc.Package.Status.NextMaintenanceWindow = nil
+	mc.RequeueAfter = retryShort
+	return true
+}
+
+// maintenanceWindows returns the windows in which the package may be
+// upgraded, which are those of the package bundle controller unless the
+// package has its own.
+func (mc *ManagerContext) maintenanceWindows() []api.MaintenanceWindow {
+	if len(mc.Package.Spec.MaintenanceWindows) > 0 {
+		return mc.Package.Spec.MaintenanceWindows
+	}
+	return mc.PBC.Spec.MaintenanceWindows
+}
+
+// upgradePending holds an upgrade of the package while its maintenance
+// windows are closed, returning true if it is held.
+func (mc *ManagerContext) upgradePending() bool {
+	open, next, err := maintenance.Next(mc.maintenanceWindows(), time.Now())
+	if err == nil && open {
+		return false
+	}
+	if mc.Package.Status.State != api.StateUpgradePending {
+		mc.event(corev1.EventTypeNormal, EventReasonUpgradePending, "Upgrade from %s to %s pending until the next maintenance window",
+			mc.Package.Status.Source.Version, mc.Source.Version)
+	}
+	mc.Package.Status.State = api.StateUpgradePending
+	mc.Package.Status.NextMaintenanceWindow = nil
+	mc.RequeueAfter = retryVeryLong
+	if err != nil {
+		mc.fail(err, api.FailureReasonInvalidConfig)
+		return true
+	}
+	mc.Package.Status.Detail = fmt.Sprintf("Upgrade to %s pending until the next maintenance window", mc.Source.Version)
+	mc.Package.Status.Reason = ""
+	if !next.IsZero() {
+		opens := metav1.NewTime(next)
+		mc.Package.Status.NextMaintenanceWindow = &opens
+		mc.RequeueAfter = min(time.Until(next), retryVeryLong)
+	}
+	return true
+}
+
+func processUpgradePending(mc *ManagerContext) bool {
+	if mc.Package.Status.Source == mc.Source || mc.Package.Spec.RollbackTo != 0 {
+		mc.Log.Info("Pending upgrade withdrawn", "name", mc.Package.Name)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.NextMaintenanceWindow = nil
+		mc.Package.Status.Detail = ""
+		mc.Package.Status.Reason = ""
+		mc.RequeueAfter = retryNow
+		return true
+	}
+	previous := mc.Package.Status.DeepCopy()
+	if mc.upgradePending() {
+		return !reflect.DeepEqual(previous, &mc.Package.Status)
+	}
+	return mc.upgrade()
+}
+
+func processInstalled(mc *ManagerContext) bool {
+	if mc.Package.Spec.RollbackTo != 0 {
+		if !mc.isRolledBack() {
+			return mc.rollbackToRevision()
+		}
+		// The rolled back release is kept until the spec lets go of it.
+		mc.RequeueAfter = retryVeryLong
+		return mc.setReadyCondition()
+	}
+	if mc.Package.Status.Source != mc.Source {
+		if mc.upgradePending() {
+			return true
+		}
+		return mc.upgrade()
+	}
+	newValues, err := mc.getValues()
+	configValidChanged := mc.setConfigValidCondition(err)
 	if err != nil {
 		mc.Log.Error(err, "unmarshaling current package configuration")
-		mc.Package.Status.Detail = err.Error()
+		mc.event(corev1.EventTypeWarning, EventReasonInvalidConfig, "Invalid configuration: %s", err)
+		mc.fail(err, api.FailureReasonInvalidConfig)
 		mc.RequeueAfter = retryShort
 		return true
 	}
 
 	if err := mc.PackageDriver.Initialize(mc.Ctx, mc.Package.GetClusterName()); err != nil {
-		mc.Package.Status.Detail = err.Error()
+		mc.fail(err, api.FailureReasonClusterUnreachable)
 		mc.Log.Error(err, "Initialization failed")
 		return true
 	}
@@ -199,12 +624,13 @@ func processInstalled(mc *ManagerContext) bool {
 	needs, err := mc.PackageDriver.IsConfigChanged(mc.Ctx, mc.Package.Name, newValues)
 	if err != nil {
 		mc.Log.Error(err, "checking necessity of reconfiguration")
-		mc.Package.Status.Detail = err.Error()
+		mc.fail(err, api.FailureReasonUnknown)
 		mc.RequeueAfter = retryLong
 		return true
 	}
 	if needs {
 		mc.Log.Info("configuration change detected, upgrading")
+		mc.event(corev1.EventTypeNormal, EventReasonConfigChanged, "Configuration changed, reinstalling %s", mc.Source.Version)
 		mc.Package.Status.State = api.StateUpdating
 		mc.RequeueAfter = retryShort
 		mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
@@ -212,29 +638,176 @@ func processInstalled(mc *ManagerContext) bool {
 	}
 	mc.RequeueAfter = retryVeryLong
 
-	return false
+	driftChanged := mc.detectDrift()
+	if len(mc.Package.Status.Drift) > 0 && mc.Package.Spec.HealDrift {
+		mc.Log.Info("drift detected, reinstalling", "drift", mc.Package.Status.Drift)
+		mc.event(corev1.EventTypeNormal, EventReasonHealingDrift, "Reinstalling %s to heal drift", mc.Package.Status.CurrentVersion)
+		mc.Package.Status.State = api.StateUpdating
+		mc.RequeueAfter = retryShort
+		return true
+	}
+
+	// Packages installed before conditions were reported, or whose generation
+	// changed without a configuration change, still need their conditions
+	// brought up to date.
+	readyChanged := mc.setReadyCondition()
+	return readyChanged || configValidChanged || driftChanged
 }
 
+// detectDrift records the package's resources whose live state differs from
+// its release, returning true if they changed.
+func (mc *ManagerContext) detectDrift() bool {
+	drift, err := mc.PackageDriver.Drift(mc.Ctx, mc.Package.Name)
+	if err != nil {
+		mc.Log.Error(err, "checking drift")
+		return false
+	}
+	if reflect.DeepEqual(drift, mc.Package.Status.Drift) || len(drift)+len(mc.Package.Status.Drift) == 0 {
+		return false
+	}
+	if len(drift) > 0 {
+		mc.event(corev1.EventTypeWarning, EventReasonDriftDetected, "Resources drifted: %s", strings.Join(drift, "; "))
+	}
+	mc.Package.Status.Drift = drift
+	return true
+}
+
+// processUninstalling uninstalls a deleted package, then removes its
+// finalizer so the deletion can complete.
 func processUninstalling(mc *ManagerContext) bool {
+	if mc.Package.IsCascadeDelete() && mc.uninstallDependents() {
+		return true
+	}
 	if err := mc.PackageDriver.Initialize(mc.Ctx, mc.Package.GetClusterName()); err != nil {
-		mc.Package.Status.Detail = err.Error()
+		mc.fail(err, api.FailureReasonClusterUnreachable)
 		mc.Log.Error(err, "Initialization failed")
-		return false
+		mc.RequeueAfter = retryShort
+		return true
 	}
-	if err := mc.PackageDriver.Uninstall(mc.Ctx, mc.Package.Name); err != nil {
-		mc.Package.Status.Detail = err.Error()
+	started := time.Now()
+	err := mc.PackageDriver.Uninstall(mc.Ctx, mc.Package.Name)
+	metrics.ObserveOperation(metrics.OperationUninstall, started, err)
+	if err != nil {
+		mc.fail(err, api.FailureReasonUninstallFailed)
 		mc.Log.Error(err, "Uninstall failed")
-		return false
+		mc.event(corev1.EventTypeWarning, EventReasonUninstallFailed, "Uninstall failed: %s", err)
+		mc.RequeueAfter = retryShort
+		return true
 	}
 	mc.Log.Info("Uninstalled", "name", mc.Package.Name)
+	mc.event(corev1.EventTypeNormal, EventReasonUninstalled, "Uninstalled")
 	mc.Package.Status.Detail = ""
+	mc.Package.Status.Reason = ""
 	mc.RequeueAfter = retryNever
+	if controllerutil.RemoveFinalizer(&mc.Package, api.PackageFinalizer) {
+		if err := mc.ManagerClient.Save(mc.Ctx, &mc.Package); err != nil {
+			mc.Log.Error(err, "removing finalizer")
+			mc.fail(err, api.FailureReasonUnknown)
+			mc.RequeueAfter = retryShort
+			return true
+		}
+	}
+	return false
+}
+
+// uninstallDependents deletes the packages depending on the package, in
+// reverse order of their dependencies, returning true while any remain.
+func (mc *ManagerContext) uninstallDependents() bool {
+	pkgs, err := mc.ManagerClient.GetPackageList(mc.Ctx, mc.Package.Namespace)
+	if err != nil {
+		mc.fail(err, api.FailureReasonUnknown)
+		mc.RequeueAfter = retryShort
+		return true
+	}
+	installedBundle := func(name string) (*api.PackageBundle, error) {
+		return mc.ManagerClient.GetBundle(mc.Ctx, name)
+	}
+	dependents := dependency.NewResolver(mc.Bundle).WithInstalledBundles(installedBundle).Dependents(&mc.Package, pkgs.Items)
+	if len(dependents) == 0 {
+		return false
+	}
+
+	names := []string{}
+	for _, dependent := range dependents {
+		names = append(names, dependent.Package.Name)
+		if !dependent.Package.DeletionTimestamp.IsZero() || isRequired(dependent.Package, dependents) {
+			continue
+		}
+		mc.Log.Info("Uninstalling dependent", "name", mc.Package.Name, "dependent", dependent.Package.Name)
+		if err := mc.ManagerClient.DeletePackage(mc.Ctx, &dependent.Package); err != nil {
+			mc.Log.Error(err, "deleting dependent package", "dependent", dependent.Package.Name)
+			continue
+		}
+		mc.event(corev1.EventTypeNormal, EventReasonUninstallingDependents, "Uninstalling dependent %s", dependent.Package.Name)
+	}
+	mc.Package.Status.Detail = "Waiting for dependents to be uninstalled: " + strings.Join(names, ", ")
+	mc.Package.Status.Reason = ""
+	mc.RequeueAfter = retrySoon
+	return true
+}
+
+// isRequired returns true if any of the dependents depend on the package.
+func isRequired(pkg api.Package, dependents []dependency.Dependent) bool {
+	for _, dependent := range dependents {
+		if dependent.Requires(pkg.Spec.PackageName) {
+			return true
+		}
+	}
 	return false
 }
 
+func processFailed(mc *ManagerContext) bool {
+	mc.RequeueAfter = retryNever
+	if mc.Package.Spec.RollbackTo != 0 {
+		if !mc.isRolledBack() {
+			return mc.rollbackToRevision()
+		}
+		// The rolled back release is kept until the spec lets go of it.
+		return false
+	}
+	if mc.Package.Status.Source == mc.Source && reflect.DeepEqual(mc.Package.Spec, mc.Package.Status.Spec) {
+		return false
+	}
+	// An installed package is only upgraded to another source within its
+	// maintenance windows.
+	if mc.Package.Status.Source != mc.Source && mc.Package.Status.CurrentVersion != "" {
+		if mc.upgradePending() {
+			return true
+		}
+		return mc.upgrade()
+	}
+	mc.Log.Info("Package changed, retrying failed installation", "name", mc.Package.Name)
+	mc.resetRetries()
+	return true
+}
+
+// resetRetries clears the retry accounting and restarts the installation.
+func (mc *ManagerContext) resetRetries() {
+	mc.Package.Status.RetryCount = 0
+	mc.Package.Status.State = api.StateInstallingDependencies
+	mc.Package.Status.Detail = ""
+	mc.Package.Status.Reason = ""
+	mc.RequeueAfter = retryNow
+}
+
+// processRetryRequested handles the retry annotation set by an operator.
+func processRetryRequested(mc *ManagerContext) bool {
+	delete(mc.Package.Annotations, api.RetryAnnotation)
+	if err := mc.ManagerClient.Save(mc.Ctx, &mc.Package); err != nil {
+		mc.Log.Error(err, "removing retry annotation")
+		mc.fail(err, api.FailureReasonUnknown)
+		mc.RequeueAfter = retryShort
+		return true
+	}
+	mc.Log.Info("Retry requested", "name", mc.Package.Name)
+	mc.resetRetries()
+	return true
+}
+
 func processUnknown(mc *ManagerContext) bool {
 	mc.Log.Info("Unknown state", "name", mc.Package.Name)
 	mc.Package.Status.Detail = "Unknown state: " + string(mc.Package.Status.State)
+	mc.Package.Status.Reason = api.FailureReasonUnknown
 	mc.RequeueAfter = retryNever
 	mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
 	return true
@@ -268,9 +841,15 @@ func NewManager() Manager {
 				api.StateInitializing:           processInitializing,
 				api.StateInstalling:             processInstalling,
 				api.StateInstallingDependencies: processInstallingDependencies,
+				api.StateVerifying:              processVerifying,
 				api.StateInstalled:              processInstalled,
+				api.StateDegraded:               processDegraded,
+				api.StateUpgradePending:         processUpgradePending,
+				api.StatePendingApproval:        processPendingApproval,
 				api.StateUpdating:               processUpdating,
 				api.StateUninstalling:           processUninstalling,
+				api.StateSuspended:              processResuming,
+				api.StateFailed:                 processFailed,
 				api.StateUnknown:                processDone,
 			},
 		})
@@ -292,16 +871,32 @@ func (m manager) Process(mc *ManagerContext) bool {
 	mc.RequeueAfter = retryLong
 	if !mc.Package.IsValidNamespace() {
 		mc.Package.Status.Detail = "Packages namespaces must start with: " + api.PackageNamespace
+		mc.Package.Status.Reason = api.FailureReasonInvalidNamespace
 		mc.RequeueAfter = retryNever
 		if mc.Package.Status.State == api.StateUnknown {
 			return false
 		}
 		mc.Package.Status.State = api.StateUnknown
+		mc.setReadyCondition()
 		return true
 	}
-	stateFunc := m.getState(mc.Package.Status.State)
-	result := stateFunc(mc)
+	var result bool
+	if mc.isSuspended() && mc.Package.Status.State != api.StateUninstalling {
+		result = mc.suspend()
+	} else if mc.Package.IsRetryRequested() && mc.Package.Status.State != api.StateUninstalling {
+		result = processRetryRequested(mc)
+	} else if remaining := mc.backoffRemaining(); remaining > 0 {
+		mc.RequeueAfter = remaining
+		return false
+	} else {
+		stateFunc := m.getState(mc.Package.Status.State)
+		result = stateFunc(mc)
+	}
+	if mc.updateUpgradesAvailable() {
+		result = true
+	}
 	if result {
+		mc.setReadyCondition()
 		mc.Log.Info(
 			"Updating",
 			"namespace",
@@ -10,13 +10,21 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	cMock "github.com/aws/eks-anywhere-packages/controllers/mocks"
 	"github.com/aws/eks-anywhere-packages/pkg/bundle"
+	"github.com/aws/eks-anywhere-packages/pkg/driver"
 	"github.com/aws/eks-anywhere-packages/pkg/driver/mocks"
+	"github.com/aws/eks-anywhere-packages/pkg/failure"
 )
 
 const (
@@ -121,6 +129,13 @@ func givenBundle() *api.PackageBundle {
 	}
 }
 
+func givenDependencyPackage(mc *ManagerContext, config string) api.Package {
+	pkg := api.NewPackage("test-dep", "test-dep", mc.Package.Namespace, config)
+	pkg.Annotations = map[string]string{api.DependencyAnnotation: "true"}
+	pkg.AddDependent(&mc.Package)
+	return pkg
+}
+
 func givenMockClient(t *testing.T) *cMock.MockClient {
 	gomockController := gomock.NewController(t)
 	return cMock.NewMockClient(gomockController)
@@ -153,6 +168,7 @@ func givenMocks(t *testing.T) (*ManagerContext, *mocks.MockPackageDriver) {
 		},
 		RequeueAfter: time.Duration(100),
 		Log:          logr.Discard(),
+		Recorder:     record.NewFakeRecorder(10),
 	}, mockDriver
 }
 
@@ -163,14 +179,35 @@ func thenManagerContext(t *testing.T, mc *ManagerContext, expectedState api.Stat
 	assert.Equal(t, expectedDetail, mc.Package.Status.Detail)
 }
 
+func thenCondition(t *testing.T, mc *ManagerContext, conditionType string, expectedStatus metav1.ConditionStatus, expectedReason string) {
+	condition := meta.FindStatusCondition(mc.Package.Status.Conditions, conditionType)
+	if assert.NotNil(t, condition, conditionType) {
+		assert.Equal(t, expectedStatus, condition.Status)
+		assert.Equal(t, expectedReason, condition.Reason)
+		assert.Equal(t, mc.Package.Generation, condition.ObservedGeneration)
+	}
+}
+
+func thenEvents(t *testing.T, mc *ManagerContext, expected ...string) {
+	events := mc.Recorder.(*record.FakeRecorder).Events
+	for _, e := range expected {
+		select {
+		case actual := <-events:
+			assert.Equal(t, e, actual)
+		default:
+			assert.Fail(t, "missing event", e)
+		}
+	}
+	assert.Empty(t, events)
+}
+
 func TestManagerContext_SetUninstalling(t *testing.T) {
 	sut, _ := givenMocks(t)
-	expectedName := "billy"
 	expectedState := api.StateUninstalling
 
-	sut.SetUninstalling(api.PackageNamespace+"-"+clusterName, expectedName)
+	sut.SetUninstalling()
 
-	assert.Equal(t, expectedName, sut.Package.Name)
+	assert.Equal(t, packageInstance, sut.Package.Name)
 	assert.Equal(t, expectedState, sut.Package.Status.State)
 }
 
@@ -192,6 +229,17 @@ func TestManagerContext_getRegistry(t *testing.T) {
 	})
 }
 
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, retryShort, retryBackoff(1))
+	assert.Equal(t, 2*retryShort, retryBackoff(2))
+	assert.Equal(t, 4*retryShort, retryBackoff(3))
+	assert.Equal(t, 16*retryShort, retryBackoff(5))
+	assert.Equal(t, retryMaxBackoff, retryBackoff(6))
+	assert.Equal(t, retryMaxBackoff, retryBackoff(100))
+	// The cap is reached before the package is marked failed.
+	assert.Equal(t, retryMaxBackoff, retryBackoff(maxInstallRetries-1))
+}
+
 func TestNewManager(t *testing.T) {
 	expectedManager := NewManager()
 	actualManager := NewManager()
@@ -224,13 +272,121 @@ func TestManagerLifecycle(t *testing.T) {
 		mc.Package.Status.State = api.StateInstallingDependencies
 		mc.Version = mc.Bundle.Spec.Packages[0].Source.Versions[0]
 		mockClient.EXPECT().List(mc.Ctx, gomock.AssignableToTypeOf(&api.PackageList{}), gomock.Eq(client.InNamespace(mc.Package.Namespace))).SetArg(1, api.PackageList{})
-		expectedDepPkg := api.NewPackage("test-dep", "test-dep", mc.Package.Namespace, mc.Package.Spec.Config)
+		expectedDepPkg := givenDependencyPackage(mc, "")
+		mockClient.EXPECT().Create(mc.Ctx, gomock.Eq(&expectedDepPkg)).Return(nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstallingDependencies, api.PackageOCISource{}, retrySoon, "Waiting for dependencies: test-dep")
+	})
+
+	t.Run("installing with constrained dependency creates it at a matching version with its own config", func(t *testing.T) {
+		mc, mockClient := givenMocksWithClient(t)
+		mc.Package.Status.State = api.StateInstallingDependencies
+		mc.Package.Spec.DependencyConfig = map[string]string{"test-dep": "foo: bar"}
+		mc.Bundle.Spec.Packages[1].Source.Versions = []api.SourceVersion{{Name: "v1.13.0-abc123"}, {Name: "v1.11.2-def456"}}
+		mc.Version = mc.Bundle.Spec.Packages[0].Source.Versions[0]
+		mc.Version.Dependencies = []string{"test-dep>=1.12, <1.14"}
+		mockClient.EXPECT().List(mc.Ctx, gomock.AssignableToTypeOf(&api.PackageList{}), gomock.Eq(client.InNamespace(mc.Package.Namespace))).SetArg(1, api.PackageList{})
+		expectedDepPkg := givenDependencyPackage(mc, "foo: bar")
+		expectedDepPkg.Spec.PackageVersion = "v1.13.0-abc123"
 		mockClient.EXPECT().Create(mc.Ctx, gomock.Eq(&expectedDepPkg)).Return(nil)
 		result := sut.Process(mc)
 		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstallingDependencies, api.PackageOCISource{}, retrySoon, "Waiting for dependencies: test-dep>=1.12, <1.14")
+	})
+
+	t.Run("installing waits if an installed dependency doesn't satisfy its constraint", func(t *testing.T) {
+		mc, mockClient := givenMocksWithClient(t)
+		mc.Package.Status.State = api.StateInstallingDependencies
+		mc.Bundle.Spec.Packages[1].Source.Versions = []api.SourceVersion{{Name: "v1.13.0-abc123"}, {Name: "v1.11.2-def456"}}
+		mc.Version = mc.Bundle.Spec.Packages[0].Source.Versions[0]
+		mc.Version.Dependencies = []string{"test-dep>=1.12"}
+		installedPkg := api.NewPackage("test-dep", "test-dep", mc.Package.Namespace, "")
+		installedPkg.Status.State = api.StateInstalled
+		installedPkg.Status.CurrentVersion = "v1.11.2-def456"
+		mockClient.EXPECT().List(mc.Ctx, gomock.AssignableToTypeOf(&api.PackageList{}), gomock.Eq(client.InNamespace(mc.Package.Namespace))).SetArg(1, api.PackageList{
+			Items: []api.Package{
+				installedPkg,
+			},
+		})
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstallingDependencies, api.PackageOCISource{}, retrySoon, "Waiting for dependencies: test-dep>=1.12")
+	})
+
+	for _, state := range []api.StateEnum{api.StateSuspended, api.StateUpgradePending, api.StatePendingApproval, api.StateDegraded} {
+		t.Run("installing succeeds if an installed dependency is "+string(state), func(t *testing.T) {
+			mc, mockClient := givenMocksWithClient(t)
+			mc.Package.Status.State = api.StateInstallingDependencies
+			mc.Bundle.Spec.Packages[1].Source.Versions = []api.SourceVersion{{Name: "v1.13.0-abc123"}}
+			mc.Version = mc.Bundle.Spec.Packages[0].Source.Versions[0]
+			mc.Version.Dependencies = []string{"test-dep>=1.12"}
+			depPkg := api.NewPackage("test-dep", "test-dep", mc.Package.Namespace, "")
+			depPkg.Status.State = state
+			depPkg.Status.CurrentVersion = "v1.13.0-abc123"
+			mockClient.EXPECT().List(mc.Ctx, gomock.AssignableToTypeOf(&api.PackageList{}), gomock.Eq(client.InNamespace(mc.Package.Namespace))).SetArg(1, api.PackageList{
+				Items: []api.Package{depPkg},
+			})
+			result := sut.Process(mc)
+			assert.True(t, result)
+			thenManagerContext(t, mc, api.StateInstalling, api.PackageOCISource{}, retryLong, "")
+		})
+	}
+
+	t.Run("installing waits for a dependency which was never installed", func(t *testing.T) {
+		mc, mockClient := givenMocksWithClient(t)
+		mc.Package.Status.State = api.StateInstallingDependencies
+		mc.Version = mc.Bundle.Spec.Packages[0].Source.Versions[0]
+		depPkg := api.NewPackage("test-dep", "test-dep", mc.Package.Namespace, "")
+		depPkg.Status.State = api.StateSuspended
+		mockClient.EXPECT().List(mc.Ctx, gomock.AssignableToTypeOf(&api.PackageList{}), gomock.Eq(client.InNamespace(mc.Package.Namespace))).SetArg(1, api.PackageList{
+			Items: []api.Package{depPkg},
+		})
+		result := sut.Process(mc)
+		assert.True(t, result)
 		thenManagerContext(t, mc, api.StateInstallingDependencies, api.PackageOCISource{}, retrySoon, "Waiting for dependencies: test-dep")
 	})
 
+	t.Run("installing records the package as a dependent of an existing dependency", func(t *testing.T) {
+		mc, mockClient := givenMocksWithClient(t)
+		mc.Package.Status.State = api.StateInstallingDependencies
+		mc.Version = mc.Bundle.Spec.Packages[0].Source.Versions[0]
+		depPkg := api.NewPackage("test-dep", "test-dep", mc.Package.Namespace, "")
+		depPkg.Annotations = map[string]string{api.DependencyAnnotation: "true"}
+		depPkg.OwnerReferences = []metav1.OwnerReference{{APIVersion: api.GroupVersion.String(), Kind: api.PackageKind, Name: "other", UID: "other-uid"}}
+		depPkg.Status.State = api.StateInstalled
+		mockClient.EXPECT().List(mc.Ctx, gomock.AssignableToTypeOf(&api.PackageList{}), gomock.Eq(client.InNamespace(mc.Package.Namespace))).SetArg(1, api.PackageList{
+			Items: []api.Package{depPkg},
+		})
+		expectedDepPkg := depPkg.DeepCopy()
+		expectedDepPkg.AddDependent(&mc.Package)
+		mockClient.EXPECT().Update(mc.Ctx, gomock.Eq(expectedDepPkg), gomock.Any()).Return(nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalling, api.PackageOCISource{}, retryLong, "")
+		assert.Equal(t, []string{"other", "packageInstance"}, expectedDepPkg.GetDependents())
+	})
+
+	t.Run("installing doesn't take ownership of user created or pinned dependencies", func(t *testing.T) {
+		for _, annotations := range []map[string]string{
+			nil,
+			{api.DependencyAnnotation: "true", api.PinnedAnnotation: "true"},
+		} {
+			mc, mockClient := givenMocksWithClient(t)
+			mc.Package.Status.State = api.StateInstallingDependencies
+			mc.Version = mc.Bundle.Spec.Packages[0].Source.Versions[0]
+			depPkg := api.NewPackage("test-dep", "test-dep", mc.Package.Namespace, "")
+			depPkg.Annotations = annotations
+			depPkg.Status.State = api.StateInstalled
+			mockClient.EXPECT().List(mc.Ctx, gomock.AssignableToTypeOf(&api.PackageList{}), gomock.Eq(client.InNamespace(mc.Package.Namespace))).SetArg(1, api.PackageList{
+				Items: []api.Package{depPkg},
+			})
+			result := sut.Process(mc)
+			assert.True(t, result)
+			thenManagerContext(t, mc, api.StateInstalling, api.PackageOCISource{}, retryLong, "")
+		}
+	})
+
 	t.Run("installing succeeds if dependencies are installed", func(t *testing.T) {
 		mc, mockClient := givenMocksWithClient(t)
 		mc.Package.Status.State = api.StateInstallingDependencies
@@ -254,7 +410,8 @@ func TestManagerLifecycle(t *testing.T) {
 		mc.Version.Dependencies = []string{"bad-dep"}
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateInstallingDependencies, api.PackageOCISource{}, retryLong, "invalid package bundle. (packageInstance@{test sha256:deadbeefc7e907d06dafe4687e579fce76b37e4e93b7605022da52e6ccc26fd2 []  [bad-dep]} bundle: testPackageBundle)")
+		thenManagerContext(t, mc, api.StateInstallingDependencies, api.PackageOCISource{}, retryLong, "invalid package bundle. (packageInstance@test bundle: testPackageBundle): package not found in bundle (testPackageBundle): bad-dep")
+		assert.Equal(t, api.FailureReasonInvalidBundle, mc.Package.Status.Reason)
 	})
 
 	t.Run("installing dependencies fails if the client fails", func(t *testing.T) {
@@ -281,6 +438,9 @@ func TestManagerLifecycle(t *testing.T) {
 		result := sut.Process(mc)
 		assert.True(t, result)
 		thenManagerContext(t, mc, api.StateInstallingDependencies, api.PackageOCISource{}, retrySoon, "Waiting for dependencies: test-dep")
+		thenCondition(t, mc, api.DependenciesReadyCondition, metav1.ConditionFalse, "WaitingForDependencies")
+		thenEvents(t, mc, "Normal WaitingForDependencies Waiting for dependencies: test-dep")
+		thenCondition(t, mc, api.ReadyCondition, metav1.ConditionFalse, "InstallingDependencies")
 
 		installedPkg.Status.State = api.StateInstalled
 		mockClient.EXPECT().List(mc.Ctx, gomock.AssignableToTypeOf(&api.PackageList{}), gomock.Eq(client.InNamespace(mc.Package.Namespace))).SetArg(1, api.PackageList{
@@ -291,28 +451,206 @@ func TestManagerLifecycle(t *testing.T) {
 		result = sut.Process(mc)
 		assert.True(t, result)
 		thenManagerContext(t, mc, api.StateInstalling, api.PackageOCISource{}, retryLong, "")
+		thenCondition(t, mc, api.DependenciesReadyCondition, metav1.ConditionTrue, "DependenciesInstalled")
 	})
 
-	t.Run("installing installs", func(t *testing.T) {
+	givenValuesFrom := func(t *testing.T, mc *ManagerContext) *cMock.MockClient {
+		mockClient := givenMockClient(t)
+		mc.ManagerClient = bundle.NewManagerClient(mockClient)
+		mc.Package.Spec.Config = "image:\n  tag: inline\n"
+		mc.Package.Spec.ValuesFrom = []api.ValuesReference{
+			{Kind: "ConfigMap", Name: "defaults"},
+			{Kind: "Secret", Name: "credentials", Key: "creds"},
+		}
+		return mockClient
+	}
+	givenValuesObjects := func(mockClient *cMock.MockClient, mc *ManagerContext) {
+		mockClient.EXPECT().Get(mc.Ctx, types.NamespacedName{Namespace: mc.Package.Namespace, Name: "defaults"}, gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			DoAndReturn(func(_ context.Context, _ types.NamespacedName, obj client.Object, _ ...client.GetOption) error {
+				obj.(*corev1.ConfigMap).Data = map[string]string{"values.yaml": "image:\n  repository: hello\n  tag: default\npassword: default\n"}
+				return nil
+			})
+		mockClient.EXPECT().Get(mc.Ctx, types.NamespacedName{Namespace: mc.Package.Namespace, Name: "credentials"}, gomock.AssignableToTypeOf(&corev1.Secret{})).
+			DoAndReturn(func(_ context.Context, _ types.NamespacedName, obj client.Object, _ ...client.GetOption) error {
+				obj.(*corev1.Secret).Data = map[string][]byte{"creds": []byte("password: secret\n")}
+				return nil
+			})
+	}
+
+	t.Run("installing merges values from references", func(t *testing.T) {
 		mc, mockDriver := givenMocks(t)
 		mc.Package.Status.State = api.StateInstalling
+		mockClient := givenValuesFrom(t, mc)
+		givenValuesObjects(mockClient, mc)
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, map[string]interface{}{
+			"image":        map[string]interface{}{"repository": "hello", "tag": "inline"},
+			"password":     "secret",
+			sourceRegistry: mc.PBC.GetDefaultImageRegistry(),
+		}, api.InstallOptions{}).Return(1, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateVerifying, expectedSource, retryReadyCheck, "")
+	})
+
+	t.Run("installing fails for missing referenced values", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mockClient := givenValuesFrom(t, mc)
+		mockClient.EXPECT().Get(mc.Ctx, gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			Return(apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "defaults"))
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryLong,
+			`getting config map eksa-packages-clusterName/defaults: configmaps "defaults" not found`)
+		thenCondition(t, mc, api.ConfigValidCondition, metav1.ConditionFalse, "InvalidConfig")
+	})
+
+	t.Run("installed compares merged values", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedSource
+		mockClient := givenValuesFrom(t, mc)
+		givenValuesObjects(mockClient, mc)
 		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
-		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any()).Return(nil)
+		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, map[string]interface{}{
+			"image":        map[string]interface{}{"repository": "hello", "tag": "inline"},
+			"password":     "secret",
+			sourceRegistry: mc.PBC.GetDefaultImageRegistry(),
+		}).Return(true, nil)
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 60*time.Second, "")
+		thenManagerContext(t, mc, api.StateUpdating, expectedSource, retryShort, "")
 	})
 
-	t.Run("installing in deprecated namespace", func(t *testing.T) {
+	t.Run("installing installs", func(t *testing.T) {
 		mc, mockDriver := givenMocks(t)
 		mc.Package.Status.State = api.StateInstalling
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateVerifying, expectedSource, retryReadyCheck, "")
+		thenCondition(t, mc, api.ReadyCondition, metav1.ConditionFalse, "Verifying")
+		thenCondition(t, mc, api.ConfigValidCondition, metav1.ConditionTrue, "ValidConfig")
+		thenEvents(t, mc, "Normal Installed Installed ")
+	})
+
+	t.Run("verifying in deprecated namespace", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateVerifying
 		mc.Package.Namespace = "eksa-packages"
 		t.Setenv("CLUSTER_NAME", "franky")
 		mockDriver.EXPECT().Initialize(mc.Ctx, "").Return(nil)
-		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any()).Return(nil)
+		mockDriver.EXPECT().NotReady(mc.Ctx, mc.Package.Name).Return(nil, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedEmptySource, retryNow, "Deprecated package namespace. Move to eksa-packages-franky")
+	})
+
+	t.Run("verifying marks ready packages installed", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateVerifying
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().NotReady(mc.Ctx, mc.Package.Name).Return(nil, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedEmptySource, retryNow, "")
+		thenCondition(t, mc, api.ReadyCondition, metav1.ConditionTrue, "Installed")
+		thenEvents(t, mc, "Normal Ready Workloads of 0.1.0 are ready")
+	})
+
+	t.Run("verifying waits for workloads", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateVerifying
+		now := metav1.Now()
+		mc.Package.Status.LastAttemptTime = &now
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().NotReady(mc.Ctx, mc.Package.Name).Return([]string{"Deployment ns/a: not ready", "Job ns/b: running"}, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateVerifying, expectedEmptySource, retryReadyCheck, "Waiting for workloads to be ready: Deployment ns/a: not ready; Job ns/b: running")
+
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().NotReady(mc.Ctx, mc.Package.Name).Return([]string{"Deployment ns/a: not ready", "Job ns/b: running"}, nil)
+		result = sut.Process(mc)
+		assert.False(t, result)
+	})
+
+	t.Run("verifying marks packages degraded after the ready timeout", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateVerifying
+		mc.Package.Spec.ReadyTimeout = &metav1.Duration{Duration: time.Minute}
+		attempted := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+		mc.Package.Status.LastAttemptTime = &attempted
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().NotReady(mc.Ctx, mc.Package.Name).Return([]string{"Deployment ns/a: not ready"}, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateDegraded, expectedEmptySource, retryLong, "Workloads not ready: Deployment ns/a: not ready")
+		thenCondition(t, mc, api.ReadyCondition, metav1.ConditionFalse, "Degraded")
+		thenEvents(t, mc, "Warning Degraded Workloads not ready after 1m0s: Deployment ns/a: not ready")
+	})
+
+	t.Run("verifying fails if readiness can't be checked", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateVerifying
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().NotReady(mc.Ctx, mc.Package.Name).Return(nil, errors.New("oops"))
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateVerifying, expectedEmptySource, retryShort, "oops")
+	})
+
+	t.Run("degraded recovers once workloads are ready", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateDegraded
+		mc.Package.Status.Source = mc.Source
+		mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().NotReady(mc.Ctx, mc.Package.Name).Return(nil, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, retryNow, "")
+	})
+
+	t.Run("degraded stays degraded while workloads aren't ready", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateDegraded
+		mc.Package.Status.Detail = "Workloads not ready: Deployment ns/a: not ready"
+		mc.Package.Status.Reason = api.FailureReasonWorkloadsNotReady
+		mc.Package.Status.Source = mc.Source
+		mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().NotReady(mc.Ctx, mc.Package.Name).Return([]string{"Deployment ns/a: not ready"}, nil)
+		result := sut.Process(mc)
+		assert.False(t, result)
+		assert.Equal(t, api.FailureReasonWorkloadsNotReady, mc.Package.Status.Reason)
+		thenManagerContext(t, mc, api.StateDegraded, expectedSource, retryLong, "Workloads not ready: Deployment ns/a: not ready")
+	})
+
+	t.Run("degraded is upgraded when the source changes", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateDegraded
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 60*time.Second, "Deprecated package namespace. Move to eksa-packages-franky")
+		thenManagerContext(t, mc, api.StateUpdating, expectedSource, retryShort, "")
+	})
+
+	t.Run("degraded is updated when the spec changes", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateDegraded
+		mc.Package.Status.Source = expectedSource
+		mc.Package.Spec.Config = newConfiguration
+		mc.PBC.Spec.MaintenanceWindows = []api.MaintenanceWindow{{
+			Schedule: fmt.Sprintf("0 %d * * *", time.Now().UTC().Add(12*time.Hour).Hour()),
+			Duration: metav1.Duration{Duration: time.Hour},
+		}}
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUpdating, expectedSource, retryNow, "")
 	})
 
 	t.Run("installing with namespace creation enabled creates the namespace", func(t *testing.T) {
@@ -320,163 +658,1546 @@ func TestManagerLifecycle(t *testing.T) {
 		mc.PBC.Spec.CreateNamespace = true
 		mc.Package.Status.State = api.StateInstalling
 		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
-		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, true, mc.Source, gomock.Any()).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, true, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil)
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 60*time.Second, "")
+		thenManagerContext(t, mc, api.StateVerifying, expectedSource, retryReadyCheck, "")
 	})
 
-	t.Run("installing initialize fails", func(t *testing.T) {
+	t.Run("installing passes the install options over those of the bundle controller", func(t *testing.T) {
 		mc, mockDriver := givenMocks(t)
+		yes, no := true, false
+		mc.PBC.Spec.InstallOptions = &api.InstallOptions{Wait: &yes, Atomic: &yes}
+		mc.Package.Spec.InstallOptions = &api.InstallOptions{Atomic: &no, Timeout: &metav1.Duration{Duration: time.Hour}}
+		mc.Package.Status.Spec = mc.Package.Spec
 		mc.Package.Status.State = api.StateInstalling
-		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(fmt.Errorf("boom"))
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(),
+			api.InstallOptions{Wait: &yes, Atomic: &no, Timeout: &metav1.Duration{Duration: time.Hour}}).Return(1, nil)
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateInstalling, expectedSource, 60*time.Second, "boom")
+		thenManagerContext(t, mc, api.StateVerifying, expectedSource, retryReadyCheck, "")
 	})
 
-	t.Run("installing install fails", func(t *testing.T) {
+	t.Run("installing verifies the images against the bundle", func(t *testing.T) {
 		mc, mockDriver := givenMocks(t)
 		mc.Package.Status.State = api.StateInstalling
+		mc.Version.Images = []api.VersionImages{{Repository: "hello", Digest: "sha256:1111"}}
+		registry := mc.PBC.GetDefaultImageRegistry()
 		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
-		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any()).Return(fmt.Errorf("boom"))
+		mockDriver.EXPECT().Plan(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, mc.Source, gomock.Any()).
+			Return(&driver.Plan{Images: []string{registry + "/hello@sha256:1111"}}, nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil)
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateInstalling, expectedSource, 60*time.Second, "boom")
+		thenManagerContext(t, mc, api.StateVerifying, expectedSource, retryReadyCheck, "")
+		thenCondition(t, mc, api.ImagesVerifiedCondition, metav1.ConditionTrue, "ImagesVerified")
 	})
 
-	t.Run("installed upgrade triggered", func(t *testing.T) {
-		mc, _ := givenMocks(t)
-		mc.Package.Status.State = api.StateInstalled
-		mc.Package.Status.Source = expectedSource
-		mc.Source = expectedUpdate
+	t.Run("installing reports a bundle listing no images to verify", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil)
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateUpdating, expectedUpdate, 30*time.Second, "")
+		thenManagerContext(t, mc, api.StateVerifying, expectedSource, retryReadyCheck, "")
+		thenCondition(t, mc, api.ImagesVerifiedCondition, metav1.ConditionUnknown, "NoImagesListed")
 	})
 
-	t.Run("installed configuration update", func(t *testing.T) {
+	t.Run("installing fails for images not in the bundle", func(t *testing.T) {
 		mc, mockDriver := givenMocks(t)
-		mc.Package.Status.State = api.StateInstalled
-		mc.Package.Status.Source = expectedSource
-		mc.Source = expectedSource
-		mc.Package.Spec.Config = newConfiguration
+		mc.Package.Status.State = api.StateInstalling
+		mc.Version.Images = []api.VersionImages{{Repository: "hello", Digest: "sha256:1111"}}
+		registry := mc.PBC.GetDefaultImageRegistry()
 		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
-		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(true, nil)
+		mockDriver.EXPECT().Plan(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, mc.Source, gomock.Any()).
+			Return(&driver.Plan{Images: []string{registry + "/hello@sha256:1111", registry + "/hello@sha256:2222", "docker.io/evil:latest"}}, nil)
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateUpdating, expectedSource, 30*time.Second, "")
+		detail := "images of " + mc.Package.Name + " not in the bundle for registry " + registry + ": " +
+			registry + "/hello@sha256:2222, docker.io/evil:latest"
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryShort, detail)
+		assert.Equal(t, api.FailureReasonUnexpectedImages, mc.Package.Status.Reason)
+		assert.Equal(t, int32(1), mc.Package.Status.RetryCount)
+		thenEvents(t, mc, "Warning UnexpectedImages "+detail)
+		thenCondition(t, mc, api.ImagesVerifiedCondition, metav1.ConditionFalse, "UnexpectedImages")
+
+		mc.Package.Status.LastAttemptTime = nil
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		result = sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryShort*2, detail)
+		assert.Equal(t, api.FailureReasonUnexpectedImages, mc.Package.Status.Reason)
+		assert.Equal(t, int32(2), mc.Package.Status.RetryCount)
 	})
 
-	t.Run("installed no configuration change", func(t *testing.T) {
+	t.Run("installing retries without verifying the same images again", func(t *testing.T) {
 		mc, mockDriver := givenMocks(t)
-		mc.Package.Status.State = api.StateInstalled
-		mc.Package.Status.Source = expectedSource
-		mc.Source = expectedSource
-		mc.Package.Spec.Config = originalConfiguration
+		mc.Package.Status.State = api.StateInstalling
+		mc.Version.Images = []api.VersionImages{{Repository: "hello", Digest: "sha256:1111"}}
+		registry := mc.PBC.GetDefaultImageRegistry()
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil).Times(3)
+		mockDriver.EXPECT().Plan(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, mc.Source, gomock.Any()).
+			Return(&driver.Plan{Images: []string{registry + "/hello@sha256:1111"}}, nil).Times(2)
+		gomock.InOrder(
+			mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(0, fmt.Errorf("boom")),
+			mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(0, fmt.Errorf("boom")),
+			mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil),
+		)
+
+		sut.Process(mc)
+		mc.Package.Status.LastAttemptTime = nil
+		sut.Process(mc)
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryShort*2, "boom")
+		verified := mc.Package.Status.ImagesVerifiedHash
+		assert.NotEmpty(t, verified)
+
+		mc.Package.Spec.Config = "greeting: hi"
+		mc.Package.Status.LastAttemptTime = nil
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateVerifying, expectedSource, retryReadyCheck, "")
+		assert.NotEqual(t, verified, mc.Package.Status.ImagesVerifiedHash)
+		thenCondition(t, mc, api.ImagesVerifiedCondition, metav1.ConditionTrue, "ImagesVerified")
+	})
+
+	t.Run("installing fails when the images can't be rendered", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Version.Images = []api.VersionImages{{Repository: "hello", Digest: "sha256:1111"}}
 		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
-		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(false, nil)
+		mockDriver.EXPECT().Plan(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, mc.Source, gomock.Any()).
+			Return(nil, fmt.Errorf("boom"))
 		result := sut.Process(mc)
-		assert.False(t, result)
-		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 180*time.Second, "")
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryShort, "rendering "+mc.Package.Name+" to verify its images: boom")
 	})
 
-	t.Run("installed initialize error", func(t *testing.T) {
+	t.Run("installing initialize fails", func(t *testing.T) {
 		mc, mockDriver := givenMocks(t)
-		mc.Package.Status.State = api.StateInstalled
-		mc.Package.Status.Source = expectedSource
-		mc.Source = expectedSource
-		mc.Package.Spec.Config = newConfiguration
+		mc.Package.Status.State = api.StateInstalling
 		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(fmt.Errorf("boom"))
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 60*time.Second, "boom")
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryShort, "boom")
+		assert.Equal(t, api.FailureReasonClusterUnreachable, mc.Package.Status.Reason)
+		assert.Equal(t, int32(1), mc.Package.Status.RetryCount)
+		assert.NotNil(t, mc.Package.Status.LastAttemptTime)
 	})
 
-	t.Run("installed IsConfigChanged error", func(t *testing.T) {
+	t.Run("installing install fails", func(t *testing.T) {
 		mc, mockDriver := givenMocks(t)
-		mc.Package.Status.State = api.StateInstalled
-		mc.Package.Status.Source = expectedSource
-		mc.Source = expectedSource
-		mc.Package.Spec.Config = newConfiguration
+		mc.Package.Status.State = api.StateInstalling
 		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
-		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(true, fmt.Errorf("boom"))
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(0, fmt.Errorf("boom"))
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 60*time.Second, "boom")
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryShort, "boom")
+		assert.Equal(t, api.FailureReasonInstallFailed, mc.Package.Status.Reason)
+		assert.Equal(t, int32(1), mc.Package.Status.RetryCount)
+		assert.NotNil(t, mc.Package.Status.LastAttemptTime)
 	})
 
-	t.Run("installed configuration parse error", func(t *testing.T) {
-		mc, _ := givenMocks(t)
-		mc.Package.Status.State = api.StateInstalled
-		mc.Package.Status.Source = expectedSource
-		mc.Source = expectedSource
-		mc.Package.Spec.Config = "bogus configuration ---- what ever"
+	t.Run("installing install fails with the reason of the driver", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).
+			Return(0, failure.Errorf(api.FailureReasonRegistryAuthFailed, "unauthorized"))
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 30*time.Second, "error unmarshaling JSON: while decoding JSON: json: cannot unmarshal string into Go value of type map[string]interface {}")
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryShort, "unauthorized")
+		assert.Equal(t, api.FailureReasonRegistryAuthFailed, mc.Package.Status.Reason)
 	})
 
-	t.Run("Uninstalling works", func(t *testing.T) {
+	t.Run("installing success clears the failure reason", func(t *testing.T) {
 		mc, mockDriver := givenMocks(t)
-		mc.SetUninstalling(api.PackageNamespace+"-"+clusterName, clusterName)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.Detail = "boom"
+		mc.Package.Status.Reason = api.FailureReasonInstallFailed
 		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
-		mockDriver.EXPECT().Uninstall(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil)
 		result := sut.Process(mc)
-		assert.False(t, result)
-		thenManagerContext(t, mc, api.StateUninstalling, expectedEmptySource, time.Duration(0), "")
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateVerifying, expectedSource, retryReadyCheck, "")
+		assert.Empty(t, mc.Package.Status.Reason)
 	})
 
-	t.Run("Uninstalling initialize fails", func(t *testing.T) {
+	t.Run("installing upgrade fails rolls back", func(t *testing.T) {
 		mc, mockDriver := givenMocks(t)
-		mc.SetUninstalling(api.PackageNamespace+"-"+clusterName, clusterName)
-		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(fmt.Errorf("init crunch"))
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		mc.Source.Version = "0.2.0"
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(0, fmt.Errorf("boom"))
+		mockDriver.EXPECT().Rollback(mc.Ctx, mc.Package.Name, api.InstallOptions{}).Return(true, nil)
 		result := sut.Process(mc)
-		assert.False(t, result)
-		thenManagerContext(t, mc, api.StateUninstalling, expectedEmptySource, 60*time.Second, "init crunch")
+		assert.True(t, result)
+		assert.Equal(t, api.StateInstalling, mc.Package.Status.State)
+		assert.Equal(t, "0.1.0", mc.Package.Status.CurrentVersion)
+		assert.Equal(t, "boom", mc.Package.Status.Detail)
+		if assert.NotNil(t, mc.Package.Status.RolledBack) {
+			assert.Equal(t, "0.1.0", mc.Package.Status.RolledBack.Version)
+			assert.Equal(t, "0.2.0", mc.Package.Status.RolledBack.FailedVersion)
+			assert.Equal(t, "boom", mc.Package.Status.RolledBack.Reason)
+		}
+		thenEvents(t, mc,
+			"Warning UpgradeFailed Upgrade to 0.2.0 failed: boom",
+			"Warning RolledBack Rolled back to 0.1.0 after upgrade to 0.2.0 failed")
 	})
 
-	t.Run("Uninstalling fails", func(t *testing.T) {
+	t.Run("installing upgrade records an atomic rollback", func(t *testing.T) {
 		mc, mockDriver := givenMocks(t)
-		mc.SetUninstalling(api.PackageNamespace+"-"+clusterName, clusterName)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		mc.Source.Version = "0.2.0"
+		atomic := true
+		mc.Package.Spec.InstallOptions = &api.InstallOptions{Atomic: &atomic}
+		upgradeErr := fmt.Errorf("upgrading helm chart %s: %w: boom", mc.Package.Name, driver.ErrRolledBack)
 		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
-		mockDriver.EXPECT().Uninstall(mc.Ctx, clusterName).Return(fmt.Errorf("crunch"))
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{Atomic: &atomic}).Return(0, upgradeErr)
 		result := sut.Process(mc)
-		assert.False(t, result)
-		thenManagerContext(t, mc, api.StateUninstalling, expectedEmptySource, 60*time.Second, "crunch")
+		assert.True(t, result)
+		if assert.NotNil(t, mc.Package.Status.RolledBack) {
+			assert.Equal(t, "0.1.0", mc.Package.Status.RolledBack.Version)
+			assert.Equal(t, "0.2.0", mc.Package.Status.RolledBack.FailedVersion)
+			assert.Equal(t, upgradeErr.Error(), mc.Package.Status.RolledBack.Reason)
+		}
+		thenEvents(t, mc,
+			"Warning UpgradeFailed Upgrade to 0.2.0 failed: "+upgradeErr.Error(),
+			"Warning RolledBack Rolled back to 0.1.0 after upgrade to 0.2.0 failed")
 	})
 
-	t.Run("Bogus state is reported", func(t *testing.T) {
-		mc, _ := givenMocks(t)
-		mc.Package.Status.State = "bogus"
+	t.Run("installing upgrade rolls back with the install options", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		maxHistory := 5
+		mc.PBC.Spec.InstallOptions = &api.InstallOptions{MaxHistory: &maxHistory}
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{MaxHistory: &maxHistory}).Return(0, fmt.Errorf("boom"))
+		mockDriver.EXPECT().Rollback(mc.Ctx, mc.Package.Name, api.InstallOptions{MaxHistory: &maxHistory}).Return(true, nil)
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, "bogus", expectedEmptySource, time.Duration(0), "Unknown state: bogus")
+		assert.NotNil(t, mc.Package.Status.RolledBack)
 	})
 
-	t.Run("Unknown state is ignored", func(t *testing.T) {
-		mc, _ := givenMocks(t)
-		mc.Package.Status.State = api.StateUnknown
+	t.Run("installing uninstalls a package from the driver it moved from", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		mc.Package.Status.InstalledDriver = api.DriverManifest
+		installedDriver := givenMockDriver(t)
+		mc.InstalledDriver = installedDriver
+		gomock.InOrder(
+			mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil),
+			installedDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil),
+			installedDriver.EXPECT().Uninstall(mc.Ctx, mc.Package.Name).Return(nil),
+			mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil),
+		)
 		result := sut.Process(mc)
-		assert.False(t, result)
-		thenManagerContext(t, mc, api.StateUnknown, expectedEmptySource, time.Duration(0), "")
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateVerifying, expectedSource, retryReadyCheck, "")
+		assert.Equal(t, api.DriverHelm, mc.Package.Status.InstalledDriver)
+		assert.Nil(t, mc.InstalledDriver)
+		thenEvents(t, mc,
+			"Normal Uninstalled Uninstalled 0.1.0 to change its driver",
+			"Normal Upgraded Upgraded from 0.1.0 to "+mc.Source.Version)
 	})
 
-	t.Run("Package in wrong namespace should be ignored", func(t *testing.T) {
-		mc, _ := givenMocks(t)
-		mc.Package.Status.State = ""
-		mc.Package.Namespace = "default"
+	t.Run("installing fails when the driver a package moved from can't uninstall it", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		mc.Package.Status.InstalledDriver = api.DriverManifest
+		installedDriver := givenMockDriver(t)
+		mc.InstalledDriver = installedDriver
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		installedDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		installedDriver.EXPECT().Uninstall(mc.Ctx, mc.Package.Name).Return(fmt.Errorf("boom"))
 		result := sut.Process(mc)
 		assert.True(t, result)
-		thenManagerContext(t, mc, api.StateUnknown, expectedEmptySource, time.Duration(0), "Packages namespaces must start with: eksa-packages")
+		detail := "uninstalling " + mc.Package.Name + " from its previous driver: boom"
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryShort, detail)
+		assert.Equal(t, api.FailureReasonUninstallFailed, mc.Package.Status.Reason)
+		assert.Equal(t, api.DriverManifest, mc.Package.Status.InstalledDriver)
+		thenEvents(t, mc, "Warning UninstallFailed Uninstall of 0.1.0 before changing its driver failed: "+detail)
 	})
 
-	t.Run("Package in wrong namespace should be ignored again", func(t *testing.T) {
-		mc, _ := givenMocks(t)
-		mc.Package.Status.State = api.StateUnknown
-		mc.Package.Namespace = "default"
+	t.Run("installing upgrade succeeds", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		mc.Source.Version = "0.2.0"
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil)
 		result := sut.Process(mc)
-		assert.False(t, result)
-		thenManagerContext(t, mc, api.StateUnknown, expectedEmptySource, time.Duration(0), "Packages namespaces must start with: eksa-packages")
+		assert.True(t, result)
+		assert.Equal(t, api.StateVerifying, mc.Package.Status.State)
+		assert.Equal(t, "0.2.0", mc.Package.Status.CurrentVersion)
+		thenEvents(t, mc, "Normal Upgraded Upgraded from 0.1.0 to 0.2.0")
 	})
+
+	t.Run("installing with manual approval waits for the plan to be approved", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Spec.Approval = api.ApprovalManual
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		mc.Source.Version = "0.2.0"
+		changes := []string{"Deployment eksa-packages/hello: spec.template.spec.containers[0].image changed"}
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil).Times(2)
+		mockDriver.EXPECT().Plan(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, mc.Source, gomock.Any()).
+			Return(&driver.Plan{Changes: changes}, nil)
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		assert.Equal(t, api.StatePendingApproval, mc.Package.Status.State)
+		assert.Equal(t, retryLong, mc.RequeueAfter)
+		plan := mc.Package.Status.Plan
+		if assert.NotNil(t, plan) {
+			assert.Len(t, plan.Hash, hashLength)
+			assert.Equal(t, "0.1.0", plan.FromVersion)
+			assert.Equal(t, "0.2.0", plan.ToVersion)
+			assert.Equal(t, changes, plan.Changes)
+			assert.Equal(t, "Waiting for approval: set the anywhere.eks.aws.com/approve annotation to "+plan.Hash, mc.Package.Status.Detail)
+			thenEvents(t, mc, "Normal PlanPending Plan "+plan.Hash+" to install 0.2.0: "+changes[0])
+		}
+		thenCondition(t, mc, api.ReadyCondition, metav1.ConditionTrue, "PendingApproval")
+		assert.Nil(t, mc.Package.Status.LastAttemptTime)
+
+		result = sut.Process(mc)
+
+		assert.False(t, result)
+		assert.Equal(t, api.StatePendingApproval, mc.Package.Status.State)
+		assert.Equal(t, retryLong, mc.RequeueAfter)
+
+		mc.Package.Annotations = map[string]string{api.ApproveAnnotation: plan.Hash}
+		result = sut.Process(mc)
+
+		assert.True(t, result)
+		assert.Equal(t, api.StateInstalling, mc.Package.Status.State)
+		assert.Equal(t, retryNow, mc.RequeueAfter)
+		thenEvents(t, mc, "Normal PlanApproved Plan "+plan.Hash+" approved")
+
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil)
+		result = sut.Process(mc)
+
+		assert.True(t, result)
+		assert.Equal(t, api.StateVerifying, mc.Package.Status.State)
+		assert.Equal(t, "0.2.0", mc.Package.Status.CurrentVersion)
+		assert.Nil(t, mc.Package.Status.Plan)
+		thenEvents(t, mc, "Normal Upgraded Upgraded from 0.1.0 to 0.2.0")
+	})
+
+	t.Run("installing with manual approval plans again when the configuration changes", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Spec.Approval = api.ApprovalManual
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		mc.Source.Version = "0.1.0"
+		mc.Package.Status.Plan = &api.PackagePlan{Hash: "0123456789ab", ToVersion: "0.1.0"}
+		mc.Package.Annotations = map[string]string{api.ApproveAnnotation: "0123456789ab"}
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Plan(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, mc.Source, gomock.Any()).
+			Return(&driver.Plan{Changes: []string{"ConfigMap eksa-packages/hello: data.greeting changed"}}, nil)
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		assert.Equal(t, api.StatePendingApproval, mc.Package.Status.State)
+		assert.NotEqual(t, "0123456789ab", mc.Package.Status.Plan.Hash)
+		thenEvents(t, mc, "Normal PlanPending Plan "+mc.Package.Status.Plan.Hash+" to install 0.1.0: ConfigMap eksa-packages/hello: data.greeting changed")
+	})
+
+	t.Run("installing with manual approval proceeds when nothing would change", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Spec.Approval = api.ApprovalManual
+		mc.Package.Status.State = api.StateInstalling
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Plan(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, mc.Source, gomock.Any()).
+			Return(&driver.Plan{}, nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil)
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateVerifying, expectedSource, retryReadyCheck, "")
+	})
+
+	t.Run("installing with manual approval fails to plan", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Spec.Approval = api.ApprovalManual
+		mc.Package.Status.State = api.StateInstalling
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Plan(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, mc.Source, gomock.Any()).
+			Return(nil, fmt.Errorf("boom"))
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryLong, "boom")
+		assert.Nil(t, mc.Package.Status.Plan)
+	})
+
+	t.Run("approval leaves the values to install unchanged", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Spec.Approval = api.ApprovalManual
+		values := map[string]interface{}{"greeting": "hello"}
+		mockDriver.EXPECT().Plan(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, mc.Source, values).
+			Return(&driver.Plan{Changes: []string{"ConfigMap eksa-packages/hello: added"}}, nil)
+
+		approved := mc.approved(values)
+
+		assert.False(t, approved)
+		assert.Equal(t, map[string]interface{}{"greeting": "hello"}, values)
+	})
+
+	t.Run("pending approval plans again when the source changes", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Spec.Approval = api.ApprovalManual
+		mc.Package.Status.State = api.StatePendingApproval
+		mc.Package.Status.Source = expectedUpdate
+		mc.Package.Status.Plan = &api.PackagePlan{Hash: "0123456789ab"}
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalling, expectedUpdate, retryNow, "")
+		thenEvents(t, mc)
+	})
+
+	t.Run("installing upgrade fails with nothing to roll back", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(0, fmt.Errorf("boom"))
+		mockDriver.EXPECT().Rollback(mc.Ctx, mc.Package.Name, api.InstallOptions{}).Return(false, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryShort, "boom")
+		assert.Nil(t, mc.Package.Status.RolledBack)
+	})
+
+	t.Run("installing upgrade rollback fails", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(0, fmt.Errorf("boom"))
+		mockDriver.EXPECT().Rollback(mc.Ctx, mc.Package.Name, api.InstallOptions{}).Return(false, fmt.Errorf("crunch"))
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, retryShort, "boom")
+		assert.Nil(t, mc.Package.Status.RolledBack)
+	})
+
+	t.Run("installing success clears rollback", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.RolledBack = &api.PackageRollback{Version: "0.1.0"}
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		assert.Nil(t, mc.Package.Status.RolledBack)
+	})
+
+	t.Run("installing install fails backs off exponentially", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.RetryCount = 2
+		lastAttempt := metav1.NewTime(time.Now().Add(-5 * time.Minute))
+		mc.Package.Status.LastAttemptTime = &lastAttempt
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(0, fmt.Errorf("boom"))
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalling, expectedSource, 4*retryShort, "boom")
+		assert.Equal(t, int32(3), mc.Package.Status.RetryCount)
+	})
+
+	t.Run("installing waits for backoff", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.RetryCount = 2
+		lastAttempt := metav1.Now()
+		mc.Package.Status.LastAttemptTime = &lastAttempt
+		result := sut.Process(mc)
+		assert.False(t, result)
+		assert.Equal(t, api.StateInstalling, mc.Package.Status.State)
+		assert.Greater(t, mc.RequeueAfter, retryShort)
+		assert.LessOrEqual(t, mc.RequeueAfter, 2*retryShort)
+	})
+
+	t.Run("installing install fails too many times", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.RetryCount = maxInstallRetries - 1
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(0, fmt.Errorf("boom"))
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateFailed, expectedSource, retryNever, "boom")
+		assert.Equal(t, int32(maxInstallRetries), mc.Package.Status.RetryCount)
+		assert.Equal(t, mc.Package.Spec, mc.Package.Status.Spec)
+	})
+
+	t.Run("installing success resets retries", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.RetryCount = 1
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateVerifying, expectedSource, retryReadyCheck, "")
+		assert.Equal(t, int32(0), mc.Package.Status.RetryCount)
+	})
+
+	t.Run("installing records revisions", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Source.Version = "0.1.0"
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil).Times(2)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(0, fmt.Errorf("boom"))
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(3, nil)
+
+		sut.Process(mc)
+		mc.Package.Status.LastAttemptTime = nil
+		sut.Process(mc)
+
+		history := mc.Package.Status.History
+		if assert.Len(t, history, 2) {
+			assert.Equal(t, int64(1), history[0].Revision)
+			assert.Equal(t, api.RevisionResultFailed, history[0].Result)
+			assert.Equal(t, "boom", history[0].Detail)
+			assert.Equal(t, 0, history[0].HelmRevision)
+			assert.Equal(t, int64(2), history[1].Revision)
+			assert.Equal(t, api.RevisionResultSucceeded, history[1].Result)
+			assert.Equal(t, "0.1.0", history[1].Version)
+			assert.Equal(t, expectedSource.Digest, history[1].Digest)
+			assert.Equal(t, packageBundleName, history[1].Bundle)
+			assert.Len(t, history[1].ConfigHash, hashLength)
+			assert.Equal(t, history[0].ConfigHash, history[1].ConfigHash)
+			assert.Equal(t, 3, history[1].HelmRevision)
+			assert.False(t, history[1].Time.IsZero())
+		}
+	})
+
+	t.Run("installing keeps a bounded history", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		for i := int64(1); i <= maxRevisionHistory; i++ {
+			mc.Package.Status.History = append(mc.Package.Status.History, api.PackageRevision{Revision: i})
+		}
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(1, nil)
+
+		sut.Process(mc)
+
+		history := mc.Package.Status.History
+		assert.Len(t, history, maxRevisionHistory)
+		assert.Equal(t, int64(2), history[0].Revision)
+		assert.Equal(t, int64(maxRevisionHistory+1), history[len(history)-1].Revision)
+	})
+
+	t.Run("failed is not retried", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateFailed
+		mc.Package.Status.Source = expectedSource
+		mc.Package.Status.Detail = "boom"
+		mc.Package.Status.RetryCount = maxInstallRetries
+		mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
+		result := sut.Process(mc)
+		assert.False(t, result)
+		thenManagerContext(t, mc, api.StateFailed, expectedSource, retryNever, "boom")
+	})
+
+	t.Run("failed is retried when the spec changes", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateFailed
+		mc.Package.Status.Source = expectedSource
+		mc.Package.Status.Detail = "boom"
+		mc.Package.Status.RetryCount = maxInstallRetries
+		mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
+		mc.Package.Spec.Config = newConfiguration
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstallingDependencies, expectedSource, retryNow, "")
+		assert.Equal(t, int32(0), mc.Package.Status.RetryCount)
+	})
+
+	t.Run("failed is retried when the source changes", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateFailed
+		mc.Package.Status.Source = expectedSource
+		mc.Package.Status.RetryCount = maxInstallRetries
+		mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
+		mc.Source = expectedUpdate
+		result := sut.Process(mc)
+		assert.True(t, result)
+		assert.Equal(t, api.StateInstallingDependencies, mc.Package.Status.State)
+		assert.Equal(t, int32(0), mc.Package.Status.RetryCount)
+	})
+
+	t.Run("retry annotation resets a failed package", func(t *testing.T) {
+		mc, mockClient := givenMocksWithClient(t)
+		mc.Package.Status.State = api.StateFailed
+		mc.Package.Status.Source = expectedSource
+		mc.Package.Status.RetryCount = maxInstallRetries
+		mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
+		mc.Package.Annotations = map[string]string{api.RetryAnnotation: "true"}
+		mockClient.EXPECT().Update(mc.Ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+				assert.NotContains(t, obj.GetAnnotations(), api.RetryAnnotation)
+				return nil
+			})
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstallingDependencies, expectedSource, retryNow, "")
+		assert.Equal(t, int32(0), mc.Package.Status.RetryCount)
+	})
+
+	t.Run("retry annotation skips the backoff", func(t *testing.T) {
+		mc, mockClient := givenMocksWithClient(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.Package.Status.RetryCount = 3
+		lastAttempt := metav1.Now()
+		mc.Package.Status.LastAttemptTime = &lastAttempt
+		mc.Package.Annotations = map[string]string{api.RetryAnnotation: "true"}
+		mockClient.EXPECT().Update(mc.Ctx, gomock.Any(), gomock.Any()).Return(nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		assert.Equal(t, api.StateInstallingDependencies, mc.Package.Status.State)
+		assert.Equal(t, retryNow, mc.RequeueAfter)
+		assert.Equal(t, int32(0), mc.Package.Status.RetryCount)
+	})
+
+	t.Run("retry annotation removal fails", func(t *testing.T) {
+		mc, mockClient := givenMocksWithClient(t)
+		mc.Package.Status.State = api.StateFailed
+		mc.Package.Status.Source = expectedSource
+		mc.Package.Status.RetryCount = maxInstallRetries
+		mc.Package.Annotations = map[string]string{api.RetryAnnotation: "true"}
+		mockClient.EXPECT().Update(mc.Ctx, gomock.Any(), gomock.Any()).Return(errors.New("crunch"))
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateFailed, expectedSource, retryShort, "crunch")
+		assert.Equal(t, int32(maxInstallRetries), mc.Package.Status.RetryCount)
+	})
+
+	t.Run("installed upgrade triggered", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedUpdate
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUpdating, expectedUpdate, 30*time.Second, "")
+	})
+
+	closedWindow := func() api.MaintenanceWindow {
+		opens := time.Now().UTC().Add(12 * time.Hour)
+		return api.MaintenanceWindow{
+			Schedule: fmt.Sprintf("0 %d * * *", opens.Hour()),
+			Duration: metav1.Duration{Duration: time.Hour},
+		}
+	}
+	openWindow := api.MaintenanceWindow{Schedule: "* * * * *", Duration: metav1.Duration{Duration: time.Hour}}
+
+	t.Run("installed upgrade held until the maintenance window", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		from, to := expectedSource, expectedUpdate
+		from.Version, to.Version = "1.0.0", "2.0.0"
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = from
+		mc.Source = to
+		mc.PBC.Spec.MaintenanceWindows = []api.MaintenanceWindow{closedWindow()}
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		assert.Equal(t, api.StateUpgradePending, mc.Package.Status.State)
+		assert.Equal(t, from, mc.Package.Status.Source)
+		assert.Equal(t, "Upgrade to 2.0.0 pending until the next maintenance window", mc.Package.Status.Detail)
+		if assert.NotNil(t, mc.Package.Status.NextMaintenanceWindow) {
+			assert.Equal(t, 0, mc.Package.Status.NextMaintenanceWindow.Minute())
+			assert.WithinDuration(t, time.Now().Add(12*time.Hour), mc.Package.Status.NextMaintenanceWindow.Time, time.Hour)
+		}
+		assert.Equal(t, retryVeryLong, mc.RequeueAfter)
+		thenCondition(t, mc, api.ReadyCondition, metav1.ConditionTrue, "UpgradePending")
+		thenEvents(t, mc, "Normal UpgradePending Upgrade from 1.0.0 to 2.0.0 pending until the next maintenance window")
+
+		result = sut.Process(mc)
+
+		assert.False(t, result)
+		assert.Equal(t, api.StateUpgradePending, mc.Package.Status.State)
+		thenEvents(t, mc)
+	})
+
+	t.Run("installed upgrade within the package maintenance window", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedUpdate
+		mc.PBC.Spec.MaintenanceWindows = []api.MaintenanceWindow{closedWindow()}
+		mc.Package.Spec.MaintenanceWindows = []api.MaintenanceWindow{openWindow}
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUpdating, expectedUpdate, retryShort, "")
+	})
+
+	t.Run("degraded upgrade held until the maintenance window", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		from, to := expectedSource, expectedUpdate
+		from.Version, to.Version = "1.0.0", "2.0.0"
+		mc.Package.Status.State = api.StateDegraded
+		mc.Package.Status.Source = from
+		mc.Package.Status.CurrentVersion = "1.0.0"
+		mc.Source = to
+		mc.PBC.Spec.MaintenanceWindows = []api.MaintenanceWindow{closedWindow()}
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUpgradePending, from, retryVeryLong, "Upgrade to 2.0.0 pending until the next maintenance window")
+		assert.NotNil(t, mc.Package.Status.NextMaintenanceWindow)
+		thenEvents(t, mc, "Normal UpgradePending Upgrade from 1.0.0 to 2.0.0 pending until the next maintenance window")
+	})
+
+	t.Run("failed upgrade held until the maintenance window", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		from, to := expectedSource, expectedUpdate
+		from.Version, to.Version = "1.0.0", "2.0.0"
+		mc.Package.Status.State = api.StateFailed
+		mc.Package.Status.Source = from
+		mc.Package.Status.CurrentVersion = "0.9.0"
+		mc.Package.Status.RetryCount = maxInstallRetries
+		mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
+		mc.Source = to
+		mc.PBC.Spec.MaintenanceWindows = []api.MaintenanceWindow{closedWindow()}
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUpgradePending, from, retryVeryLong, "Upgrade to 2.0.0 pending until the next maintenance window")
+		assert.NotNil(t, mc.Package.Status.NextMaintenanceWindow)
+	})
+
+	t.Run("failed is upgraded within the maintenance window", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateFailed
+		mc.Package.Status.Source = expectedSource
+		mc.Package.Status.CurrentVersion = "0.9.0"
+		mc.Package.Status.RetryCount = maxInstallRetries
+		mc.Package.Spec.DeepCopyInto(&mc.Package.Status.Spec)
+		mc.Source = expectedUpdate
+		mc.PBC.Spec.MaintenanceWindows = []api.MaintenanceWindow{openWindow}
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUpdating, expectedUpdate, retryShort, "")
+		assert.Equal(t, int32(0), mc.Package.Status.RetryCount)
+	})
+
+	t.Run("upgrade pending starts when the maintenance window opens", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		from, to := expectedSource, expectedUpdate
+		from.Version, to.Version = "1.0.0", "2.0.0"
+		mc.Package.Status.State = api.StateUpgradePending
+		mc.Package.Status.Source = from
+		mc.Package.Status.NextMaintenanceWindow = &metav1.Time{Time: time.Now()}
+		mc.Source = to
+		mc.PBC.Spec.MaintenanceWindows = []api.MaintenanceWindow{openWindow}
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUpdating, to, retryShort, "")
+		assert.Nil(t, mc.Package.Status.NextMaintenanceWindow)
+		thenEvents(t, mc, "Normal Upgrading Upgrading from 1.0.0 to 2.0.0")
+	})
+
+	t.Run("upgrade pending withdrawn", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateUpgradePending
+		mc.Package.Status.Source = expectedSource
+		mc.Package.Status.Detail = "Upgrade to  pending until the next maintenance window"
+		mc.Package.Status.NextMaintenanceWindow = &metav1.Time{Time: time.Now()}
+		mc.Source = expectedSource
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, retryNow, "")
+		assert.Nil(t, mc.Package.Status.NextMaintenanceWindow)
+	})
+
+	t.Run("upgrade held for invalid maintenance windows", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		from, to := expectedSource, expectedUpdate
+		from.Version, to.Version = "1.0.0", "2.0.0"
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = from
+		mc.Source = to
+		mc.PBC.Spec.MaintenanceWindows = []api.MaintenanceWindow{{Schedule: "bogus", Duration: metav1.Duration{Duration: time.Hour}}}
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUpgradePending, from, retryVeryLong,
+			`invalid maintenance window schedule "bogus": expected exactly 5 fields, found 1: [bogus]`)
+	})
+
+	givenHistory := func(mc *ManagerContext) {
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.CurrentVersion = "0.2.0"
+		mc.Package.Status.Source = mc.Source
+		mc.Package.Status.History = []api.PackageRevision{
+			{Revision: 1, Version: "0.1.0", Digest: "sha256:0.1.0", HelmRevision: 1, Result: api.RevisionResultSucceeded},
+			{Revision: 2, Version: "0.2.0", Digest: "sha256:0.2.0", Result: api.RevisionResultFailed},
+			{Revision: 3, Version: "0.2.0", Digest: "sha256:0.2.0", HelmRevision: 2, Result: api.RevisionResultSucceeded},
+		}
+	}
+
+	t.Run("installed rolls back to a revision from the spec", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Spec.RollbackTo = 1
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().RollbackTo(mc.Ctx, mc.Package.Name, 1, api.InstallOptions{}).Return(3, nil)
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		rolledBack := expectedSource
+		rolledBack.Version = "0.1.0"
+		rolledBack.Digest = "sha256:0.1.0"
+		thenManagerContext(t, mc, api.StateVerifying, rolledBack, retryReadyCheck, "")
+		assert.Equal(t, "0.1.0", mc.Package.Status.CurrentVersion)
+		history := mc.Package.Status.History
+		if assert.Len(t, history, 4) {
+			assert.Equal(t, int64(4), history[3].Revision)
+			assert.Equal(t, "0.1.0", history[3].Version)
+			assert.Equal(t, 3, history[3].HelmRevision)
+			assert.Equal(t, int64(1), history[3].RollbackOf)
+			assert.Equal(t, api.RevisionResultRolledBack, history[3].Result)
+		}
+		thenEvents(t, mc, "Normal RolledBack Rolled back to revision 1 of 0.1.0")
+
+		mc.Package.Status.State = api.StateInstalled
+		result = sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, rolledBack, retryVeryLong, "")
+		thenCondition(t, mc, api.ReadyCondition, metav1.ConditionTrue, "Installed")
+	})
+
+	t.Run("installed rollback to a revision not in the history", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Spec.RollbackTo = 7
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, retryNever, "Revision 7 is not in the history of the package")
+		assert.Equal(t, api.FailureReasonRollbackFailed, mc.Package.Status.Reason)
+		thenEvents(t, mc, "Warning RollbackFailed Revision 7 is not in the history of the package")
+
+		result = sut.Process(mc)
+
+		assert.False(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, retryNever, "Revision 7 is not in the history of the package")
+		thenEvents(t, mc)
+	})
+
+	t.Run("installed rollback to a failed revision", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Spec.RollbackTo = 2
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, retryNever, "Revision 2 has no release to roll back to")
+		thenEvents(t, mc, "Warning RollbackFailed Revision 2 has no release to roll back to")
+	})
+
+	t.Run("installed rollback fails", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Spec.RollbackTo = 1
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().RollbackTo(mc.Ctx, mc.Package.Name, 1, api.InstallOptions{}).Return(0, fmt.Errorf("boom"))
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, retryLong, "boom")
+		assert.Len(t, mc.Package.Status.History, 3)
+		thenEvents(t, mc, "Warning RollbackFailed Rollback to revision 1 failed: boom")
+	})
+
+	t.Run("installed rolls back to a revision with the install options", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Spec.RollbackTo = 1
+		maxHistory := 5
+		mc.Package.Spec.InstallOptions = &api.InstallOptions{MaxHistory: &maxHistory}
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().RollbackTo(mc.Ctx, mc.Package.Name, 1, api.InstallOptions{MaxHistory: &maxHistory}).Return(3, nil)
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		assert.Equal(t, api.StateVerifying, mc.Package.Status.State)
+	})
+
+	t.Run("installed rollback to a revision older than the kept release history", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Spec.RollbackTo = 1
+		notKept := fmt.Errorf("rolling back helm release %s to revision 1: %w", mc.Package.Name, driver.ErrRevisionNotKept)
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().RollbackTo(mc.Ctx, mc.Package.Name, 1, api.InstallOptions{}).Return(0, notKept)
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, retryLong, notKept.Error())
+		assert.Equal(t, 0, mc.Package.Status.History[0].HelmRevision)
+
+		result = sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, retryNever, "Revision 1 has no release to roll back to")
+	})
+
+	t.Run("installing marks revisions beyond the kept release history", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Status.State = api.StateInstalling
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{}).Return(3, nil)
+
+		sut.Process(mc)
+
+		history := mc.Package.Status.History
+		if assert.Len(t, history, 4) {
+			assert.Equal(t, 0, history[0].HelmRevision)
+			assert.Equal(t, 2, history[2].HelmRevision)
+			assert.Equal(t, 3, history[3].HelmRevision)
+		}
+	})
+
+	t.Run("installing keeps revisions within a longer release history", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Status.State = api.StateInstalling
+		maxHistory := 5
+		mc.Package.Spec.InstallOptions = &api.InstallOptions{MaxHistory: &maxHistory}
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Install(mc.Ctx, mc.Package.Name, mc.Package.Spec.TargetNamespace, false, mc.Source, gomock.Any(), api.InstallOptions{MaxHistory: &maxHistory}).Return(3, nil)
+
+		sut.Process(mc)
+
+		assert.Equal(t, 1, mc.Package.Status.History[0].HelmRevision)
+	})
+
+	t.Run("degraded rolls back to a revision from the spec", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Status.State = api.StateDegraded
+		mc.Package.Spec.RollbackTo = 1
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().RollbackTo(mc.Ctx, mc.Package.Name, 1, api.InstallOptions{}).Return(3, nil)
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		assert.Equal(t, api.StateVerifying, mc.Package.Status.State)
+		assert.Equal(t, "0.1.0", mc.Package.Status.CurrentVersion)
+	})
+
+	t.Run("failed rolls back to a revision from the spec", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Status.State = api.StateFailed
+		mc.Package.Status.Spec = mc.Package.Spec
+		mc.Package.Spec.RollbackTo = 1
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().RollbackTo(mc.Ctx, mc.Package.Name, 1, api.InstallOptions{}).Return(3, nil)
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		assert.Equal(t, api.StateVerifying, mc.Package.Status.State)
+		assert.Equal(t, "0.1.0", mc.Package.Status.CurrentVersion)
+		thenEvents(t, mc, "Normal RolledBack Rolled back to revision 1 of 0.1.0")
+	})
+
+	t.Run("failed rollback to a revision not in the history", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Status.State = api.StateFailed
+		mc.Package.Status.Spec = mc.Package.Spec
+		mc.Package.Spec.RollbackTo = 7
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateFailed, expectedSource, retryNever, "Revision 7 is not in the history of the package")
+		thenEvents(t, mc, "Warning RollbackFailed Revision 7 is not in the history of the package")
+	})
+
+	t.Run("upgrade pending withdrawn for a rollback", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		givenHistory(mc)
+		mc.Package.Status.State = api.StateUpgradePending
+		mc.Package.Status.Source = expectedUpdate
+		mc.Package.Spec.RollbackTo = 1
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedUpdate, retryNow, "")
+	})
+
+	t.Run("installed configuration update", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedSource
+		mc.Package.Spec.Config = newConfiguration
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(true, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUpdating, expectedSource, 30*time.Second, "")
+		thenEvents(t, mc, "Normal ConfigChanged Configuration changed, reinstalling ")
+	})
+
+	t.Run("installed no configuration change", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedSource
+		mc.Package.Spec.Config = originalConfiguration
+		mc.Package.SetCondition(api.ReadyCondition, metav1.ConditionTrue, "Installed", "")
+		mc.Package.SetCondition(api.ConfigValidCondition, metav1.ConditionTrue, "ValidConfig", "")
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(false, nil)
+		mockDriver.EXPECT().Drift(mc.Ctx, mc.Package.Name).Return(nil, nil)
+		result := sut.Process(mc)
+		assert.False(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 180*time.Second, "")
+	})
+
+	t.Run("installed reports available upgrades", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Package.Status.CurrentVersion = "v1.0.0"
+		mc.Source = expectedSource
+		mc.Package.Spec.Config = originalConfiguration
+		mc.Package.SetCondition(api.ReadyCondition, metav1.ConditionTrue, "Installed", "")
+		mc.Package.SetCondition(api.ConfigValidCondition, metav1.ConditionTrue, "ValidConfig", "")
+		mc.Bundle.Spec.Packages[0].Source.Versions = []api.SourceVersion{{Name: "v1.1.0", Digest: "sha256:110"}, {Name: "v1.0.0", Digest: "sha256:100"}}
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil).Times(2)
+		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(false, nil).Times(2)
+		mockDriver.EXPECT().Drift(mc.Ctx, mc.Package.Name).Return(nil, nil).Times(2)
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, retryVeryLong, "")
+		assert.Equal(t, []api.PackageAvailableUpgrade{{Version: "v1.1.0", Tag: "sha256:110"}}, mc.Package.Status.UpgradesAvailable)
+
+		result = sut.Process(mc)
+
+		assert.False(t, result)
+	})
+
+	t.Run("installed records drift", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedSource
+		mc.Package.Spec.Config = originalConfiguration
+		mc.Package.SetCondition(api.ReadyCondition, metav1.ConditionTrue, "Installed", "")
+		mc.Package.SetCondition(api.ConfigValidCondition, metav1.ConditionTrue, "ValidConfig", "")
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(false, nil)
+		mockDriver.EXPECT().Drift(mc.Ctx, mc.Package.Name).Return([]string{"Deployment ns/web: spec.replicas changed"}, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, retryVeryLong, "")
+		assert.Equal(t, []string{"Deployment ns/web: spec.replicas changed"}, mc.Package.Status.Drift)
+		thenEvents(t, mc, "Warning DriftDetected Resources drifted: Deployment ns/web: spec.replicas changed")
+
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(false, nil)
+		mockDriver.EXPECT().Drift(mc.Ctx, mc.Package.Name).Return([]string{"Deployment ns/web: spec.replicas changed"}, nil)
+		result = sut.Process(mc)
+		assert.False(t, result)
+		thenEvents(t, mc)
+
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(false, nil)
+		mockDriver.EXPECT().Drift(mc.Ctx, mc.Package.Name).Return(nil, nil)
+		result = sut.Process(mc)
+		assert.True(t, result)
+		assert.Empty(t, mc.Package.Status.Drift)
+	})
+
+	t.Run("installed ignores drift errors", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedSource
+		mc.Package.Spec.Config = originalConfiguration
+		mc.Package.Status.Drift = []string{"Deployment ns/web: deleted"}
+		mc.Package.SetCondition(api.ReadyCondition, metav1.ConditionTrue, "Installed", "")
+		mc.Package.SetCondition(api.ConfigValidCondition, metav1.ConditionTrue, "ValidConfig", "")
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(false, nil)
+		mockDriver.EXPECT().Drift(mc.Ctx, mc.Package.Name).Return(nil, errors.New("oops"))
+		result := sut.Process(mc)
+		assert.False(t, result)
+		assert.Equal(t, []string{"Deployment ns/web: deleted"}, mc.Package.Status.Drift)
+	})
+
+	t.Run("installed heals drift", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedSource
+		mc.Package.Spec.Config = originalConfiguration
+		mc.Package.Spec.HealDrift = true
+		mc.Package.Status.CurrentVersion = "0.1.0"
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(false, nil)
+		mockDriver.EXPECT().Drift(mc.Ctx, mc.Package.Name).Return([]string{"Deployment ns/web: deleted"}, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUpdating, expectedSource, retryShort, "")
+		thenEvents(t, mc,
+			"Warning DriftDetected Resources drifted: Deployment ns/web: deleted",
+			"Normal HealingDrift Reinstalling 0.1.0 to heal drift")
+	})
+
+	t.Run("installed sets missing conditions", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedSource
+		mc.Package.Spec.Config = originalConfiguration
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(false, nil)
+		mockDriver.EXPECT().Drift(mc.Ctx, mc.Package.Name).Return(nil, nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 180*time.Second, "")
+		thenCondition(t, mc, api.ReadyCondition, metav1.ConditionTrue, "Installed")
+		thenCondition(t, mc, api.ConfigValidCondition, metav1.ConditionTrue, "ValidConfig")
+	})
+
+	t.Run("installed initialize error", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedSource
+		mc.Package.Spec.Config = newConfiguration
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(fmt.Errorf("boom"))
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 60*time.Second, "boom")
+	})
+
+	t.Run("installed IsConfigChanged error", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedSource
+		mc.Package.Spec.Config = newConfiguration
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().IsConfigChanged(mc.Ctx, mc.Package.Name, gomock.Any()).Return(true, fmt.Errorf("boom"))
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 60*time.Second, "boom")
+	})
+
+	t.Run("installed configuration parse error", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Source = expectedSource
+		mc.Package.Spec.Config = "bogus configuration ---- what ever"
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, 30*time.Second, "error unmarshaling JSON: while decoding JSON: json: cannot unmarshal string into Go value of type map[string]interface {}")
+		thenCondition(t, mc, api.ConfigValidCondition, metav1.ConditionFalse, "InvalidConfig")
+	})
+
+	t.Run("suspended package skips the driver", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalled
+		mc.Package.Status.Source = expectedSource
+		mc.Package.Status.CurrentVersion = "test"
+		mc.Source = expectedUpdate
+		mc.Package.Spec.Suspend = true
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateSuspended, expectedSource, retryLong, "Reconciliation suspended")
+		thenCondition(t, mc, api.ReadyCondition, metav1.ConditionUnknown, "Suspended")
+		thenEvents(t, mc, "Normal Suspended Reconciliation suspended")
+
+		mc.Package.Annotations = map[string]string{api.RetryAnnotation: "true"}
+		result = sut.Process(mc)
+
+		assert.False(t, result)
+		thenManagerContext(t, mc, api.StateSuspended, expectedSource, retryLong, "Reconciliation suspended")
+		thenEvents(t, mc)
+	})
+
+	t.Run("suspended by the package bundle controller", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateInstalling
+		mc.PBC.Spec.Suspend = true
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateSuspended, PackageOCISource{}, retryLong, "Reconciliation of all packages suspended by the package bundle controller")
+	})
+
+	t.Run("resumes suspended package", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateSuspended
+		mc.Package.Status.Source = expectedSource
+		mc.Package.Status.CurrentVersion = "test"
+		mc.Package.Status.Detail = "Reconciliation suspended"
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInstalled, expectedSource, retryNow, "")
+		thenEvents(t, mc, "Normal Resumed Reconciliation resumed")
+	})
+
+	t.Run("resumes suspended package before it is installed", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateSuspended
+
+		result := sut.Process(mc)
+
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateInitializing, PackageOCISource{}, retryNow, "")
+	})
+
+	t.Run("Uninstalling suspended package works", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mockClient := givenMockClient(t)
+		mc.ManagerClient = bundle.NewManagerClient(mockClient)
+		mc.Package.Finalizers = []string{api.PackageFinalizer}
+		mc.Package.Spec.Suspend = true
+		mc.SetUninstalling()
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Uninstall(mc.Ctx, packageInstance).Return(nil)
+		mockClient.EXPECT().Update(mc.Ctx, &mc.Package, gomock.Any()).Return(nil)
+		result := sut.Process(mc)
+		assert.False(t, result)
+		assert.Empty(t, mc.Package.Finalizers)
+	})
+
+	t.Run("Uninstalling works", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mockClient := givenMockClient(t)
+		mc.ManagerClient = bundle.NewManagerClient(mockClient)
+		mc.Package.Finalizers = []string{api.PackageFinalizer}
+		mc.SetUninstalling()
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Uninstall(mc.Ctx, packageInstance).Return(nil)
+		mockClient.EXPECT().Update(mc.Ctx, &mc.Package, gomock.Any()).Return(nil)
+		result := sut.Process(mc)
+		assert.False(t, result)
+		thenManagerContext(t, mc, api.StateUninstalling, expectedEmptySource, time.Duration(0), "")
+		assert.Empty(t, mc.Package.Finalizers)
+		thenEvents(t, mc, "Normal Uninstalled Uninstalled")
+	})
+
+	t.Run("Uninstalling with cascade deletes dependents first", func(t *testing.T) {
+		mc, mockClient := givenMocksWithClient(t)
+		mc.Package.Spec.PackageName = "test-dep"
+		mc.Package.Annotations = map[string]string{api.CascadeDeleteAnnotation: "true"}
+		mc.SetUninstalling()
+		dependent := api.NewPackage(packageName, "my-dependent", mc.Package.Namespace, "")
+		dependent.Status.CurrentVersion = "test"
+		mockClient.EXPECT().List(mc.Ctx, gomock.AssignableToTypeOf(&api.PackageList{}), gomock.Eq(client.InNamespace(mc.Package.Namespace))).SetArg(1, api.PackageList{
+			Items: []api.Package{mc.Package, dependent},
+		})
+		mockClient.EXPECT().Delete(mc.Ctx, gomock.Eq(&dependent)).Return(nil)
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUninstalling, expectedEmptySource, retrySoon, "Waiting for dependents to be uninstalled: my-dependent")
+		thenEvents(t, mc, "Normal UninstallingDependents Uninstalling dependent my-dependent")
+	})
+
+	t.Run("Uninstalling with cascade waits for deleting dependents", func(t *testing.T) {
+		mc, mockClient := givenMocksWithClient(t)
+		mc.Package.Spec.PackageName = "test-dep"
+		mc.Package.Annotations = map[string]string{api.CascadeDeleteAnnotation: "true"}
+		mc.SetUninstalling()
+		dependent := api.NewPackage(packageName, "my-dependent", mc.Package.Namespace, "")
+		dependent.Status.CurrentVersion = "test"
+		now := metav1.Now()
+		dependent.DeletionTimestamp = &now
+		mockClient.EXPECT().List(mc.Ctx, gomock.AssignableToTypeOf(&api.PackageList{}), gomock.Eq(client.InNamespace(mc.Package.Namespace))).SetArg(1, api.PackageList{
+			Items: []api.Package{mc.Package, dependent},
+		})
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUninstalling, expectedEmptySource, retrySoon, "Waiting for dependents to be uninstalled: my-dependent")
+		thenEvents(t, mc)
+	})
+
+	t.Run("Uninstalling with cascade uninstalls once dependents are gone", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mockClient := givenMockClient(t)
+		mc.ManagerClient = bundle.NewManagerClient(mockClient)
+		mc.Package.Spec.PackageName = "test-dep"
+		mc.Package.Annotations = map[string]string{api.CascadeDeleteAnnotation: "true"}
+		mc.SetUninstalling()
+		mockClient.EXPECT().List(mc.Ctx, gomock.AssignableToTypeOf(&api.PackageList{}), gomock.Eq(client.InNamespace(mc.Package.Namespace))).SetArg(1, api.PackageList{
+			Items: []api.Package{mc.Package},
+		})
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Uninstall(mc.Ctx, packageInstance).Return(nil)
+		result := sut.Process(mc)
+		assert.False(t, result)
+		thenManagerContext(t, mc, api.StateUninstalling, expectedEmptySource, time.Duration(0), "")
+	})
+
+	t.Run("Uninstalling finalizer removal fails", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mockClient := givenMockClient(t)
+		mc.ManagerClient = bundle.NewManagerClient(mockClient)
+		mc.Package.Finalizers = []string{api.PackageFinalizer}
+		mc.SetUninstalling()
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Uninstall(mc.Ctx, packageInstance).Return(nil)
+		mockClient.EXPECT().Update(mc.Ctx, &mc.Package, gomock.Any()).Return(fmt.Errorf("oops"))
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUninstalling, expectedEmptySource, retryShort, "oops")
+	})
+
+	t.Run("Uninstalling initialize fails", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.SetUninstalling()
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(fmt.Errorf("init crunch"))
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUninstalling, expectedEmptySource, retryShort, "init crunch")
+	})
+
+	t.Run("Uninstalling fails", func(t *testing.T) {
+		mc, mockDriver := givenMocks(t)
+		mc.SetUninstalling()
+		mockDriver.EXPECT().Initialize(mc.Ctx, clusterName).Return(nil)
+		mockDriver.EXPECT().Uninstall(mc.Ctx, packageInstance).Return(fmt.Errorf("crunch"))
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUninstalling, expectedEmptySource, retryShort, "crunch")
+		thenCondition(t, mc, api.ReadyCondition, metav1.ConditionFalse, "Uninstalling")
+		thenEvents(t, mc, "Warning UninstallFailed Uninstall failed: crunch")
+	})
+
+	t.Run("Bogus state is reported", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = "bogus"
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, "bogus", expectedEmptySource, time.Duration(0), "Unknown state: bogus")
+	})
+
+	t.Run("Unknown state is ignored", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateUnknown
+		result := sut.Process(mc)
+		assert.False(t, result)
+		thenManagerContext(t, mc, api.StateUnknown, expectedEmptySource, time.Duration(0), "")
+	})
+
+	t.Run("Package in wrong namespace should be ignored", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = ""
+		mc.Package.Namespace = "default"
+		result := sut.Process(mc)
+		assert.True(t, result)
+		thenManagerContext(t, mc, api.StateUnknown, expectedEmptySource, time.Duration(0), "Packages namespaces must start with: eksa-packages")
+	})
+
+	t.Run("Package in wrong namespace should be ignored again", func(t *testing.T) {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.State = api.StateUnknown
+		mc.Package.Namespace = "default"
+		result := sut.Process(mc)
+		assert.False(t, result)
+		thenManagerContext(t, mc, api.StateUnknown, expectedEmptySource, time.Duration(0), "Packages namespaces must start with: eksa-packages")
+	})
+}
+
+func TestManagerContext_availableUpgrades(t *testing.T) {
+	givenBundleNamed := func(name string, state api.PackageBundleStateEnum, versions ...api.SourceVersion) api.PackageBundle {
+		b := givenBundle()
+		b.Name = name
+		b.Status.State = state
+		b.Spec.Packages[0].Source.Versions = versions
+		return *b
+	}
+	givenContext := func(t *testing.T, current string) *ManagerContext {
+		mc, _ := givenMocks(t)
+		mc.Package.Status.CurrentVersion = current
+		active := givenBundleNamed("v1-21-1002", api.PackageBundleStateAvailable,
+			api.SourceVersion{Name: "v1.1.0-abc", Digest: "sha256:110"},
+			api.SourceVersion{Name: "v1.0.0-def", Digest: "sha256:100"},
+		)
+		mc.Bundle = &active
+		mc.Bundles = []api.PackageBundle{
+			active,
+			givenBundleNamed("v1-21-1001", api.PackageBundleStateAvailable, api.SourceVersion{Name: "v0.9.0", Digest: "sha256:090"}),
+			givenBundleNamed("v1-21-1003", api.PackageBundleStateAvailable,
+				api.SourceVersion{Name: "v1.2.0-ghi", Digest: "sha256:120"},
+				api.SourceVersion{Name: "v1.1.0-abc", Digest: "sha256:110"},
+			),
+			givenBundleNamed("v1-21-1004", api.PackageBundleStateInvalid, api.SourceVersion{Name: "v2.0.0", Digest: "sha256:200"}),
+		}
+		return mc
+	}
+
+	t.Run("lists newer versions from the active and newer available bundles", func(t *testing.T) {
+		mc := givenContext(t, "v1.0.0-def")
+
+		assert.Equal(t, []api.PackageAvailableUpgrade{
+			{Version: "v1.2.0-ghi", Tag: "sha256:120"},
+			{Version: "v1.1.0-abc", Tag: "sha256:110"},
+		}, mc.availableUpgrades())
+	})
+
+	t.Run("ignores bundles for other kubernetes versions", func(t *testing.T) {
+		mc := givenContext(t, "v1.0.0-def")
+		mc.Bundles = append(mc.Bundles,
+			givenBundleNamed("v1-22-1001", api.PackageBundleStateAvailable, api.SourceVersion{Name: "v3.0.0", Digest: "sha256:300"}),
+			givenBundleNamed("v1-20-9999", api.PackageBundleStateAvailable, api.SourceVersion{Name: "v4.0.0", Digest: "sha256:400"}),
+		)
+
+		assert.Equal(t, []api.PackageAvailableUpgrade{
+			{Version: "v1.2.0-ghi", Tag: "sha256:120"},
+			{Version: "v1.1.0-abc", Tag: "sha256:110"},
+		}, mc.availableUpgrades())
+	})
+
+	t.Run("lists nothing when up to date", func(t *testing.T) {
+		mc := givenContext(t, "v1.2.0-ghi")
+
+		assert.Empty(t, mc.availableUpgrades())
+	})
+
+	t.Run("lists nothing for unparsable versions", func(t *testing.T) {
+		mc := givenContext(t, "latest")
+
+		assert.Empty(t, mc.availableUpgrades())
+	})
+
+	t.Run("ignores uninstalling packages", func(t *testing.T) {
+		mc := givenContext(t, "v1.0.0-def")
+		mc.Package.Status.State = api.StateUninstalling
+
+		assert.False(t, mc.updateUpgradesAvailable())
+		assert.Empty(t, mc.Package.Status.UpgradesAvailable)
+	})
+}
+
+func TestFindVersion(t *testing.T) {
+	bundle := givenBundle()
+	bundle.Spec.Packages[0].Source.Versions = []api.SourceVersion{
+		{Name: "v1.2.0-build2", Digest: "sha256:120b2"},
+		{Name: "v2.0.0-build1", Digest: "sha256:200"},
+		{Name: "v1.2.0-build1", Digest: "sha256:120b1"},
+		{Name: "v1.1.3-build1", Digest: "sha256:113"},
+		{Name: "v1.1.1-build1", Digest: "sha256:111"},
+	}
+	bundlePkg := bundle.Spec.Packages[0]
+
+	tests := []struct {
+		name      string
+		policy    api.UpgradePolicyEnum
+		requested string
+		current   string
+		expected  string
+		err       string
+	}{
+		{name: "no policy installs the requested version", requested: "v1.1.1-build1", current: "v1.1.3-build1", expected: "v1.1.1-build1"},
+		{name: "no policy installs the first version", expected: "v1.2.0-build2"},
+		{name: "manual installs the requested version", policy: api.UpgradePolicyManual, requested: "sha256:111", current: "v1.1.3-build1", expected: "v1.1.1-build1"},
+		{name: "manual keeps the installed version", policy: api.UpgradePolicyManual, current: "v1.1.1-build1", expected: "v1.1.1-build1"},
+		{name: "manual installs the highest version first", policy: api.UpgradePolicyManual, expected: "v2.0.0-build1"},
+		{name: "patch upgrades within the minor version", policy: api.UpgradePolicyPatch, current: "v1.1.1-build1", expected: "v1.1.3-build1"},
+		{name: "patch upgrades from the requested version", policy: api.UpgradePolicyPatch, requested: "sha256:111", current: "v1.2.0-build1", expected: "v1.1.3-build1"},
+		{name: "minor upgrades within the major version", policy: api.UpgradePolicyMinor, current: "v1.1.1-build1", expected: "v1.2.0-build2"},
+		{name: "minor doesn't downgrade", policy: api.UpgradePolicyMinor, current: "v1.3.0", err: "no version of packageName allowed by the minor upgrade policy from 1.3.0"},
+		{name: "latest upgrades to the highest version", policy: api.UpgradePolicyLatest, current: "v1.1.1-build1", expected: "v2.0.0-build1"},
+		{name: "latest installs the highest version first", policy: api.UpgradePolicyLatest, requested: api.Latest, expected: "v2.0.0-build1"},
+		{name: "patch requires a semantic version", policy: api.UpgradePolicyPatch, current: "stable", err: `patch upgrade policy requires a semantic version: invalid version "stable"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := givenPackage()
+			p.Spec.UpgradePolicy = tt.policy
+			p.Spec.PackageVersion = tt.requested
+			p.Status.CurrentVersion = tt.current
+
+			actual, err := FindVersion(bundle, bundlePkg, &p)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual.Name)
+		})
+	}
+}
+
+func TestUnexpectedImages(t *testing.T) {
+	expected := []api.VersionImages{
+		{Repository: "hello", Digest: "sha256:1111"},
+		{Repository: "team/world", Digest: "sha256:2222"},
+	}
+
+	assert.Empty(t, unexpectedImages([]string{
+		"registry.example.com/hello@sha256:1111",
+		"registry.example.com/team/world:v1@sha256:2222",
+	}, "registry.example.com", expected))
+	assert.Equal(t, []string{
+		"registry.example.com/hello:v1",
+		"registry.example.com/hello@sha256:2222",
+		"mirror.example.com/hello@sha256:1111",
+		"registry.example.com:5000/team/world@sha256:2222",
+	}, unexpectedImages([]string{
+		"registry.example.com/hello:v1",
+		"registry.example.com/hello@sha256:2222",
+		"mirror.example.com/hello@sha256:1111",
+		"registry.example.com:5000/team/world@sha256:2222",
+	}, "registry.example.com", expected))
 }
@@ -0,0 +1,160 @@
+package packages
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+	"github.com/aws/eks-anywhere-packages/pkg/dependency"
+)
+
+// FindVersion returns the version of a bundle package to install for a
+// package, following its upgrade policy. Without a policy the requested
+// version is installed, or the first version in the bundle if there is none.
+func FindVersion(bundle *api.PackageBundle, bundlePkg api.BundlePackage, p *api.Package) (api.SourceVersion, error) {
+	requested := p.Spec.PackageVersion
+	if requested == api.Latest {
+		requested = ""
+	}
+	policy := p.Spec.UpgradePolicy
+	if policy == "" {
+		if requested == "" {
+			requested = api.Latest
+		}
+		return bundle.FindVersion(bundlePkg, requested)
+	}
+
+	// Upgrades are relative to the requested version, or to the installed
+	// one when none is requested.
+	base := requested
+	if base == "" {
+		base = p.Status.CurrentVersion
+	}
+	if base == "" {
+		return highestVersion(bundlePkg, api.UpgradePolicyLatest, nil)
+	}
+	if policy == api.UpgradePolicyManual {
+		return bundle.FindVersion(bundlePkg, base)
+	}
+	if policy == api.UpgradePolicyLatest {
+		return highestVersion(bundlePkg, policy, nil)
+	}
+
+	// The base may be a digest, so look up its name in the bundle.
+	if v, err := bundle.FindVersion(bundlePkg, base); err == nil {
+		base = v.Name
+	}
+	floor, err := dependency.ParseVersion(base)
+	if err != nil {
+		return api.SourceVersion{}, fmt.Errorf("%s upgrade policy requires a semantic version: %w", policy, err)
+	}
+	return highestVersion(bundlePkg, policy, floor)
+}
+
+// highestVersion returns the highest version of a bundle package no lower
+// than floor that the upgrade policy allows moving to from it. Versions with
+// the same semantic version are taken in bundle order.
+func highestVersion(bundlePkg api.BundlePackage, policy api.UpgradePolicyEnum, floor *semver.Version) (api.SourceVersion, error) {
+	var ret api.SourceVersion
+	var highest *semver.Version
+	for _, v := range bundlePkg.Source.Versions {
+		version, err := dependency.ParseVersion(v.Name)
+		if err != nil {
+			continue
+		}
+		if floor != nil {
+			if version.LessThan(floor) || version.Major() != floor.Major() {
+				continue
+			}
+			if policy == api.UpgradePolicyPatch && version.Minor() != floor.Minor() {
+				continue
+			}
+		}
+		if highest == nil || version.GreaterThan(highest) {
+			ret, highest = v, version
+		}
+	}
+	if highest == nil {
+		if floor == nil {
+			return ret, fmt.Errorf("no semantic version of %s in the bundle", bundlePkg.Name)
+		}
+		return ret, fmt.Errorf("no version of %s allowed by the %s upgrade policy from %s", bundlePkg.Name, policy, floor)
+	}
+	return ret, nil
+}
+
+// upgradeBundles returns the active bundle followed by the available bundles
+// newer than it for the same Kubernetes version.
+func (mc *ManagerContext) upgradeBundles() []*api.PackageBundle {
+	bundles := []*api.PackageBundle{mc.Bundle}
+	for i := range mc.Bundles {
+		b := &mc.Bundles[i]
+		if b.Name == mc.Bundle.Name || b.Status.State != api.PackageBundleStateAvailable || !mc.Bundle.SameKubeVersion(b) {
+			continue
+		}
+		if mc.Bundle.LessThan(b) {
+			bundles = append(bundles, b)
+		}
+	}
+	return bundles
+}
+
+// availableUpgrades lists the versions of the package newer than the one
+// installed, newest first. Versions are compared by their semantic version, so
+// rebuilds of the installed version are not upgrades.
+func (mc *ManagerContext) availableUpgrades() []api.PackageAvailableUpgrade {
+	current, err := dependency.ParseVersion(mc.Package.Status.CurrentVersion)
+	if err != nil {
+		return nil
+	}
+
+	type upgrade struct {
+		api.PackageAvailableUpgrade
+		version *semver.Version
+	}
+	var upgrades []upgrade
+	seen := map[string]bool{}
+	for _, b := range mc.upgradeBundles() {
+		pkg, err := b.FindPackage(mc.Package.Spec.PackageName)
+		if err != nil {
+			continue
+		}
+		for _, v := range pkg.Source.Versions {
+			version, err := dependency.ParseVersion(v.Name)
+			if err != nil || !version.GreaterThan(current) || seen[v.Key()] {
+				continue
+			}
+			seen[v.Key()] = true
+			upgrades = append(upgrades, upgrade{
+				PackageAvailableUpgrade: api.PackageAvailableUpgrade{Version: v.Name, Tag: v.Digest},
+				version:                 version,
+			})
+		}
+	}
+
+	sort.SliceStable(upgrades, func(i, j int) bool {
+		return upgrades[i].version.GreaterThan(upgrades[j].version)
+	})
+	var ret []api.PackageAvailableUpgrade
+	for _, u := range upgrades {
+		ret = append(ret, u.PackageAvailableUpgrade)
+	}
+	return ret
+}
+
+// updateUpgradesAvailable refreshes the upgrades available for an installed
+// package, returning true if they changed.
+func (mc *ManagerContext) updateUpgradesAvailable() bool {
+	if mc.Bundle == nil || mc.Package.Status.State == api.StateUninstalling {
+		return false
+	}
+	upgrades := mc.availableUpgrades()
+	if reflect.DeepEqual(upgrades, mc.Package.Status.UpgradesAvailable) {
+		return false
+	}
+	mc.Package.Status.UpgradesAvailable = upgrades
+	return true
+}
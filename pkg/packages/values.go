@@ -0,0 +1,45 @@
+package packages
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// getValues returns the package configuration: the values from each of its
+// valuesFrom references in order, with its inline config merged over them.
+func (mc *ManagerContext) getValues() (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	for _, ref := range mc.Package.Spec.ValuesFrom {
+		config, err := mc.ManagerClient.GetValues(mc.Ctx, mc.Package.Namespace, ref)
+		if err != nil {
+			return nil, err
+		}
+		refValues := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(config), &refValues); err != nil {
+			return nil, fmt.Errorf("parsing %s %s key %s: %w", ref.Kind, ref.Name, ref.GetKey(), err)
+		}
+		mergeValues(values, refValues)
+	}
+
+	inline, err := mc.Package.GetValues()
+	if err != nil {
+		return nil, err
+	}
+	mergeValues(values, inline)
+	return values, nil
+}
+
+// mergeValues merges src into dst. Maps are merged recursively, while any
+// other value in src, lists included, replaces the one in dst.
+func mergeValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
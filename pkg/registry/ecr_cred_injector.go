@@ -23,6 +23,7 @@ import (
 
 	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	"github.com/aws/eks-anywhere-packages/pkg/authenticator"
+	"github.com/aws/eks-anywhere-packages/pkg/metrics"
 )
 
 const (
@@ -56,6 +57,7 @@ func (a *ECRCredInjector) Run(ctx context.Context) {
 	// been created yet during cluster bootstrap.
 	for {
 		err := a.Refresh(ctx)
+		metrics.ECRCredentialRefreshed(err)
 		if err == nil {
 			a.log.Info("ECR credential is injected to the docker config file")
 			break
@@ -70,6 +72,7 @@ func (a *ECRCredInjector) Run(ctx context.Context) {
 
 	for range time.Tick(time.Hour) {
 		err := a.Refresh(ctx)
+		metrics.ECRCredentialRefreshed(err)
 		if err != nil {
 			a.log.Error(err, "Failed to refresh ECR credential in dockerconfig file")
 		} else {
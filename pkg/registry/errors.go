@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+)
+
+// ErrorReason classifies an error talking to a registry. Errors which lost
+// their type on the way, as those of helm's registry client can, are
+// classified by their message. Only network errors and server errors of the
+// registry make it unreachable, anything else is unknown.
+func ErrorReason(err error) api.FailureReasonEnum {
+	if errors.Is(err, context.Canceled) {
+		return api.FailureReasonUnknown
+	}
+	var response *errcode.ErrorResponse
+	if errors.As(err, &response) {
+		switch {
+		case response.StatusCode == http.StatusUnauthorized, response.StatusCode == http.StatusForbidden:
+			return api.FailureReasonRegistryAuthFailed
+		case response.StatusCode == http.StatusNotFound:
+			return api.FailureReasonArtifactNotFound
+		case response.StatusCode >= http.StatusInternalServerError:
+			return api.FailureReasonRegistryUnreachable
+		}
+	}
+	if errors.Is(err, errdef.ErrNotFound) {
+		return api.FailureReasonArtifactNotFound
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	if errors.As(err, &netErr) || errors.As(err, &urlErr) {
+		return api.FailureReasonRegistryUnreachable
+	}
+	message := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(message, "unauthorized"),
+		strings.Contains(message, "denied"),
+		strings.Contains(message, "forbidden"):
+		return api.FailureReasonRegistryAuthFailed
+	case strings.Contains(message, "not found"):
+		return api.FailureReasonArtifactNotFound
+	case strings.Contains(message, "dial tcp"),
+		strings.Contains(message, "connection refused"),
+		strings.Contains(message, "no such host"),
+		strings.Contains(message, "i/o timeout"):
+		return api.FailureReasonRegistryUnreachable
+	}
+	return api.FailureReasonUnknown
+}
@@ -0,0 +1,47 @@
+package registry_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+	"github.com/aws/eks-anywhere-packages/pkg/registry"
+)
+
+func TestErrorReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected api.FailureReasonEnum
+	}{
+		{"unauthorized", &errcode.ErrorResponse{StatusCode: http.StatusUnauthorized}, api.FailureReasonRegistryAuthFailed},
+		{"forbidden", fmt.Errorf("pulling: %w", &errcode.ErrorResponse{StatusCode: http.StatusForbidden}), api.FailureReasonRegistryAuthFailed},
+		{"response not found", &errcode.ErrorResponse{StatusCode: http.StatusNotFound}, api.FailureReasonArtifactNotFound},
+		{"not found", fmt.Errorf("resolving: %w", errdef.ErrNotFound), api.FailureReasonArtifactNotFound},
+		{"denied message", fmt.Errorf("failed to authorize: access denied"), api.FailureReasonRegistryAuthFailed},
+		{"not found message", fmt.Errorf("public.ecr.aws/l0g8r8j6/hello:0.1.0: not found"), api.FailureReasonArtifactNotFound},
+		{"network", &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}, api.FailureReasonRegistryUnreachable},
+		{"transport", &url.Error{Op: "Get", URL: "https://public.ecr.aws/v2/", Err: fmt.Errorf("EOF")}, api.FailureReasonRegistryUnreachable},
+		{"server error", &errcode.ErrorResponse{StatusCode: http.StatusServiceUnavailable}, api.FailureReasonRegistryUnreachable},
+		{"network message", fmt.Errorf("dial tcp: i/o timeout"), api.FailureReasonRegistryUnreachable},
+		{"bad request", &errcode.ErrorResponse{StatusCode: http.StatusBadRequest}, api.FailureReasonUnknown},
+		{"chart decode", fmt.Errorf("loading chart: validation: chart.metadata.name is required"), api.FailureReasonUnknown},
+		{"bad digest", fmt.Errorf("fetch manifest: %w", digest.ErrDigestInvalidFormat), api.FailureReasonUnknown},
+		{"context canceled", fmt.Errorf("pulling: %w", context.Canceled), api.FailureReasonUnknown},
+		{"canceled request", &url.Error{Op: "Get", URL: "https://public.ecr.aws/v2/", Err: context.Canceled}, api.FailureReasonUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, registry.ErrorReason(tt.err))
+		})
+	}
+}
@@ -3,34 +3,40 @@ package registry
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	api "github.com/aws/eks-anywhere-packages/api/v1alpha1"
+	"github.com/aws/eks-anywhere-packages/pkg/failure"
+	"github.com/aws/eks-anywhere-packages/pkg/tracing"
 )
 
 // PullBytes a resource from the registry.
 func PullBytes(ctx context.Context, sc StorageClient, artifact Artifact) (data []byte, err error) {
+	ctx, span := tracing.Start(ctx, "Pull artifact", tracing.ArtifactKey.String(artifact.VersionedImage()))
+	defer func() { tracing.End(span, err) }()
+
 	srcStorage, err := sc.GetStorage(ctx, artifact)
 	if err != nil {
-		return nil, fmt.Errorf("repository source: %v", err)
+		return nil, failure.Errorf(ErrorReason(err), "repository source: %v", err)
 	}
 
 	_, data, err = sc.FetchBytes(ctx, srcStorage, artifact)
 	if err != nil {
-		return nil, fmt.Errorf("fetch manifest: %v", err)
+		return nil, failure.Errorf(ErrorReason(err), "fetch manifest: %v", err)
 	}
 
 	var mani ocispec.Manifest
 	if err := json.Unmarshal(data, &mani); err != nil {
-		return nil, fmt.Errorf("unmarshal manifest: %v", err)
+		return nil, failure.Errorf(api.FailureReasonInvalidArtifact, "unmarshal manifest: %v", err)
 	}
 	if len(mani.Layers) < 1 {
-		return nil, fmt.Errorf("missing layer")
+		return nil, failure.Errorf(api.FailureReasonInvalidArtifact, "missing layer")
 	}
 
 	data, err = sc.FetchBlob(ctx, srcStorage, mani.Layers[0])
 	if err != nil {
-		return nil, fmt.Errorf("fetch blob: %v", err)
+		return nil, failure.Errorf(ErrorReason(err), "fetch blob: %v", err)
 	}
 	return data, err
 }
@@ -8,9 +8,12 @@ import (
 	"github.com/golang/mock/gomock"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
 
 	"github.com/aws/eks-anywhere-packages/pkg/registry"
 	"github.com/aws/eks-anywhere-packages/pkg/registry/mocks"
+	"github.com/aws/eks-anywhere-packages/pkg/testutil"
+	"github.com/aws/eks-anywhere-packages/pkg/tracing"
 )
 
 //go:embed testdata/image-manifest.json
@@ -119,3 +122,19 @@ func TestPullGetStorageFail(t *testing.T) {
 	assert.Nil(t, result)
 	assert.EqualError(t, err, "repository source: oops")
 }
+
+func TestPullTraced(t *testing.T) {
+	exporter := testutil.GivenTracing(t)
+	srcClient := mocks.NewMockStorageClient(gomock.NewController(t))
+	srcClient.EXPECT().GetStorage(gomock.Any(), srcArtifact).Return(nil, fmt.Errorf("oops"))
+
+	_, err := registry.PullBytes(ctx, srcClient, srcArtifact)
+	assert.EqualError(t, err, "repository source: oops")
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "Pull artifact", spans[0].Name)
+		assert.Contains(t, spans[0].Attributes, tracing.ArtifactKey.String(srcArtifact.VersionedImage()))
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+	}
+}
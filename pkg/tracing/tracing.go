@@ -0,0 +1,91 @@
+// Package tracing traces the package controller's operations with
+// OpenTelemetry. Spans are dropped unless tracing is set up with Setup.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName  = "github.com/aws/eks-anywhere-packages"
+	serviceName = "eks-anywhere-packages"
+)
+
+// Attributes of spans.
+const (
+	PackageKey  = attribute.Key("eksa.package")
+	ClusterKey  = attribute.Key("eksa.cluster")
+	BundleKey   = attribute.Key("eksa.bundle")
+	ArtifactKey = attribute.Key("eksa.artifact")
+)
+
+// Config configures the export of spans.
+type Config struct {
+	// Endpoint is the host and port of the OTLP gRPC collector spans are
+	// exported to. Tracing is off without one.
+	Endpoint string
+	// Insecure exports spans without TLS.
+	Insecure bool
+	// SampleRatio is the fraction of traces sampled.
+	SampleRatio float64
+}
+
+// Setup exports spans to the configured OTLP collector, returning a function
+// flushing and stopping the export on shutdown.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(config.Endpoint)}
+	if config.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+	}
+	provider := NewTracerProvider(sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider creates a tracer provider for the controller's spans.
+// Tests pass an in-memory exporter with sdktrace.WithSyncer.
+func NewTracerProvider(options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	options = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	}, options...)
+	return sdktrace.NewTracerProvider(options...)
+}
+
+// Start starts a span of the controller, child of any span in the context.
+// The context is returned as is while tracing is off.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+	if !span.SpanContext().IsValid() {
+		return ctx, span
+	}
+	return spanCtx, span
+}
+
+// End ends a span, marking it failed if the operation returned an error.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
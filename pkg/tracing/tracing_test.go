@@ -0,0 +1,61 @@
+package tracing_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/aws/eks-anywhere-packages/pkg/testutil"
+	"github.com/aws/eks-anywhere-packages/pkg/tracing"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("leaves tracing off without an endpoint", func(t *testing.T) {
+		shutdown, err := tracing.Setup(context.Background(), tracing.Config{})
+		assert.NoError(t, err)
+
+		ctx := context.Background()
+		spanCtx, span := tracing.Start(ctx, "test")
+		tracing.End(span, nil)
+		assert.Equal(t, ctx, spanCtx)
+		assert.False(t, span.IsRecording())
+		assert.NoError(t, shutdown(ctx))
+	})
+}
+
+func TestStart(t *testing.T) {
+	t.Run("records spans with their attributes", func(t *testing.T) {
+		exporter := testutil.GivenTracing(t)
+
+		ctx, parent := tracing.Start(context.Background(), "parent", tracing.PackageKey.String("hello-eks-anywhere"))
+		_, child := tracing.Start(ctx, "child", tracing.ClusterKey.String("billy"))
+		tracing.End(child, nil)
+		tracing.End(parent, nil)
+
+		spans := exporter.GetSpans()
+		if assert.Len(t, spans, 2) {
+			assert.Equal(t, "child", spans[0].Name)
+			assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+			assert.Contains(t, spans[0].Attributes, tracing.ClusterKey.String("billy"))
+			assert.Equal(t, "parent", spans[1].Name)
+			assert.Contains(t, spans[1].Attributes, tracing.PackageKey.String("hello-eks-anywhere"))
+			assert.Equal(t, codes.Unset, spans[1].Status.Code)
+		}
+	})
+
+	t.Run("records errors", func(t *testing.T) {
+		exporter := testutil.GivenTracing(t)
+
+		_, span := tracing.Start(context.Background(), "failing")
+		tracing.End(span, fmt.Errorf("boom"))
+
+		spans := exporter.GetSpans()
+		if assert.Len(t, spans, 1) {
+			assert.Equal(t, codes.Error, spans[0].Status.Code)
+			assert.Equal(t, "boom", spans[0].Status.Description)
+		}
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/xeipuuv/gojsonschema"
 	admissionv1 "k8s.io/api/admission/v1"
@@ -32,6 +33,8 @@ import (
 
 	"github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	"github.com/aws/eks-anywhere-packages/pkg/bundle"
+	"github.com/aws/eks-anywhere-packages/pkg/dependency"
+	"github.com/aws/eks-anywhere-packages/pkg/maintenance"
 )
 
 type packageValidator struct {
@@ -52,6 +55,10 @@ func InitPackageValidator(mgr ctrl.Manager) error {
 }
 
 func (v *packageValidator) Handle(ctx context.Context, request admission.Request) admission.Response {
+	if request.Operation == admissionv1.Delete {
+		return v.handleDelete(ctx, request)
+	}
+
 	p := &v1alpha1.Package{}
 	err := v.decoder.Decode(request, p)
 	if err != nil {
@@ -92,6 +99,73 @@ func (v *packageValidator) Handle(ctx context.Context, request admission.Request
 	return *resp
 }
 
+func (v *packageValidator) handleDelete(ctx context.Context, request admission.Request) admission.Response {
+	p := &v1alpha1.Package{}
+	err := v.decoder.DecodeRaw(request.OldObject, p)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError,
+			fmt.Errorf("decoding request: %w", err))
+	}
+
+	err = v.isDeleteAllowed(ctx, p)
+	if err != nil {
+		reason := fmt.Sprintf("package %s cannot be deleted: %v", p.Name, err)
+		return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Code:    http.StatusBadRequest,
+				Message: reason,
+				Reason:  metav1.StatusReason(reason),
+			},
+		}}
+	}
+
+	return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{Allowed: true}}
+}
+
+// isDeleteAllowed returns an error if installed packages still depend on the
+// package, unless it is to be deleted along with them.
+func (v *packageValidator) isDeleteAllowed(ctx context.Context, p *v1alpha1.Package) error {
+	if p.IsCascadeDelete() {
+		return nil
+	}
+
+	clusterName := p.GetClusterName()
+	if clusterName == "" {
+		clusterName = os.Getenv("CLUSTER_NAME")
+	}
+
+	activeBundle, err := v.BundleClient.GetActiveBundle(ctx, clusterName)
+	if err != nil {
+		// Without a bundle there is nothing to say what depends on what, and
+		// refusing would leave the package impossible to delete.
+		return nil
+	}
+	pkgs, err := v.BundleClient.GetPackageList(ctx, p.Namespace)
+	if err != nil {
+		return fmt.Errorf("listing packages: %w", err)
+	}
+
+	var names []string
+	// Dependents may run versions since dropped from the active bundle, whose
+	// dependencies are in the bundles they were installed from.
+	installedBundle := func(name string) (*v1alpha1.PackageBundle, error) {
+		return v.BundleClient.GetBundle(ctx, name)
+	}
+	resolver := dependency.NewResolver(activeBundle).WithInstalledBundles(installedBundle)
+	for _, dependent := range resolver.Dependents(p, pkgs.Items) {
+		if dependent.Package.DeletionTimestamp.IsZero() {
+			names = append(names, dependent.Package.Name)
+		}
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("required by %s; delete them first, or set the %s annotation to \"true\" to delete them along with it",
+			strings.Join(names, ", "), v1alpha1.CascadeDeleteAnnotation)
+	}
+	return nil
+}
+
 func (v *packageValidator) isPackageValid(p *v1alpha1.Package, activeBundle *v1alpha1.PackageBundle) (bool, error) {
 	packageInBundle, err := activeBundle.FindPackage(p.Spec.PackageName)
 	if err != nil {
@@ -125,6 +199,10 @@ func (v *packageValidator) isPackageValid(p *v1alpha1.Package, activeBundle *v1a
 		return false, fmt.Errorf("package %s targetNamespace is immutable", p.Name)
 	}
 
+	if err := maintenance.Validate(p.Spec.MaintenanceWindows); err != nil {
+		return false, err
+	}
+
 	b := new(bytes.Buffer)
 	if !result.Valid() {
 		for _, e := range result.Errors() {
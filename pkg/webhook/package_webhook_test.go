@@ -1,11 +1,21 @@
 package webhook
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	"github.com/aws/eks-anywhere-packages/api/v1alpha1"
+	bundleMocks "github.com/aws/eks-anywhere-packages/pkg/bundle/mocks"
 	"github.com/aws/eks-anywhere-packages/pkg/testutil"
 )
 
@@ -93,4 +103,144 @@ func TestPackageValidate(t *testing.T) {
 		assert.False(t, result)
 		assert.EqualError(t, err, "package my-hello-eks-anywhere targetNamespace is immutable")
 	})
+
+	t.Run("invalid maintenance windows", func(t *testing.T) {
+		activeBundle, err := testutil.GivenPackageBundle("../../api/testdata/bundle_one.yaml")
+		require.Nil(t, err)
+		myPackage, err := testutil.GivenPackage("../../api/testdata/package_webhook_valid_config.yaml")
+		require.Nil(t, err)
+		myPackage.Spec.MaintenanceWindows = []v1alpha1.MaintenanceWindow{{Schedule: "0 2 * * 6"}}
+		validator := packageValidator{}
+
+		result, err := validator.isPackageValid(myPackage, activeBundle)
+
+		assert.False(t, result)
+		assert.EqualError(t, err, "invalid maintenance window duration 0s: must be positive")
+	})
+}
+
+func givenDependencyBundle() *v1alpha1.PackageBundle {
+	return &v1alpha1.PackageBundle{
+		ObjectMeta: metav1.ObjectMeta{Name: "v1-21-1001"},
+		Spec: v1alpha1.PackageBundleSpec{
+			Packages: []v1alpha1.BundlePackage{
+				{Name: "harbor", Source: v1alpha1.BundlePackageSource{Versions: []v1alpha1.SourceVersion{{Name: "2.5.0", Dependencies: []string{"cert-manager"}}}}},
+				{Name: "cert-manager", Source: v1alpha1.BundlePackageSource{Versions: []v1alpha1.SourceVersion{{Name: "1.13.0"}}}},
+			},
+		},
+	}
+}
+
+func givenInstalledPackage(packageName, name, version string) v1alpha1.Package {
+	pkg := v1alpha1.NewPackage(packageName, name, "eksa-packages-billy", "")
+	pkg.Status.CurrentVersion = version
+	return pkg
+}
+
+func TestPackageValidateDelete(t *testing.T) {
+	ctx := context.Background()
+	certManager := givenInstalledPackage("cert-manager", "my-cert-manager", "1.13.0")
+	harbor := givenInstalledPackage("harbor", "my-harbor", "2.5.0")
+
+	t.Run("rejects deleting packages installed packages depend on", func(t *testing.T) {
+		bundleClient := bundleMocks.NewMockClient(gomock.NewController(t))
+		bundleClient.EXPECT().GetActiveBundle(ctx, "billy").Return(givenDependencyBundle(), nil)
+		bundleClient.EXPECT().GetPackageList(ctx, "eksa-packages-billy").Return(v1alpha1.PackageList{Items: []v1alpha1.Package{certManager, harbor}}, nil)
+		validator := packageValidator{BundleClient: bundleClient}
+
+		err := validator.isDeleteAllowed(ctx, &certManager)
+
+		assert.EqualError(t, err, `required by my-harbor; delete them first, or set the anywhere.eks.aws.com/cascade-delete annotation to "true" to delete them along with it`)
+	})
+
+	t.Run("rejects deleting packages depended on by versions dropped from the active bundle", func(t *testing.T) {
+		olderBundle := &v1alpha1.PackageBundle{
+			ObjectMeta: metav1.ObjectMeta{Name: "v1-21-1000"},
+			Spec: v1alpha1.PackageBundleSpec{
+				Packages: []v1alpha1.BundlePackage{
+					{Name: "harbor", Source: v1alpha1.BundlePackageSource{Versions: []v1alpha1.SourceVersion{{Name: "2.4.0", Dependencies: []string{"cert-manager"}}}}},
+					{Name: "cert-manager", Source: v1alpha1.BundlePackageSource{Versions: []v1alpha1.SourceVersion{{Name: "1.12.0"}}}},
+				},
+			},
+		}
+		olderHarbor := givenInstalledPackage("harbor", "my-harbor", "2.4.0")
+		olderHarbor.Status.History = []v1alpha1.PackageRevision{
+			{Revision: 1, Version: "2.4.0", Bundle: "v1-21-1000", Result: v1alpha1.RevisionResultSucceeded},
+		}
+		bundleClient := bundleMocks.NewMockClient(gomock.NewController(t))
+		bundleClient.EXPECT().GetActiveBundle(ctx, "billy").Return(givenDependencyBundle(), nil)
+		bundleClient.EXPECT().GetPackageList(ctx, "eksa-packages-billy").Return(v1alpha1.PackageList{Items: []v1alpha1.Package{certManager, olderHarbor}}, nil)
+		bundleClient.EXPECT().GetBundle(ctx, "v1-21-1000").Return(olderBundle, nil)
+		validator := packageValidator{BundleClient: bundleClient}
+
+		err := validator.isDeleteAllowed(ctx, &certManager)
+
+		assert.EqualError(t, err, `required by my-harbor; delete them first, or set the anywhere.eks.aws.com/cascade-delete annotation to "true" to delete them along with it`)
+	})
+
+	t.Run("allows deleting packages whose dependents are being deleted", func(t *testing.T) {
+		deleting := harbor.DeepCopy()
+		now := metav1.Now()
+		deleting.DeletionTimestamp = &now
+		bundleClient := bundleMocks.NewMockClient(gomock.NewController(t))
+		bundleClient.EXPECT().GetActiveBundle(ctx, "billy").Return(givenDependencyBundle(), nil)
+		bundleClient.EXPECT().GetPackageList(ctx, "eksa-packages-billy").Return(v1alpha1.PackageList{Items: []v1alpha1.Package{certManager, *deleting}}, nil)
+		validator := packageValidator{BundleClient: bundleClient}
+
+		assert.NoError(t, validator.isDeleteAllowed(ctx, &certManager))
+	})
+
+	t.Run("allows deleting packages nothing depends on", func(t *testing.T) {
+		bundleClient := bundleMocks.NewMockClient(gomock.NewController(t))
+		bundleClient.EXPECT().GetActiveBundle(ctx, "billy").Return(givenDependencyBundle(), nil)
+		bundleClient.EXPECT().GetPackageList(ctx, "eksa-packages-billy").Return(v1alpha1.PackageList{Items: []v1alpha1.Package{certManager, harbor}}, nil)
+		validator := packageValidator{BundleClient: bundleClient}
+
+		assert.NoError(t, validator.isDeleteAllowed(ctx, &harbor))
+	})
+
+	t.Run("allows cascading deletes", func(t *testing.T) {
+		cascading := certManager.DeepCopy()
+		cascading.Annotations = map[string]string{v1alpha1.CascadeDeleteAnnotation: "true"}
+		validator := packageValidator{}
+
+		assert.NoError(t, validator.isDeleteAllowed(ctx, cascading))
+	})
+
+	t.Run("allows deletes without an active bundle", func(t *testing.T) {
+		bundleClient := bundleMocks.NewMockClient(gomock.NewController(t))
+		bundleClient.EXPECT().GetActiveBundle(ctx, "billy").Return(nil, errors.New("oops"))
+		validator := packageValidator{BundleClient: bundleClient}
+
+		assert.NoError(t, validator.isDeleteAllowed(ctx, &certManager))
+	})
+
+	t.Run("fails if packages can't be listed", func(t *testing.T) {
+		bundleClient := bundleMocks.NewMockClient(gomock.NewController(t))
+		bundleClient.EXPECT().GetActiveBundle(ctx, "billy").Return(givenDependencyBundle(), nil)
+		bundleClient.EXPECT().GetPackageList(ctx, "eksa-packages-billy").Return(v1alpha1.PackageList{}, errors.New("oops"))
+		validator := packageValidator{BundleClient: bundleClient}
+
+		assert.EqualError(t, validator.isDeleteAllowed(ctx, &certManager), "listing packages: oops")
+	})
+
+	t.Run("handles delete requests", func(t *testing.T) {
+		scheme := runtime.NewScheme()
+		require.NoError(t, v1alpha1.AddToScheme(scheme))
+		bundleClient := bundleMocks.NewMockClient(gomock.NewController(t))
+		bundleClient.EXPECT().GetActiveBundle(ctx, "billy").Return(givenDependencyBundle(), nil)
+		bundleClient.EXPECT().GetPackageList(ctx, "eksa-packages-billy").Return(v1alpha1.PackageList{Items: []v1alpha1.Package{certManager, harbor}}, nil)
+		validator := packageValidator{BundleClient: bundleClient, decoder: admission.NewDecoder(scheme)}
+		raw, err := json.Marshal(certManager)
+		require.NoError(t, err)
+		request := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Delete,
+			OldObject: runtime.RawExtension{Raw: raw},
+		}}
+
+		response := validator.Handle(ctx, request)
+
+		assert.False(t, response.Allowed)
+		assert.Contains(t, response.Result.Message, "package my-cert-manager cannot be deleted: required by my-harbor")
+	})
 }
@@ -31,6 +31,8 @@ import (
 
 	"github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	"github.com/aws/eks-anywhere-packages/pkg/bundle"
+	"github.com/aws/eks-anywhere-packages/pkg/dependency"
+	"github.com/aws/eks-anywhere-packages/pkg/metrics"
 	"github.com/aws/eks-anywhere-packages/pkg/signature"
 )
 
@@ -96,6 +98,10 @@ func (v *packageBundleValidator) isPackageBundleValid(pb *v1alpha1.PackageBundle
 		return fmt.Errorf("Invalid bundle name (should be in the format vx-xx-xxxx where x is a digit): %s", pb.Name)
 	}
 
+	if err := dependency.NewResolver(pb).Validate(); err != nil {
+		return fmt.Errorf("invalid dependencies: %w", err)
+	}
+
 	keyOverride := os.Getenv(PublicKeyEnvVar)
 	domain := signature.EksaDomain
 	if keyOverride != "" {
@@ -103,9 +109,11 @@ func (v *packageBundleValidator) isPackageBundleValid(pb *v1alpha1.PackageBundle
 	}
 	valid, digest, yml, err := signature.ValidateSignature(pb, domain)
 	if err != nil {
+		metrics.SignatureVerificationFailed()
 		return err
 	}
 	if !valid {
+		metrics.SignatureVerificationFailed()
 		v.log.Info("Invalid signature", "Error", err, "Digest", base64.StdEncoding.EncodeToString(digest[:]), "Manifest", string(yml))
 		return fmt.Errorf("The signature is invalid for the configured public key: %s", domain.Pubkey)
 	}
@@ -39,6 +39,22 @@ func TestBundleValidate(t *testing.T) {
 		assert.EqualError(t, err, "Invalid bundle name (should be in the format vx-xx-xxxx where x is a digit): kevin-morby")
 	})
 
+	t.Run("dependency cycle", func(t *testing.T) {
+		myBundle := v1alpha1.PackageBundle{
+			ObjectMeta: metav1.ObjectMeta{Name: "v1-21-003"},
+			Spec: v1alpha1.PackageBundleSpec{
+				Packages: []v1alpha1.BundlePackage{
+					{Name: "a", Source: v1alpha1.BundlePackageSource{Versions: []v1alpha1.SourceVersion{{Name: "v1.0.0", Dependencies: []string{"b"}}}}},
+					{Name: "b", Source: v1alpha1.BundlePackageSource{Versions: []v1alpha1.SourceVersion{{Name: "v1.0.0", Dependencies: []string{"a>=1"}}}}},
+				},
+			},
+		}
+
+		err := sut.isPackageBundleValid(&myBundle)
+
+		assert.EqualError(t, err, "invalid dependencies: a@v1.0.0: dependency cycle: a -> b -> a")
+	})
+
 	t.Run("invalid key", func(t *testing.T) {
 		t.Setenv(PublicKeyEnvVar, "asdf")
 		myBundle, err := testutil.GivenPackageBundle("../../api/testdata/bundle_one.yaml")
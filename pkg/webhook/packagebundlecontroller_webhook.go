@@ -29,6 +29,7 @@ import (
 
 	"github.com/aws/eks-anywhere-packages/api/v1alpha1"
 	"github.com/aws/eks-anywhere-packages/pkg/authenticator"
+	"github.com/aws/eks-anywhere-packages/pkg/maintenance"
 )
 
 type activeBundleValidator struct {
@@ -77,6 +78,22 @@ func (v *activeBundleValidator) Handle(ctx context.Context, req admission.Reques
 func (v *activeBundleValidator) handleInner(ctx context.Context, pbc *v1alpha1.PackageBundleController, bundles *v1alpha1.PackageBundleList) (
 	*admission.Response, error,
 ) {
+	if err := maintenance.Validate(pbc.Spec.MaintenanceWindows); err != nil {
+		reason := err.Error()
+		resp := &admission.Response{
+			AdmissionResponse: admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Status:  metav1.StatusFailure,
+					Code:    http.StatusBadRequest,
+					Message: reason,
+					Reason:  metav1.StatusReason(reason),
+				},
+			},
+		}
+		return resp, nil
+	}
+
 	if pbc.Spec.ActiveBundle == "" {
 		resp := &admission.Response{
 			AdmissionResponse: admissionv1.AdmissionResponse{
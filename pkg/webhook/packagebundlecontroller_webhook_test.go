@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -173,6 +174,20 @@ func TestHandleInner(t *testing.T) {
 			assert.Equal(t, "activeBundle \"v1-21-1002\" not present on cluster", string(resp.AdmissionResponse.Result.Reason))
 		}
 	})
+
+	t.Run("rejects invalid maintenance windows", func(t *testing.T) {
+		v := &activeBundleValidator{}
+		pbc := &v1alpha1.PackageBundleController{
+			Spec: v1alpha1.PackageBundleControllerSpec{
+				MaintenanceWindows: []v1alpha1.MaintenanceWindow{{Schedule: "0 2 * *", Duration: metav1.Duration{Duration: time.Hour}}},
+			},
+		}
+		resp, err := v.handleInner(ctx, pbc, &v1alpha1.PackageBundleList{})
+		if assert.NoError(t, err) {
+			assert.False(t, resp.AdmissionResponse.Allowed)
+			assert.Equal(t, "invalid maintenance window schedule \"0 2 * *\": expected exactly 5 fields, found 4: [0 2 * *]", string(resp.AdmissionResponse.Result.Reason))
+		}
+	})
 }
 
 //